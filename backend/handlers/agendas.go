@@ -0,0 +1,114 @@
+// Copyright (c) 2015-2025 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"decred-pulse-backend/rpc"
+	"decred-pulse-backend/services"
+	"decred-pulse-backend/types"
+)
+
+// GetAgendasHandler returns the consensus agendas of a vote version with
+// their tallies in the current rule change interval
+func GetAgendasHandler(w http.ResponseWriter, r *http.Request) {
+	if rpc.DcrdClient == nil {
+		http.Error(w, "RPC client not initialized", http.StatusServiceUnavailable)
+		return
+	}
+
+	var version uint32
+	if v := r.URL.Query().Get("version"); v != "" {
+		parsed, err := strconv.ParseUint(v, 10, 32)
+		if err != nil {
+			http.Error(w, "Invalid version", http.StatusBadRequest)
+			return
+		}
+		version = uint32(parsed)
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+	defer cancel()
+
+	agendas, err := services.FetchAgendas(ctx, version)
+	if errors.Is(err, services.ErrUnknownVoteVersion) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		log.Printf("Error fetching agendas: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(agendas)
+}
+
+// GetVoteChoicesHandler returns the wallet's default vote choices, or one
+// ticket's choices with ?ticket=
+func GetVoteChoicesHandler(w http.ResponseWriter, r *http.Request) {
+	if rpc.WalletClient == nil {
+		http.Error(w, "Wallet RPC client not initialized", http.StatusServiceUnavailable)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	choices, err := services.FetchVoteChoices(ctx, r.URL.Query().Get("ticket"))
+	if err != nil {
+		writeVoteChoiceError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(choices)
+}
+
+// SetVoteChoiceHandler sets the wallet's choice for an agenda, for all
+// tickets or one ticket
+func SetVoteChoiceHandler(w http.ResponseWriter, r *http.Request) {
+	if rpc.WalletClient == nil {
+		http.Error(w, "Wallet RPC client not initialized", http.StatusServiceUnavailable)
+		return
+	}
+
+	var req types.SetVoteChoiceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	// Tickets registered with a VSP are updated there too
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	choices, err := services.SetVoteChoice(ctx, req)
+	if err != nil {
+		writeVoteChoiceError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(choices)
+}
+
+// writeVoteChoiceError maps vote choice errors to status codes
+func writeVoteChoiceError(w http.ResponseWriter, err error) {
+	if errors.Is(err, services.ErrInvalidVoteChoice) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	log.Printf("Vote choice operation failed: %v", err)
+	http.Error(w, err.Error(), http.StatusInternalServerError)
+}
@@ -0,0 +1,127 @@
+// Copyright (c) 2015-2025 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package handlers
+
+import (
+	"crypto/subtle"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// Role is an API access level. Higher roles include the lower ones.
+type Role int
+
+const (
+	// RoleOperator may perform routine wallet management (lock/unlock)
+	RoleOperator Role = iota + 1
+
+	// RoleAdmin may perform every privileged operation
+	RoleAdmin
+)
+
+func (r Role) String() string {
+	switch r {
+	case RoleOperator:
+		return "operator"
+	case RoleAdmin:
+		return "admin"
+	}
+	return "unknown"
+}
+
+// AuthConfig holds the bearer tokens granting each role
+type AuthConfig struct {
+	AdminToken    string
+	OperatorToken string
+}
+
+var (
+	authMutex  sync.RWMutex
+	authConfig AuthConfig
+)
+
+// InitAuth sets the API tokens used by RequireRole.
+// Privileged endpoints are refused entirely while no token is configured.
+func InitAuth(config AuthConfig) {
+	authMutex.Lock()
+	authConfig = config
+	authMutex.Unlock()
+
+	if config.AdminToken == "" && config.OperatorToken == "" {
+		log.Println("No API tokens configured. Privileged wallet endpoints are disabled.")
+	} else {
+		log.Println("API token authentication enabled for privileged endpoints")
+	}
+}
+
+// RequireRole wraps a handler so it only runs for requests carrying a bearer
+// token whose role is at least minRole
+func RequireRole(minRole Role, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		authMutex.RLock()
+		config := authConfig
+		authMutex.RUnlock()
+
+		if !roleConfigured(config, minRole) {
+			http.Error(w, "Endpoint disabled: no API token configured for role "+minRole.String(), http.StatusForbidden)
+			return
+		}
+
+		token, ok := bearerToken(r)
+		if !ok {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="decred-pulse"`)
+			http.Error(w, "Authentication required", http.StatusUnauthorized)
+			return
+		}
+
+		role := tokenRole(config, token)
+		if role < minRole {
+			log.Printf("Warning: Rejected %s %s from %s: insufficient role", r.Method, r.URL.Path, r.RemoteAddr)
+			http.Error(w, "Insufficient permissions", http.StatusForbidden)
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+// roleConfigured reports whether any token grants at least minRole
+func roleConfigured(config AuthConfig, minRole Role) bool {
+	if config.AdminToken != "" {
+		return true
+	}
+	return minRole <= RoleOperator && config.OperatorToken != ""
+}
+
+// tokenRole returns the role granted by token, or 0 if it matches none
+func tokenRole(config AuthConfig, token string) Role {
+	if tokenMatches(config.AdminToken, token) {
+		return RoleAdmin
+	}
+	if tokenMatches(config.OperatorToken, token) {
+		return RoleOperator
+	}
+	return 0
+}
+
+// tokenMatches compares tokens in constant time; an unset token never matches
+func tokenMatches(expected, token string) bool {
+	if expected == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(token)) == 1
+}
+
+// bearerToken extracts the token from an "Authorization: Bearer" header
+func bearerToken(r *http.Request) (string, bool) {
+	header := r.Header.Get("Authorization")
+	const prefix = "Bearer "
+	if len(header) <= len(prefix) || !strings.EqualFold(header[:len(prefix)], prefix) {
+		return "", false
+	}
+	return strings.TrimSpace(header[len(prefix):]), true
+}
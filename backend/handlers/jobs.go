@@ -0,0 +1,136 @@
+// Copyright (c) 2015-2025 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+
+	"decred-pulse-backend/jobs"
+	"decred-pulse-backend/types"
+)
+
+// ListJobsHandler returns tracked background jobs, newest first
+func ListJobsHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	list := jobs.List(query.Get("type"), query.Get("status"))
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(types.JobListResponse{
+		Jobs:  list,
+		Total: len(list),
+	})
+}
+
+// GetJobHandler returns a single job including its steps and logs
+func GetJobHandler(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	job, err := jobs.Get(id)
+	if err != nil {
+		http.Error(w, "Job not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(job)
+}
+
+// CancelJobHandler requests cancellation of a running job
+func CancelJobHandler(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+
+	err := jobs.Cancel(id)
+	switch {
+	case errors.Is(err, jobs.ErrJobNotFound):
+		http.Error(w, "Job not found", http.StatusNotFound)
+		return
+	case errors.Is(err, jobs.ErrJobFinished):
+		http.Error(w, "Job already finished", http.StatusConflict)
+		return
+	case err != nil:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"message": "Cancellation requested",
+	})
+}
+
+// jobStreamReadTimeout closes job streams whose client missed three pings
+const jobStreamReadTimeout = 15 * time.Second
+
+// StreamJobsHandler streams job progress events via WebSocket.
+// On connect the client receives the currently queued and running jobs, then
+// every change.
+func StreamJobsHandler(w http.ResponseWriter, r *http.Request) {
+	upgrader := websocket.Upgrader{
+		CheckOrigin: func(r *http.Request) bool {
+			return true // Allow all origins (configure appropriately for production)
+		},
+	}
+
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Printf("Failed to upgrade to WebSocket: %v", err)
+		return
+	}
+	defer conn.Close()
+
+	// Subscribe before sending the snapshot so no event is missed in between
+	events := jobs.Subscribe()
+	defer jobs.Unsubscribe(events)
+
+	active := append(jobs.List("", types.JobStatusQueued), jobs.List("", types.JobStatusRunning)...)
+	for _, job := range active {
+		if err := conn.WriteJSON(types.JobEvent{Event: "updated", Job: job}); err != nil {
+			return
+		}
+	}
+
+	// Read until the client goes away. Reading processes close frames and
+	// pongs; a peer that stops answering pings hits the read deadline.
+	closed := make(chan struct{})
+	conn.SetReadDeadline(time.Now().Add(jobStreamReadTimeout))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(jobStreamReadTimeout))
+	})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	keepAliveTicker := time.NewTicker(5 * time.Second)
+	defer keepAliveTicker.Stop()
+
+	for {
+		select {
+		case <-closed:
+			return
+		case event := <-events:
+			if err := conn.WriteJSON(event); err != nil {
+				log.Printf("Jobs WebSocket write failed: %v", err)
+				return
+			}
+		case <-keepAliveTicker.C:
+			if err := conn.WriteMessage(websocket.PingMessage, []byte{}); err != nil {
+				return
+			}
+		}
+	}
+}
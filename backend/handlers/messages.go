@@ -0,0 +1,89 @@
+// Copyright (c) 2015-2025 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"decred-pulse-backend/rpc"
+	"decred-pulse-backend/services"
+	"decred-pulse-backend/types"
+)
+
+// SignMessageHandler signs a message with a wallet address to prove control of it
+func SignMessageHandler(w http.ResponseWriter, r *http.Request) {
+	if rpc.WalletGrpcClient == nil {
+		http.Error(w, "Wallet gRPC client not initialized", http.StatusServiceUnavailable)
+		return
+	}
+
+	var req types.SignMessageRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	response, err := services.SignMessage(ctx, req)
+	if err != nil {
+		writeMessageError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// VerifyMessageHandler checks a signed message against an address
+func VerifyMessageHandler(w http.ResponseWriter, r *http.Request) {
+	if rpc.DcrdClient == nil {
+		http.Error(w, "RPC client not initialized", http.StatusServiceUnavailable)
+		return
+	}
+
+	var req types.VerifyMessageRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	response, err := services.VerifyMessage(ctx, req)
+	if err != nil {
+		writeMessageError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// writeMessageError maps message signing errors to status codes
+func writeMessageError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidMessage),
+		errors.Is(err, services.ErrInvalidSignature),
+		errors.Is(err, services.ErrInvalidSigningAddress):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, services.ErrIncorrectPassphrase):
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+	case errors.Is(err, services.ErrAddressNotOwned):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, services.ErrWalletLocked):
+		http.Error(w, err.Error(), http.StatusLocked)
+	default:
+		log.Printf("Message signing failed: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
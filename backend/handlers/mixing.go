@@ -0,0 +1,61 @@
+// Copyright (c) 2015-2025 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"decred-pulse-backend/rpc"
+	"decred-pulse-backend/services"
+)
+
+// GetMixStatsHandler returns the chain StakeShuffle statistics
+func GetMixStatsHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+	defer cancel()
+
+	stats := services.FetchMixStats(ctx)
+	if stats == nil {
+		http.Error(w, "Mix statistics not available, start a scan with POST /api/mixing/scan", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(stats)
+}
+
+// TriggerMixStatsScanHandler starts the chain scan for mix statistics.
+// Without rescan it continues from the last scanned block.
+func TriggerMixStatsScanHandler(w http.ResponseWriter, r *http.Request) {
+	if rpc.DcrdClient == nil {
+		http.Error(w, "RPC client not initialized", http.StatusServiceUnavailable)
+		return
+	}
+
+	var req struct {
+		StartHeight int64 `json:"startHeight"`
+		Rescan      bool  `json:"rescan"`
+	}
+	// An empty body uses the defaults
+	json.NewDecoder(r.Body).Decode(&req)
+
+	jobID, err := services.TriggerMixStatsScan(req.StartHeight, req.Rescan)
+	if err != nil {
+		log.Printf("Error triggering mix statistics scan: %v", err)
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"message": "Mix statistics scan started",
+		"jobId":   jobID,
+	})
+}
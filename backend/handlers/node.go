@@ -122,6 +122,7 @@ func HealthCheckHandler(w http.ResponseWriter, r *http.Request) {
 		"status":             "healthy",
 		"rpcConnected":       rpc.DcrdClient != nil,
 		"walletRPCConnected": rpc.WalletClient != nil,
+		"walletSyncMode":     services.LastKnownWalletSyncMode(),
 		"time":               time.Now(),
 	}
 	w.Header().Set("Content-Type", "application/json")
@@ -0,0 +1,24 @@
+// Copyright (c) 2015-2025 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"decred-pulse-backend/pricing"
+)
+
+// GetExchangeRateHandler returns the cached DCR exchange rate with its age
+func GetExchangeRateHandler(w http.ResponseWriter, r *http.Request) {
+	rate := pricing.CurrentRate()
+	if rate == nil {
+		http.Error(w, "No exchange rate available", http.StatusServiceUnavailable)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rate)
+}
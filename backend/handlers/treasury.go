@@ -7,12 +7,17 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"time"
 
+	"github.com/gorilla/mux"
+
+	"decred-pulse-backend/rpc"
 	"decred-pulse-backend/services"
+	"decred-pulse-backend/types"
 )
 
 // GetTreasuryInfoHandler returns current treasury status
@@ -48,7 +53,7 @@ func TriggerTSpendScanHandler(w http.ResponseWriter, r *http.Request) {
 		req.StartHeight = 552448
 	}
 
-	err := services.TriggerHistoricalScan(req.StartHeight)
+	jobID, err := services.TriggerHistoricalScan(req.StartHeight)
 	if err != nil {
 		log.Printf("Error triggering TSpend scan: %v", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -59,6 +64,7 @@ func TriggerTSpendScanHandler(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(map[string]interface{}{
 		"success": true,
 		"message": fmt.Sprintf("Historical TSpend scan started from block %d", req.StartHeight),
+		"jobId":   jobID,
 	})
 }
 
@@ -82,3 +88,127 @@ func GetTSpendScanResultsHandler(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(results)
 }
+
+// ListTreasuryPoliciesHandler returns the wallet's vote for each Pi key
+func ListTreasuryPoliciesHandler(w http.ResponseWriter, r *http.Request) {
+	if rpc.WalletClient == nil {
+		http.Error(w, "Wallet RPC client not initialized", http.StatusServiceUnavailable)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	policies, err := services.ListTreasuryPolicies(ctx)
+	if err != nil {
+		writeTreasuryPolicyError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(policies)
+}
+
+// SetTreasuryPolicyHandler sets the wallet's vote for a Pi key
+func SetTreasuryPolicyHandler(w http.ResponseWriter, r *http.Request) {
+	if rpc.WalletClient == nil {
+		http.Error(w, "Wallet RPC client not initialized", http.StatusServiceUnavailable)
+		return
+	}
+
+	var req types.TreasuryPolicyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	// Tickets registered with a VSP are updated there too
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	policy, err := services.SetTreasuryPolicy(ctx, req)
+	if err != nil {
+		writeTreasuryPolicyError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(policy)
+}
+
+// ListTSpendPoliciesHandler returns the wallet's vote for each treasury spend
+// it knows about, optionally for one ticket with ?ticket=
+func ListTSpendPoliciesHandler(w http.ResponseWriter, r *http.Request) {
+	if rpc.WalletClient == nil {
+		http.Error(w, "Wallet RPC client not initialized", http.StatusServiceUnavailable)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	policies, err := services.ListTSpendPolicies(ctx, r.URL.Query().Get("ticket"))
+	if err != nil {
+		writeTreasuryPolicyError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(policies)
+}
+
+// GetTSpendPolicyHandler returns the wallet's vote on one treasury spend
+func GetTSpendPolicyHandler(w http.ResponseWriter, r *http.Request) {
+	if rpc.WalletClient == nil {
+		http.Error(w, "Wallet RPC client not initialized", http.StatusServiceUnavailable)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	policy, err := services.FetchTSpendPolicy(ctx, mux.Vars(r)["hash"], r.URL.Query().Get("ticket"))
+	if err != nil {
+		writeTreasuryPolicyError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(policy)
+}
+
+// SetTSpendPolicyHandler sets the wallet's vote on a treasury spend
+func SetTSpendPolicyHandler(w http.ResponseWriter, r *http.Request) {
+	if rpc.WalletClient == nil {
+		http.Error(w, "Wallet RPC client not initialized", http.StatusServiceUnavailable)
+		return
+	}
+
+	var req types.TSpendPolicyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	policy, err := services.SetTSpendPolicy(ctx, req)
+	if err != nil {
+		writeTreasuryPolicyError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(policy)
+}
+
+// writeTreasuryPolicyError maps treasury policy errors to status codes
+func writeTreasuryPolicyError(w http.ResponseWriter, err error) {
+	if errors.Is(err, services.ErrInvalidTreasuryPolicy) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	log.Printf("Treasury policy operation failed: %v", err)
+	http.Error(w, err.Error(), http.StatusInternalServerError)
+}
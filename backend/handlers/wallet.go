@@ -7,122 +7,21 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
 	"log"
 	"net/http"
 	"strings"
-	"sync"
 	"time"
 
 	"decred-pulse-backend/rpc"
 	"decred-pulse-backend/services"
 	"decred-pulse-backend/types"
 
-	pb "decred.org/dcrwallet/v4/rpc/walletrpc"
-
+	"github.com/gorilla/mux"
 	"github.com/gorilla/websocket"
 )
 
-// Global rescan stream management
-var (
-	activeRescanStream   pb.WalletService_RescanClient
-	activeRescanMutex    sync.RWMutex
-	rescanStreamChannels []chan *pb.RescanResponse
-	rescanChannelsMutex  sync.Mutex
-)
-
-// startRescanViaGrpc initiates a blockchain rescan using gRPC and broadcasts progress
-func startRescanViaGrpc(beginHeight int32) {
-	if rpc.WalletGrpcClient == nil {
-		log.Println("❌ Cannot start gRPC rescan: gRPC client not initialized")
-		return
-	}
-
-	ctx := context.Background()
-	req := &pb.RescanRequest{
-		BeginHeight: beginHeight,
-	}
-
-	stream, err := rpc.WalletGrpcClient.Rescan(ctx, req)
-	if err != nil {
-		log.Printf("❌ Failed to start gRPC rescan: %v", err)
-		return
-	}
-
-	log.Println("✅ gRPC rescan stream started - broadcasting progress updates")
-
-	// Store active stream
-	activeRescanMutex.Lock()
-	activeRescanStream = stream
-	activeRescanMutex.Unlock()
-
-	// Receive and broadcast progress updates
-	for {
-		update, err := stream.Recv()
-		if err == io.EOF {
-			log.Println("✅ gRPC rescan stream completed")
-			break
-		}
-		if err != nil {
-			log.Printf("❌ gRPC rescan stream error: %v", err)
-			break
-		}
-
-		// Broadcast to all listening WebSocket clients
-		rescanChannelsMutex.Lock()
-		for _, ch := range rescanStreamChannels {
-			select {
-			case ch <- update:
-			default:
-				// Channel full, skip
-			}
-		}
-		rescanChannelsMutex.Unlock()
-
-		log.Printf("📊 Rescan progress: block %d", update.RescannedThrough)
-	}
-
-	// Clear active stream
-	activeRescanMutex.Lock()
-	activeRescanStream = nil
-	activeRescanMutex.Unlock()
-
-	// Notify all listeners that stream ended
-	rescanChannelsMutex.Lock()
-	for _, ch := range rescanStreamChannels {
-		close(ch)
-	}
-	rescanStreamChannels = nil
-	rescanChannelsMutex.Unlock()
-
-	log.Println("✅ Rescan completed - all transactions imported")
-}
-
-// subscribeToRescanUpdates creates a channel that receives rescan progress updates
-func subscribeToRescanUpdates() chan *pb.RescanResponse {
-	ch := make(chan *pb.RescanResponse, 10)
-	rescanChannelsMutex.Lock()
-	rescanStreamChannels = append(rescanStreamChannels, ch)
-	rescanChannelsMutex.Unlock()
-	return ch
-}
-
-// unsubscribeFromRescanUpdates removes a channel from receiving updates
-func unsubscribeFromRescanUpdates(ch chan *pb.RescanResponse) {
-	rescanChannelsMutex.Lock()
-	defer rescanChannelsMutex.Unlock()
-
-	for i, c := range rescanStreamChannels {
-		if c == ch {
-			rescanStreamChannels = append(rescanStreamChannels[:i], rescanStreamChannels[i+1:]...)
-			break
-		}
-	}
-}
-
-// Pending rescan tracking is now in services package
-
 // GetWalletStatusHandler handles requests for wallet status
 func GetWalletStatusHandler(w http.ResponseWriter, r *http.Request) {
 	if rpc.WalletClient == nil {
@@ -216,51 +115,16 @@ func ImportXpubHandler(w http.ResponseWriter, r *http.Request) {
 		accountName = "imported"
 	}
 
-	// Import the xpub asynchronously
-	// We run it in a goroutine and return immediately so the frontend doesn't timeout
+	// Import the xpub as a tracked background job
+	// We return the job ID immediately so the frontend doesn't timeout
 	log.Printf("Starting xpub import for account: %s", accountName)
+	job := services.StartXpubImport(accountName, req.Xpub)
 
-	// Start the import in a goroutine
-	// WebSocket stream will automatically detect and show rescan progress
-	go func() {
-		ctx := context.Background()
-
-		// Step 1: Import xpub
-		params := []json.RawMessage{
-			json.RawMessage(fmt.Sprintf(`"%s"`, accountName)),
-			json.RawMessage(fmt.Sprintf(`"%s"`, req.Xpub)),
-		}
-
-		log.Printf("Step 1/3: Importing xpub for account '%s'", accountName)
-		result, err := rpc.WalletClient.RawRequest(ctx, "importxpub", params)
-		if err != nil {
-			log.Printf("Failed to import xpub: %v", err)
-			return
-		}
-		log.Printf("Xpub import completed: %v", string(result))
-
-		// Step 2: Discover address usage
-		log.Printf("Step 2/3: Discovering address usage across blockchain...")
-		_, err = rpc.WalletClient.RawRequest(ctx, "discoverusage", nil)
-		if err != nil {
-			log.Printf("Failed to discover address usage: %v", err)
-			return
-		}
-		log.Printf("Address discovery completed - wallet database updated")
-
-		// Step 3: Wait for wallet to be ready, then rescan from block 0 via gRPC
-		log.Printf("Step 3/3: Waiting 5 seconds for wallet to load transaction filter...")
-		time.Sleep(5 * time.Second)
-
-		// Start gRPC rescan from genesis
-		log.Printf("Starting gRPC rescan from block 0...")
-		startRescanViaGrpc(0)
-	}()
-
-	// Return immediately - the frontend will poll wallet status to track rescan progress
+	// Return immediately - the frontend tracks progress through the job
 	response := types.ImportXpubResponse{
 		Success: true,
 		Message: fmt.Sprintf("Xpub import started for account '%s'. Now discovering addresses and rescanning blockchain. This typically takes 5-30 minutes.", accountName),
+		JobID:   job.ID,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -280,78 +144,119 @@ func RescanWalletHandler(w http.ResponseWriter, r *http.Request) {
 		req.BeginHeight = 0
 	}
 
-	// Start rescan in a goroutine - it's a long-running operation
-	// The gRPC Rescan() method will stream progress updates that the WebSocket handler can forward
+	// Start rescan as a tracked job owned by the rescan controller
+	// The gRPC Rescan() stream records exact progress that the WebSocket handlers forward
 	log.Printf("Starting wallet rescan from block %d via gRPC", req.BeginHeight)
 
-	go func() {
-		ctx := context.Background()
-
-		// Step 1: Discover address usage via JSON-RPC
-		log.Printf("Step 1/2: Discovering address usage across blockchain for all accounts...")
-		_, err := rpc.WalletClient.RawRequest(ctx, "discoverusage", nil)
-		if err != nil {
-			log.Printf("Failed to discover address usage: %v", err)
-			return
-		}
-		log.Printf("Address discovery completed - wallet database updated")
-
-		// Step 2: Wait for wallet to load transaction filter
-		log.Printf("Step 2/2: Waiting 5 seconds for wallet to load transaction filter...")
-		time.Sleep(5 * time.Second)
+	queued := services.IsRescanActive()
+	job, err := services.StartRescan(req.BeginHeight, req.Queue)
+	if errors.Is(err, services.ErrRescanInProgress) {
+		http.Error(w, "A rescan is already in progress. Set \"queue\": true to run it afterwards.", http.StatusConflict)
+		return
+	}
+	if err != nil {
+		log.Printf("Error starting rescan: %v", err)
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
 
-		// Step 3: Start rescan via gRPC - this provides a progress stream
-		log.Printf("Starting gRPC rescan from block %d...", req.BeginHeight)
-		startRescanViaGrpc(int32(req.BeginHeight))
-	}()
+	message := fmt.Sprintf("Discovering addresses and rescanning blockchain from block %d. This may take 30+ minutes.", req.BeginHeight)
+	if queued {
+		message = fmt.Sprintf("Rescan from block %d queued behind the active rescan.", req.BeginHeight)
+	}
 
 	// Return immediately so frontend can start polling for progress
 	response := types.RescanResponse{
 		Success: true,
-		Message: fmt.Sprintf("Discovering addresses and rescanning blockchain from block %d. This may take 30+ minutes.", req.BeginHeight),
+		Message: message,
+		JobID:   job.ID,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(response)
 }
 
-// GetSyncProgressHandler handles requests for wallet sync progress from log files
+// GetRescanStatusHandler returns the rescan controller state
+func GetRescanStatusHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(services.GetRescanStatus())
+}
+
+// CancelRescanHandler cancels the active rescan
+func CancelRescanHandler(w http.ResponseWriter, r *http.Request) {
+	if err := services.CancelRescan(); err != nil {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(types.RescanResponse{
+		Success: true,
+		Message: "Rescan cancellation requested",
+	})
+}
+
+// GetSyncProgressHandler handles requests for wallet rescan progress
 func GetSyncProgressHandler(w http.ResponseWriter, r *http.Request) {
-	// Get sync progress from log file parsing
-	isRescanning, scanHeight, err := services.ParseWalletLogsForRescan()
+	status := services.GetRescanStatus()
+
+	response := types.SyncProgressResponse{
+		IsRescanning: status.IsRescanning,
+		ScanHeight:   status.ScanHeight,
+		ChainHeight:  status.ChainHeight,
+		Progress:     status.Progress,
+		Message:      status.Message,
+	}
+	if !status.IsRescanning {
+		response.Progress = 100
+		response.Message = "No active rescan"
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// GetWalletNetworkHandler reports the wallet's sync mode (SPV or RPC) and
+// its sync state as seen by the wallet itself
+func GetWalletNetworkHandler(w http.ResponseWriter, r *http.Request) {
+	if rpc.WalletClient == nil {
+		http.Error(w, "Wallet RPC client not initialized", http.StatusServiceUnavailable)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	network, err := services.FetchWalletNetwork(ctx)
 	if err != nil {
-		log.Printf("Error parsing wallet logs: %v", err)
+		log.Printf("Error fetching wallet network state: %v", err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	// Get chain height from dcrd for progress calculation
-	var progress float64 = 100.0
-	var chainHeight int64 = 0
-	var message string = "No active rescan"
-
-	if isRescanning && rpc.DcrdClient != nil {
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-		defer cancel()
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(network)
+}
 
-		height, err := rpc.DcrdClient.GetBlockCount(ctx)
-		if err == nil {
-			chainHeight = height
-			progress = (float64(scanHeight) / float64(chainHeight)) * 100
-			message = fmt.Sprintf("Rescanning... %d/%d blocks", scanHeight, chainHeight)
-		}
+// ListWalletPeersHandler lists the wallet's SPV peers
+func ListWalletPeersHandler(w http.ResponseWriter, r *http.Request) {
+	if rpc.WalletClient == nil {
+		http.Error(w, "Wallet RPC client not initialized", http.StatusServiceUnavailable)
+		return
 	}
 
-	response := types.SyncProgressResponse{
-		IsRescanning: isRescanning,
-		ScanHeight:   scanHeight,
-		ChainHeight:  chainHeight,
-		Progress:     progress,
-		Message:      message,
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	peers, err := services.FetchWalletPeers(ctx)
+	if err != nil {
+		log.Printf("Error fetching wallet peers: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
+	json.NewEncoder(w).Encode(peers)
 }
 
 // ListTransactionsHandler handles requests for wallet transaction history
@@ -393,13 +298,9 @@ func ListTransactionsHandler(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(transactions)
 }
 
-// StreamRescanProgressHandler streams rescan progress via WebSocket using gRPC
+// StreamRescanProgressHandler streams rescan controller progress via WebSocket.
+// The stream closes once the rescan finishes.
 func StreamRescanProgressHandler(w http.ResponseWriter, r *http.Request) {
-	if rpc.WalletGrpcClient == nil {
-		http.Error(w, "Wallet gRPC client not initialized", http.StatusServiceUnavailable)
-		return
-	}
-
 	// Upgrade HTTP connection to WebSocket
 	upgrader := websocket.Upgrader{
 		CheckOrigin: func(r *http.Request) bool {
@@ -416,110 +317,187 @@ func StreamRescanProgressHandler(w http.ResponseWriter, r *http.Request) {
 
 	log.Println("WebSocket connection established for rescan progress streaming")
 
-	// Get chain height from dcrd for progress calculation
-	chainHeight := int64(1)
-	if rpc.DcrdClient != nil {
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-		height, err := rpc.DcrdClient.GetBlockCount(ctx)
-		cancel()
-		if err == nil {
-			chainHeight = height
+	updates := services.SubscribeRescanStatus()
+	defer services.UnsubscribeRescanStatus(updates)
+
+	status := services.GetRescanStatus()
+	if err := conn.WriteJSON(status); err != nil {
+		return
+	}
+	if !status.IsRescanning {
+		return
+	}
+
+	keepAliveTicker := time.NewTicker(5 * time.Second)
+	defer keepAliveTicker.Stop()
+
+	for {
+		select {
+		case status := <-updates:
+			if err := conn.WriteJSON(status); err != nil {
+				log.Printf("Failed to write to WebSocket: %v", err)
+				return
+			}
+			if !status.IsRescanning {
+				log.Printf("Rescan %s, closing WebSocket stream", status.State)
+				return
+			}
+		case <-keepAliveTicker.C:
+			if err := conn.WriteMessage(websocket.PingMessage, []byte{}); err != nil {
+				return
+			}
 		}
 	}
+}
 
-	log.Println("Starting rescan progress monitoring (polling log-based method)")
+// GetWalletLockStatusHandler returns the wallet and per-account lock state
+func GetWalletLockStatusHandler(w http.ResponseWriter, r *http.Request) {
+	if rpc.WalletClient == nil {
+		http.Error(w, "Wallet RPC client not initialized", http.StatusServiceUnavailable)
+		return
+	}
 
-	// Poll rescan progress and stream to WebSocket
-	ticker := time.NewTicker(1 * time.Second)
-	defer ticker.Stop()
+	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+	defer cancel()
 
-	// Track consecutive "not rescanning" responses to avoid premature close
-	notRescanningCount := 0
+	status, err := services.FetchWalletLockStatus(ctx)
+	if err != nil {
+		log.Printf("Error fetching wallet lock status: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
 
-	// Check if a rescan is pending
-	isPending, pendingGracePeriod := services.IsPendingRescan()
-	gracePeriodTicks := 8            // Default grace period
-	maxNotRescanningBeforeClose := 5 // Default: Wait for 5 consecutive "not rescanning" before closing
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(status)
+}
 
-	if isPending {
-		gracePeriodTicks = pendingGracePeriod
-		maxNotRescanningBeforeClose = 30 // Wait 30 more seconds after grace period when rescan is pending
-		log.Printf("Pending rescan detected - using extended grace period of %d seconds and extended timeout of %d checks", gracePeriodTicks, maxNotRescanningBeforeClose)
+// UnlockWalletHandler unlocks the wallet with walletpassphrase
+func UnlockWalletHandler(w http.ResponseWriter, r *http.Request) {
+	if rpc.WalletClient == nil {
+		http.Error(w, "Wallet RPC client not initialized", http.StatusServiceUnavailable)
+		return
 	}
 
-	tickCount := 0
+	var req types.WalletUnlockRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.Passphrase == "" {
+		http.Error(w, "Passphrase is required", http.StatusBadRequest)
+		return
+	}
+	if req.Timeout < 0 {
+		http.Error(w, "Timeout must not be negative", http.StatusBadRequest)
+		return
+	}
 
-	for {
-		select {
-		case <-ticker.C:
-			tickCount++
-			// Check rescan progress
-			isRescanning, scanHeight, err := services.ParseWalletLogsForRescan()
-			if err != nil {
-				log.Printf("Error parsing logs: %v", err)
-				continue
-			}
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
 
-			// Update chain height
-			if rpc.DcrdClient != nil {
-				ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
-				height, err := rpc.DcrdClient.GetBlockCount(ctx)
-				cancel()
-				if err == nil {
-					chainHeight = height
-				}
-			}
+	if err := services.UnlockWallet(ctx, req.Passphrase, req.Timeout); err != nil {
+		writeLockError(w, err)
+		return
+	}
 
-			progress := (float64(scanHeight) / float64(chainHeight)) * 100
-			if progress > 100 {
-				progress = 100
-			}
+	message := "Wallet unlocked until locked explicitly"
+	if req.Timeout > 0 {
+		message = fmt.Sprintf("Wallet unlocked for %d seconds", req.Timeout)
+	}
 
-			progressData := map[string]interface{}{
-				"isRescanning": isRescanning,
-				"scanHeight":   scanHeight,
-				"chainHeight":  chainHeight,
-				"progress":     progress,
-			}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"message": message,
+	})
+}
 
-			if isRescanning {
-				progressData["message"] = fmt.Sprintf("Rescanning... %d/%d blocks", scanHeight, chainHeight)
-				log.Printf("Rescan progress: %d/%d (%.2f%%)", scanHeight, chainHeight, progress)
-				notRescanningCount = 0 // Reset counter
-				// Pending rescan flag is cleared automatically in CheckRescanProgress
-			} else {
-				// Only start counting "not rescanning" after grace period
-				if tickCount > gracePeriodTicks {
-					notRescanningCount++
-					log.Printf("Rescan not detected in logs (count: %d/%d, after grace period)", notRescanningCount, maxNotRescanningBeforeClose)
-					progressData["message"] = "Checking rescan status..."
-				} else {
-					log.Printf("Grace period: %d/%d seconds - waiting for rescan to start", tickCount, gracePeriodTicks)
-					if isPending {
-						progressData["message"] = "Discovering addresses, rescan will start soon..."
-					} else {
-						progressData["message"] = "Starting rescan..."
-					}
-				}
-			}
+// LockWalletHandler locks the wallet with walletlock
+func LockWalletHandler(w http.ResponseWriter, r *http.Request) {
+	if rpc.WalletClient == nil {
+		http.Error(w, "Wallet RPC client not initialized", http.StatusServiceUnavailable)
+		return
+	}
 
-			// Send update to client
-			if err := conn.WriteJSON(progressData); err != nil {
-				log.Printf("Failed to write to WebSocket: %v", err)
-				return
-			}
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
 
-			// Only close if we've had multiple consecutive "not rescanning" responses AFTER grace period
-			if notRescanningCount >= maxNotRescanningBeforeClose {
-				log.Println("Rescan complete (no activity detected), closing WebSocket stream")
-				progressData["message"] = "Rescan complete"
-				progressData["isRescanning"] = false
-				conn.WriteJSON(progressData)
-				services.ClearPendingRescan() // Clear pending flag when closing
-				return
-			}
-		}
+	if err := services.LockWallet(ctx); err != nil {
+		writeLockError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"message": "Wallet locked",
+	})
+}
+
+// UnlockAccountHandler unlocks an individually encrypted account
+func UnlockAccountHandler(w http.ResponseWriter, r *http.Request) {
+	if rpc.WalletClient == nil {
+		http.Error(w, "Wallet RPC client not initialized", http.StatusServiceUnavailable)
+		return
+	}
+
+	account := mux.Vars(r)["account"]
+
+	var req types.AccountUnlockRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.Passphrase == "" {
+		http.Error(w, "Passphrase is required", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	if err := services.UnlockAccount(ctx, account, req.Passphrase); err != nil {
+		writeLockError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"message": fmt.Sprintf("Account %s unlocked", account),
+	})
+}
+
+// LockAccountHandler locks an individually encrypted account
+func LockAccountHandler(w http.ResponseWriter, r *http.Request) {
+	if rpc.WalletClient == nil {
+		http.Error(w, "Wallet RPC client not initialized", http.StatusServiceUnavailable)
+		return
 	}
 
-	log.Println("WebSocket connection closed")
+	account := mux.Vars(r)["account"]
+
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	if err := services.LockAccount(ctx, account); err != nil {
+		writeLockError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"message": fmt.Sprintf("Account %s locked", account),
+	})
+}
+
+// writeLockError reports a lock/unlock failure without echoing request data
+func writeLockError(w http.ResponseWriter, err error) {
+	if errors.Is(err, services.ErrIncorrectPassphrase) {
+		http.Error(w, "Incorrect passphrase", http.StatusUnauthorized)
+		return
+	}
+	log.Printf("Wallet lock operation failed: %v", err)
+	http.Error(w, err.Error(), http.StatusInternalServerError)
 }
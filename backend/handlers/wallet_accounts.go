@@ -0,0 +1,131 @@
+// Copyright (c) 2015-2025 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"decred-pulse-backend/rpc"
+	"decred-pulse-backend/services"
+	"decred-pulse-backend/types"
+)
+
+// CreateAccountHandler creates a new wallet account
+func CreateAccountHandler(w http.ResponseWriter, r *http.Request) {
+	if rpc.WalletClient == nil {
+		http.Error(w, "Wallet RPC client not initialized", http.StatusServiceUnavailable)
+		return
+	}
+
+	var req types.AccountNameRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	details, err := services.CreateAccount(ctx, req.Name)
+	if err != nil {
+		writeAccountError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(details)
+}
+
+// RenameAccountHandler renames a wallet account
+func RenameAccountHandler(w http.ResponseWriter, r *http.Request) {
+	if rpc.WalletClient == nil {
+		http.Error(w, "Wallet RPC client not initialized", http.StatusServiceUnavailable)
+		return
+	}
+
+	account := mux.Vars(r)["account"]
+
+	var req types.AccountNameRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	details, err := services.RenameAccount(ctx, account, req.Name)
+	if err != nil {
+		writeAccountError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(details)
+}
+
+// GetAccountXpubHandler exports the extended public key of an account
+func GetAccountXpubHandler(w http.ResponseWriter, r *http.Request) {
+	if rpc.WalletClient == nil {
+		http.Error(w, "Wallet RPC client not initialized", http.StatusServiceUnavailable)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+	defer cancel()
+
+	xpub, err := services.FetchAccountXpub(ctx, mux.Vars(r)["account"])
+	if err != nil {
+		writeAccountError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(xpub)
+}
+
+// GetAccountAddressIndexHandler returns the next external and internal
+// address indexes of an account
+func GetAccountAddressIndexHandler(w http.ResponseWriter, r *http.Request) {
+	if rpc.WalletClient == nil {
+		http.Error(w, "Wallet RPC client not initialized", http.StatusServiceUnavailable)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
+	defer cancel()
+
+	details, err := services.FetchAccountDetails(ctx, mux.Vars(r)["account"])
+	if err != nil {
+		writeAccountError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(details)
+}
+
+// writeAccountError maps account, address and output errors to status codes
+func writeAccountError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrAccountNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, services.ErrInvalidAccountName):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, services.ErrWalletLocked):
+		http.Error(w, "Wallet is locked, unlock it first", http.StatusLocked)
+	default:
+		log.Printf("Wallet operation failed: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,110 @@
+// Copyright (c) 2015-2025 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"decred-pulse-backend/rpc"
+	"decred-pulse-backend/services"
+	"decred-pulse-backend/types"
+)
+
+// ListAddressesHandler returns a page of an account's addresses with their
+// derivation paths and usage
+func ListAddressesHandler(w http.ResponseWriter, r *http.Request) {
+	if rpc.WalletClient == nil {
+		http.Error(w, "Wallet RPC client not initialized", http.StatusServiceUnavailable)
+		return
+	}
+
+	query := r.URL.Query()
+	filter := services.AddressFilter{
+		Account:  query.Get("account"),
+		Page:     1,
+		PageSize: 50,
+	}
+	if s := query.Get("branch"); s != "" {
+		branch, err := strconv.ParseUint(s, 10, 32)
+		if err != nil || branch > 1 {
+			http.Error(w, "branch must be 0 (receive) or 1 (change)", http.StatusBadRequest)
+			return
+		}
+		b := uint32(branch)
+		filter.Branch = &b
+	}
+	if s := query.Get("used"); s != "" {
+		used, err := strconv.ParseBool(s)
+		if err != nil {
+			http.Error(w, "used must be true or false", http.StatusBadRequest)
+			return
+		}
+		filter.Used = &used
+	}
+	if p, err := strconv.Atoi(query.Get("page")); err == nil && p > 0 {
+		filter.Page = p
+	}
+	if ps, err := strconv.Atoi(query.Get("pageSize")); err == nil && ps > 0 {
+		filter.PageSize = ps
+		if filter.PageSize > 500 {
+			filter.PageSize = 500
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 60*time.Second)
+	defer cancel()
+
+	response, err := services.ListAddresses(ctx, filter)
+	if err != nil {
+		writeAccountError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// NewAddressHandler derives the next receive or change address of an account
+func NewAddressHandler(w http.ResponseWriter, r *http.Request) {
+	if rpc.WalletClient == nil {
+		http.Error(w, "Wallet RPC client not initialized", http.StatusServiceUnavailable)
+		return
+	}
+
+	var req types.NewAddressRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.Amount < 0 {
+		http.Error(w, "Amount must not be negative", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	address, err := services.GenerateAddress(ctx, req)
+	switch {
+	case errors.Is(err, services.ErrGapLimit):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case errors.Is(err, services.ErrInvalidGapPolicy), errors.Is(err, services.ErrInvalidBranch):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case err != nil:
+		writeAccountError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(address)
+}
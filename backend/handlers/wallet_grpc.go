@@ -6,18 +6,19 @@ package handlers
 
 import (
 	"context"
-	"fmt"
 	"log"
 	"net/http"
 	"time"
 
 	"decred-pulse-backend/rpc"
+	"decred-pulse-backend/services"
+	"decred-pulse-backend/types"
 
 	"github.com/gorilla/websocket"
 )
 
 // StreamRescanGrpcHandler streams rescan progress via WebSocket
-// by subscribing to the gRPC rescan progress broadcast
+// by subscribing to the rescan controller, which records the gRPC stream
 func StreamRescanGrpcHandler(w http.ResponseWriter, r *http.Request) {
 	// Upgrade to WebSocket
 	upgrader := websocket.Upgrader{
@@ -36,97 +37,110 @@ func StreamRescanGrpcHandler(w http.ResponseWriter, r *http.Request) {
 	log.Println("🔌 WebSocket: Client connected for rescan progress")
 
 	// Subscribe to rescan progress updates
-	progressCh := subscribeToRescanUpdates()
-	defer unsubscribeFromRescanUpdates(progressCh)
-
-	// Get chain height for progress calculation
-	getChainHeight := func() int64 {
-		if rpc.DcrdClient != nil {
-			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
-			defer cancel()
-			height, err := rpc.DcrdClient.GetBlockCount(ctx)
-			if err == nil {
-				return height
-			}
-		}
-		return 0
-	}
-
-	chainHeight := getChainHeight()
+	updates := services.SubscribeRescanStatus()
+	defer services.UnsubscribeRescanStatus(updates)
 
 	// Keep-alive ticker
 	keepAliveTicker := time.NewTicker(5 * time.Second)
 	defer keepAliveTicker.Stop()
 
 	// Initial check - send current status immediately
-	activeRescanMutex.RLock()
-	hasActiveRescan := activeRescanStream != nil
-	activeRescanMutex.RUnlock()
-
-	if !hasActiveRescan {
+	status := services.GetRescanStatus()
+	if !status.IsRescanning {
 		// No active rescan - send "synced" status and keep connection open
-		conn.WriteJSON(map[string]interface{}{
-			"isRescanning": false,
-			"message":      "Wallet fully synced",
-			"progress":     100.0,
-			"scanHeight":   chainHeight,
-			"chainHeight":  chainHeight,
-		})
+		conn.WriteJSON(syncedRescanStatus(status))
+	} else {
+		conn.WriteJSON(status)
 	}
 
 	log.Println("📡 Waiting for rescan progress updates...")
 
 	for {
 		select {
-		case update, ok := <-progressCh:
-			if !ok {
-				// Channel closed - rescan finished
-				log.Println("✅ Rescan complete - sending final sync status")
-				conn.WriteJSON(map[string]interface{}{
-					"isRescanning": false,
-					"message":      "Wallet fully synced",
-					"progress":     100.0,
-					"scanHeight":   chainHeight,
-					"chainHeight":  chainHeight,
-				})
+		case status := <-updates:
+			if !status.IsRescanning {
+				if status.State == services.RescanStateIdle {
+					continue
+				}
+				// Rescan finished - send the outcome and close
+				log.Printf("✅ Rescan %s - sending final sync status", status.State)
+				conn.WriteJSON(syncedRescanStatus(status))
 				return
 			}
 
-			// Update chain height periodically
-			chainHeight = getChainHeight()
+			if err := conn.WriteJSON(status); err != nil {
+				log.Printf("❌ WebSocket write failed: %v", err)
+				return
+			}
 
-			// Calculate progress
-			rescannedHeight := int64(update.RescannedThrough)
-			progress := 0.0
-			if chainHeight > 0 {
-				progress = (float64(rescannedHeight) / float64(chainHeight)) * 100
-				if progress > 100 {
-					progress = 100
-				}
+		case <-keepAliveTicker.C:
+			// Send ping to detect if client disconnected
+			if err := conn.WriteMessage(websocket.PingMessage, []byte{}); err != nil {
+				log.Println("🔌 WebSocket client disconnected")
+				return
 			}
+		}
+	}
+}
 
-			message := fmt.Sprintf("Rescanning blockchain... %d/%d blocks", rescannedHeight, chainHeight)
+// syncedRescanStatus prepares a finished or idle controller status for
+// clients that only track isRescanning/progress/message
+func syncedRescanStatus(status types.RescanStatus) types.RescanStatus {
+	if status.State == services.RescanStateCompleted || status.State == services.RescanStateIdle {
+		// Report the current chain tip when no rescan has run yet
+		if status.ChainHeight == 0 {
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+			status.ChainHeight = services.WalletChainHeight(ctx)
+			cancel()
+		}
+		status.Progress = 100
+		status.Message = "Wallet fully synced"
+		if status.ScanHeight > status.ChainHeight {
+			status.ChainHeight = status.ScanHeight
+		}
+		status.ScanHeight = status.ChainHeight
+	}
+	return status
+}
 
-			// Forward to WebSocket client
-			progressData := map[string]interface{}{
-				"isRescanning": true,
-				"scanHeight":   rescannedHeight,
-				"chainHeight":  chainHeight,
-				"progress":     progress,
-				"message":      message,
-			}
+// StreamWalletEventsHandler streams wallet transaction, confirmation and
+// account notifications from the dcrwallet gRPC notification streams via WebSocket
+func StreamWalletEventsHandler(w http.ResponseWriter, r *http.Request) {
+	if rpc.WalletGrpcClient == nil {
+		http.Error(w, "Wallet gRPC client not initialized", http.StatusServiceUnavailable)
+		return
+	}
 
-			log.Printf("📊 Rescan progress: %d/%d (%.1f%%)", rescannedHeight, chainHeight, progress)
+	upgrader := websocket.Upgrader{
+		CheckOrigin: func(r *http.Request) bool {
+			return true // Allow all origins for development
+		},
+	}
 
-			if err := conn.WriteJSON(progressData); err != nil {
-				log.Printf("❌ WebSocket write failed: %v", err)
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		log.Printf("Failed to upgrade to WebSocket: %v", err)
+		return
+	}
+	defer conn.Close()
+
+	log.Println("📡 WebSocket connection established for wallet notifications")
+
+	events := services.SubscribeWalletEvents()
+	defer services.UnsubscribeWalletEvents(events)
+
+	keepAliveTicker := time.NewTicker(5 * time.Second)
+	defer keepAliveTicker.Stop()
+
+	for {
+		select {
+		case event := <-events:
+			if err := conn.WriteJSON(event); err != nil {
+				log.Printf("Wallet notification WebSocket write failed: %v", err)
 				return
 			}
-
 		case <-keepAliveTicker.C:
-			// Send ping to detect if client disconnected
 			if err := conn.WriteMessage(websocket.PingMessage, []byte{}); err != nil {
-				log.Println("🔌 WebSocket client disconnected")
 				return
 			}
 		}
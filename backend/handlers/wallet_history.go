@@ -0,0 +1,120 @@
+// Copyright (c) 2015-2025 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"decred-pulse-backend/rpc"
+	"decred-pulse-backend/services"
+)
+
+// GetWalletHistoryHandler returns one page of the complete wallet history,
+// newest first. Pass the nextCursor of a page as cursor to get the next one.
+func GetWalletHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	if rpc.WalletClient == nil || rpc.WalletGrpcClient == nil {
+		http.Error(w, "Wallet RPC client not initialized", http.StatusServiceUnavailable)
+		return
+	}
+
+	filter, err := parseHistoryFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Sparse filters may walk a large part of the chain
+	ctx, cancel := context.WithTimeout(r.Context(), 60*time.Second)
+	defer cancel()
+
+	response, err := services.FetchHistory(ctx, filter)
+	if err != nil {
+		log.Printf("Error fetching wallet history: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// parseHistoryFilter reads the history filters from the query string
+func parseHistoryFilter(r *http.Request) (services.HistoryFilter, error) {
+	query := r.URL.Query()
+	filter := services.HistoryFilter{
+		Cursor:  query.Get("cursor"),
+		Account: query.Get("account"),
+	}
+
+	if s := query.Get("limit"); s != "" {
+		limit, err := strconv.Atoi(s)
+		if err != nil || limit <= 0 {
+			return filter, fmt.Errorf("invalid limit %q", s)
+		}
+		filter.Limit = limit
+	}
+	if s := query.Get("txType"); s != "" {
+		filter.TxTypes = strings.Split(s, ",")
+	}
+	if s := query.Get("category"); s != "" {
+		filter.Categories = strings.Split(s, ",")
+	}
+
+	var err error
+	if filter.Since, err = parseHistoryDate(query.Get("since"), false); err != nil {
+		return filter, err
+	}
+	if filter.Until, err = parseHistoryDate(query.Get("until"), true); err != nil {
+		return filter, err
+	}
+
+	for name, target := range map[string]**float64{"minAmount": &filter.MinAmount, "maxAmount": &filter.MaxAmount} {
+		s := query.Get(name)
+		if s == "" {
+			continue
+		}
+		v, err := strconv.ParseFloat(s, 64)
+		if err != nil || v < 0 {
+			return filter, fmt.Errorf("invalid %s %q", name, s)
+		}
+		*target = &v
+	}
+
+	if s := query.Get("mixed"); s != "" {
+		mixed, err := strconv.ParseBool(s)
+		if err != nil {
+			return filter, fmt.Errorf("invalid mixed %q", s)
+		}
+		filter.Mixed = &mixed
+	}
+
+	return filter, nil
+}
+
+// parseHistoryDate accepts RFC 3339 timestamps or YYYY-MM-DD dates in UTC.
+// A date used as the end of a range includes the whole day.
+func parseHistoryDate(s string, endOfRange bool) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q, use YYYY-MM-DD or RFC 3339", s)
+	}
+	if endOfRange {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
@@ -0,0 +1,196 @@
+// Copyright (c) 2015-2025 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"decred-pulse-backend/rpc"
+	"decred-pulse-backend/services"
+	"decred-pulse-backend/types"
+)
+
+// GetWalletLoaderHandler reports whether a wallet exists, is loaded and syncing
+func GetWalletLoaderHandler(w http.ResponseWriter, r *http.Request) {
+	if rpc.WalletLoaderClient == nil {
+		http.Error(w, "Wallet gRPC client not initialized", http.StatusServiceUnavailable)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
+	defer cancel()
+
+	loaderStatus, err := services.FetchWalletLoaderStatus(ctx)
+	if err != nil {
+		log.Printf("Error fetching wallet loader status: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(loaderStatus)
+}
+
+// CreateWalletHandler creates a wallet from a new seed. The seed is only ever
+// returned in this response.
+func CreateWalletHandler(w http.ResponseWriter, r *http.Request) {
+	if rpc.WalletLoaderClient == nil {
+		http.Error(w, "Wallet gRPC client not initialized", http.StatusServiceUnavailable)
+		return
+	}
+
+	var req types.WalletCreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 60*time.Second)
+	defer cancel()
+
+	resp, err := services.CreateWallet(ctx, req)
+	if err != nil {
+		writeWalletLoaderError(w, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(resp)
+}
+
+// RestoreWalletHandler restores a wallet from seed words or a hex seed
+func RestoreWalletHandler(w http.ResponseWriter, r *http.Request) {
+	if rpc.WalletLoaderClient == nil {
+		http.Error(w, "Wallet gRPC client not initialized", http.StatusServiceUnavailable)
+		return
+	}
+
+	var req types.WalletRestoreRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 60*time.Second)
+	defer cancel()
+
+	resp, err := services.RestoreWallet(ctx, req)
+	if err != nil {
+		writeWalletLoaderError(w, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(resp)
+}
+
+// CreateWatchOnlyWalletHandler creates a watch-only wallet from an xpub
+func CreateWatchOnlyWalletHandler(w http.ResponseWriter, r *http.Request) {
+	if rpc.WalletLoaderClient == nil {
+		http.Error(w, "Wallet gRPC client not initialized", http.StatusServiceUnavailable)
+		return
+	}
+
+	var req types.WalletWatchOnlyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 60*time.Second)
+	defer cancel()
+
+	resp, err := services.CreateWatchOnlyWallet(ctx, req)
+	if err != nil {
+		writeWalletLoaderError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(resp)
+}
+
+// OpenWalletHandler opens an existing wallet and starts syncing
+func OpenWalletHandler(w http.ResponseWriter, r *http.Request) {
+	if rpc.WalletLoaderClient == nil {
+		http.Error(w, "Wallet gRPC client not initialized", http.StatusServiceUnavailable)
+		return
+	}
+
+	var req types.WalletOpenRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 60*time.Second)
+	defer cancel()
+
+	resp, err := services.OpenWallet(ctx, req)
+	if err != nil {
+		writeWalletLoaderError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// StartWalletSyncHandler starts syncing the loaded wallet
+func StartWalletSyncHandler(w http.ResponseWriter, r *http.Request) {
+	if rpc.WalletLoaderClient == nil {
+		http.Error(w, "Wallet gRPC client not initialized", http.StatusServiceUnavailable)
+		return
+	}
+
+	var req types.WalletSyncRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	syncStatus, err := services.StartWalletSync(ctx, req)
+	if err != nil {
+		writeWalletLoaderError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(syncStatus)
+}
+
+// writeWalletLoaderError maps wallet loader errors to status codes
+func writeWalletLoaderError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidWalletRequest):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, services.ErrIncorrectPassphrase):
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+	case errors.Is(err, services.ErrWalletNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, services.ErrWalletExists), errors.Is(err, services.ErrWalletSyncing):
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		log.Printf("Wallet loader operation failed: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
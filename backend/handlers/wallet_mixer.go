@@ -0,0 +1,97 @@
+// Copyright (c) 2015-2025 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"decred-pulse-backend/rpc"
+	"decred-pulse-backend/services"
+	"decred-pulse-backend/types"
+)
+
+// GetMixerHandler returns the account mixer state and the mixed vs. unmixed
+// balance of every account
+func GetMixerHandler(w http.ResponseWriter, r *http.Request) {
+	if rpc.WalletClient == nil {
+		http.Error(w, "Wallet RPC client not initialized", http.StatusServiceUnavailable)
+		return
+	}
+
+	// Unclassified outputs need a transaction lookup each
+	ctx, cancel := context.WithTimeout(r.Context(), 60*time.Second)
+	defer cancel()
+
+	accounts, err := services.FetchAccountPrivacy(ctx)
+	if err != nil {
+		log.Printf("Error fetching account privacy: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(types.MixerResponse{
+		Status:   *services.FetchMixerStatus(),
+		Accounts: accounts,
+	})
+}
+
+// StartMixerHandler starts the account mixer
+func StartMixerHandler(w http.ResponseWriter, r *http.Request) {
+	if rpc.WalletGrpcClient == nil {
+		http.Error(w, "Wallet gRPC client not initialized", http.StatusServiceUnavailable)
+		return
+	}
+
+	var req types.MixerStartRequest
+	// An empty body uses the configured accounts
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	status, err := services.StartAccountMixer(ctx, req)
+	switch {
+	case errors.Is(err, services.ErrIncorrectPassphrase):
+		http.Error(w, "Incorrect passphrase", http.StatusUnauthorized)
+		return
+	case errors.Is(err, services.ErrWalletLocked):
+		http.Error(w, err.Error(), http.StatusLocked)
+		return
+	case errors.Is(err, services.ErrMixerRunning):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case err != nil:
+		log.Printf("Error starting account mixer: %v", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(status)
+}
+
+// StopMixerHandler stops the account mixer
+func StopMixerHandler(w http.ResponseWriter, r *http.Request) {
+	if !services.StopAccountMixer() {
+		http.Error(w, "Account mixer is not running", http.StatusConflict)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"message": "Account mixer stopping",
+	})
+}
@@ -0,0 +1,131 @@
+// Copyright (c) 2015-2025 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"decred-pulse-backend/rpc"
+	"decred-pulse-backend/services"
+	"decred-pulse-backend/types"
+)
+
+// ListOfflineTransactionsHandler lists prepared and broadcast offline transactions
+func ListOfflineTransactionsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(services.ListOfflineTransactions())
+}
+
+// PrepareOfflineTransactionHandler builds an unsigned transaction and exports
+// it for signing on an air-gapped machine
+func PrepareOfflineTransactionHandler(w http.ResponseWriter, r *http.Request) {
+	if rpc.WalletClient == nil || rpc.WalletGrpcClient == nil {
+		http.Error(w, "Wallet RPC client not initialized", http.StatusServiceUnavailable)
+		return
+	}
+
+	var req types.SendPrepareRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 60*time.Second)
+	defer cancel()
+
+	offline, err := services.PrepareOfflineTransaction(ctx, req)
+	if err != nil {
+		log.Printf("Error preparing offline transaction: %v", err)
+		code := http.StatusBadRequest
+		if errors.Is(err, services.ErrOutputReserved) {
+			code = http.StatusConflict
+		}
+		http.Error(w, err.Error(), code)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(offline)
+}
+
+// GetOfflineTransactionHandler returns an exported offline transaction
+func GetOfflineTransactionHandler(w http.ResponseWriter, r *http.Request) {
+	offline, err := services.GetOfflineTransaction(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(offline)
+}
+
+// SubmitOfflineTransactionHandler verifies a transaction signed offline
+// against the exported one and broadcasts it
+func SubmitOfflineTransactionHandler(w http.ResponseWriter, r *http.Request) {
+	if rpc.DcrdClient == nil && rpc.WalletClient == nil {
+		http.Error(w, "RPC client not initialized", http.StatusServiceUnavailable)
+		return
+	}
+
+	var req types.OfflineSubmitRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	offline, err := services.SubmitOfflineTransaction(ctx, mux.Vars(r)["id"], strings.TrimSpace(req.SignedHex))
+	if err != nil {
+		writeOfflineError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(offline)
+}
+
+// CancelOfflineTransactionHandler discards a prepared offline transaction and
+// unlocks its inputs
+func CancelOfflineTransactionHandler(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	if err := services.CancelOfflineTransaction(ctx, mux.Vars(r)["id"]); err != nil {
+		writeOfflineError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+	})
+}
+
+// writeOfflineError maps offline signing errors to status codes
+func writeOfflineError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrOfflineTxNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, services.ErrOfflineTxBroadcast), errors.Is(err, services.ErrOfflineInputSpent):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, services.ErrSignedTxMismatch):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		log.Printf("Offline transaction operation failed: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,109 @@
+// Copyright (c) 2015-2025 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"decred-pulse-backend/rpc"
+	"decred-pulse-backend/services"
+	"decred-pulse-backend/types"
+)
+
+// PrepareSendHandler builds an unsigned transaction and returns a fee preview
+// with a confirmation token. Nothing is signed or broadcast.
+func PrepareSendHandler(w http.ResponseWriter, r *http.Request) {
+	if rpc.WalletGrpcClient == nil {
+		http.Error(w, "Wallet gRPC client not initialized", http.StatusServiceUnavailable)
+		return
+	}
+
+	var req types.SendPrepareRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	preview, err := services.PrepareSend(ctx, req)
+	if err != nil {
+		log.Printf("Error preparing send: %v", err)
+		code := http.StatusBadRequest
+		if errors.Is(err, services.ErrOutputReserved) {
+			code = http.StatusConflict
+		}
+		http.Error(w, err.Error(), code)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(preview)
+}
+
+// ConfirmSendHandler signs and publishes a prepared transaction
+func ConfirmSendHandler(w http.ResponseWriter, r *http.Request) {
+	if rpc.WalletGrpcClient == nil {
+		http.Error(w, "Wallet gRPC client not initialized", http.StatusServiceUnavailable)
+		return
+	}
+
+	var req types.SendConfirmRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.Token == "" {
+		http.Error(w, "Preview token is required", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 60*time.Second)
+	defer cancel()
+
+	result, err := services.ConfirmSend(ctx, req.Token, req.Passphrase)
+	switch {
+	case errors.Is(err, services.ErrPreviewNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	case errors.Is(err, services.ErrIncorrectPassphrase):
+		http.Error(w, "Incorrect passphrase", http.StatusUnauthorized)
+		return
+	case errors.Is(err, services.ErrWalletLocked):
+		http.Error(w, err.Error(), http.StatusLocked)
+		return
+	case err != nil:
+		log.Printf("Error confirming send: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// CancelSendHandler discards a prepared transaction
+func CancelSendHandler(w http.ResponseWriter, r *http.Request) {
+	token := mux.Vars(r)["token"]
+
+	if err := services.CancelSend(token); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"message": "Prepared transaction discarded",
+	})
+}
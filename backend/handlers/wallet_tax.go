@@ -0,0 +1,260 @@
+// Copyright (c) 2015-2025 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"decred-pulse-backend/pricing"
+	"decred-pulse-backend/rpc"
+	"decred-pulse-backend/services"
+	"decred-pulse-backend/types"
+)
+
+// maxPriceFileSize bounds the uploaded price history
+const maxPriceFileSize = 10 << 20
+
+// TaxExportHandler exports every mined wallet transaction, classified for tax
+// and accounting. The optional request body is a CSV of date,price rows used
+// to value transactions in fiat, otherwise the configured price history is
+// used. format selects generic (default), koinly, cointracking or json output.
+func TaxExportHandler(w http.ResponseWriter, r *http.Request) {
+	if rpc.WalletClient == nil || rpc.WalletGrpcClient == nil {
+		http.Error(w, "Wallet RPC client not initialized", http.StatusServiceUnavailable)
+		return
+	}
+
+	query := r.URL.Query()
+	format := query.Get("format")
+	if format == "" {
+		format = "generic"
+	}
+	if format != "generic" && format != "koinly" && format != "cointracking" && format != "json" {
+		http.Error(w, "Invalid format, expected generic, koinly, cointracking or json", http.StatusBadRequest)
+		return
+	}
+	opts := services.TaxExportOptions{Account: query.Get("account")}
+	var err error
+	if opts.Since, err = parseHistoryDate(query.Get("since"), false); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if opts.Until, err = parseHistoryDate(query.Get("until"), true); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxPriceFileSize))
+	if err != nil {
+		http.Error(w, "Price file too large or unreadable", http.StatusBadRequest)
+		return
+	}
+	// Without an uploaded file, fall back to the configured price history,
+	// which is in the configured currency
+	currency := strings.ToUpper(query.Get("currency"))
+	prices := pricing.History()
+	if len(bytes.TrimSpace(body)) > 0 {
+		prices, err = pricing.ParsePriceHistory(bytes.NewReader(body))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if currency == "" {
+			currency = pricing.Currency()
+		}
+	} else {
+		if currency != "" && currency != pricing.Currency() {
+			http.Error(w, fmt.Sprintf("Currency %s does not match the configured price history (%s), upload a %s price file",
+				currency, pricing.Currency(), currency), http.StatusBadRequest)
+			return
+		}
+		currency = pricing.Currency()
+	}
+
+	// Exports walk the whole wallet history
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Minute)
+	defer cancel()
+
+	records, err := services.BuildTaxExport(ctx, prices, opts)
+	if err != nil {
+		log.Printf("Error building tax export: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	switch format {
+	case "json":
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"currency": currency,
+			"records":  records,
+		})
+	case "koinly":
+		writeCSV(w, "decred-koinly.csv", koinlyRows(records, currency))
+	case "cointracking":
+		writeCSV(w, "decred-cointracking.csv", coinTrackingRows(records))
+	default:
+		writeCSV(w, "decred-tax-export.csv", genericTaxRows(records, currency))
+	}
+}
+
+// genericTaxRows lists every record with DCR and fiat amounts
+func genericTaxRows(records []types.TaxRecord, currency string) [][]string {
+	cur := strings.ToLower(currency)
+	rows := [][]string{{
+		"date", "txid", "block_height", "kind", "accounts", "amount_dcr", "fee_dcr", "locked_dcr",
+		"price_" + cur, "amount_" + cur, "fee_" + cur, "description",
+	}}
+	for _, rec := range records {
+		price, fiatAmount, fiatFee := "", "", ""
+		if rec.HasPrice {
+			price = strconv.FormatFloat(rec.Price, 'f', -1, 64)
+			fiatAmount = formatFiat(rec.FiatAmount)
+			fiatFee = formatFiat(rec.FiatFee)
+		}
+		rows = append(rows, []string{
+			rec.Time.Format(time.RFC3339),
+			rec.TxID,
+			strconv.FormatInt(int64(rec.BlockHeight), 10),
+			rec.Kind,
+			strings.Join(rec.Accounts, ";"),
+			formatCSVAmount(rec.Amount),
+			formatCSVAmount(rec.Fee),
+			formatCSVAmount(rec.Locked),
+			price,
+			fiatAmount,
+			fiatFee,
+			rec.Description,
+		})
+	}
+	return rows
+}
+
+// koinlyRows writes the Koinly universal import format. Records where only a
+// fee left the wallet become "cost" rows, records without any movement are skipped.
+func koinlyRows(records []types.TaxRecord, currency string) [][]string {
+	rows := [][]string{{
+		"Date", "Sent Amount", "Sent Currency", "Received Amount", "Received Currency",
+		"Fee Amount", "Fee Currency", "Net Worth Amount", "Net Worth Currency", "Label", "Description", "TxHash",
+	}}
+	for _, rec := range records {
+		var sent, received, fee, label string
+		netWorth := rec.FiatAmount
+		switch {
+		case rec.Amount > 0:
+			received = formatCSVAmount(rec.Amount)
+			switch rec.Kind {
+			case services.TaxKindStakingReward:
+				label = "staking"
+			case services.TaxKindMining:
+				label = "mining"
+			}
+		case rec.Amount < 0:
+			sent = formatCSVAmount(-rec.Amount)
+			if rec.Fee < 0 {
+				fee = formatCSVAmount(-rec.Fee)
+			}
+		case rec.Fee < 0:
+			sent = formatCSVAmount(-rec.Fee)
+			label = "cost"
+			netWorth = rec.FiatFee
+		default:
+			continue
+		}
+
+		worth, worthCurrency := "", ""
+		if rec.HasPrice {
+			worth = formatFiat(abs(netWorth))
+			worthCurrency = currency
+		}
+		rows = append(rows, []string{
+			rec.Time.Format("2006-01-02 15:04 UTC"),
+			sent, currencyIf(sent),
+			received, currencyIf(received),
+			fee, currencyIf(fee),
+			worth, worthCurrency,
+			label,
+			rec.Description,
+			rec.TxID,
+		})
+	}
+	return rows
+}
+
+// coinTrackingRows writes the CoinTracking CSV import format. CoinTracking
+// values transactions itself, so no fiat columns are written.
+func coinTrackingRows(records []types.TaxRecord) [][]string {
+	rows := [][]string{{
+		"Type", "Buy Amount", "Buy Currency", "Sell Amount", "Sell Currency",
+		"Fee", "Fee Currency", "Exchange", "Trade-Group", "Comment", "Date", "Tx-ID",
+	}}
+	for _, rec := range records {
+		var kind, buy, sell, fee string
+		switch {
+		case rec.Amount > 0:
+			buy = formatCSVAmount(rec.Amount)
+			switch rec.Kind {
+			case services.TaxKindStakingReward:
+				kind = "Staking"
+			case services.TaxKindMining:
+				kind = "Mining"
+			default:
+				kind = "Deposit"
+			}
+		case rec.Amount < 0:
+			kind = "Withdrawal"
+			sell = formatCSVAmount(-rec.Amount)
+			if rec.Fee < 0 {
+				fee = formatCSVAmount(-rec.Fee)
+			}
+		case rec.Fee < 0:
+			kind = "Other Fee"
+			sell = formatCSVAmount(-rec.Fee)
+		default:
+			continue
+		}
+
+		rows = append(rows, []string{
+			kind,
+			buy, currencyIf(buy),
+			sell, currencyIf(sell),
+			fee, currencyIf(fee),
+			"Decred Wallet",
+			rec.Kind,
+			rec.Description,
+			rec.Time.Format("2006-01-02 15:04:05"),
+			rec.TxID,
+		})
+	}
+	return rows
+}
+
+// currencyIf returns the DCR currency code for non-empty amount columns
+func currencyIf(amount string) string {
+	if amount == "" {
+		return ""
+	}
+	return "DCR"
+}
+
+func formatFiat(amount float64) string {
+	return fmt.Sprintf("%.2f", amount)
+}
+
+func abs(v float64) float64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,219 @@
+// Copyright (c) 2015-2025 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package handlers
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"decred-pulse-backend/rpc"
+	"decred-pulse-backend/services"
+	"decred-pulse-backend/types"
+)
+
+// PreviewTicketPurchaseHandler prices a ticket purchase without buying anything
+func PreviewTicketPurchaseHandler(w http.ResponseWriter, r *http.Request) {
+	if rpc.WalletClient == nil || rpc.WalletGrpcClient == nil {
+		http.Error(w, "Wallet RPC client not initialized", http.StatusServiceUnavailable)
+		return
+	}
+
+	var req types.TicketPurchaseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	preview, err := services.PreviewTicketPurchase(ctx, req)
+	if err != nil {
+		log.Printf("Error previewing ticket purchase: %v", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(preview)
+}
+
+// PurchaseTicketsHandler buys tickets through a VSP
+func PurchaseTicketsHandler(w http.ResponseWriter, r *http.Request) {
+	if rpc.WalletClient == nil || rpc.WalletGrpcClient == nil {
+		http.Error(w, "Wallet RPC client not initialized", http.StatusServiceUnavailable)
+		return
+	}
+
+	var req types.TicketPurchaseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	// Purchases include split transaction creation and VSP fee negotiation
+	ctx, cancel := context.WithTimeout(r.Context(), 2*time.Minute)
+	defer cancel()
+
+	result, err := services.PurchaseTickets(ctx, req)
+	switch {
+	case errors.Is(err, services.ErrIncorrectPassphrase):
+		http.Error(w, "Incorrect passphrase", http.StatusUnauthorized)
+		return
+	case errors.Is(err, services.ErrWalletLocked):
+		http.Error(w, err.Error(), http.StatusLocked)
+		return
+	case errors.Is(err, services.ErrTicketPriceExceeded):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case err != nil:
+		log.Printf("Error purchasing tickets: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// ListWalletTicketsHandler lists the wallet's tickets with lifecycle status and VSP state.
+// Query parameters: status (comma-separated), account, vsp, page, pageSize (max 100).
+func ListWalletTicketsHandler(w http.ResponseWriter, r *http.Request) {
+	if rpc.WalletGrpcClient == nil {
+		http.Error(w, "Wallet gRPC client not initialized", http.StatusServiceUnavailable)
+		return
+	}
+
+	query := r.URL.Query()
+	filter := services.TicketFilter{
+		Account:  query.Get("account"),
+		VSPHost:  query.Get("vsp"),
+		Page:     1,
+		PageSize: 25,
+	}
+	if s := query.Get("status"); s != "" {
+		filter.Statuses = strings.Split(s, ",")
+	}
+	if p, err := strconv.Atoi(query.Get("page")); err == nil && p > 0 {
+		filter.Page = p
+	}
+	if ps, err := strconv.Atoi(query.Get("pageSize")); err == nil && ps > 0 {
+		filter.PageSize = ps
+		if filter.PageSize > 100 {
+			filter.PageSize = 100
+		}
+	}
+
+	// Walking all tickets can take a while on wallets with a long staking history
+	ctx, cancel := context.WithTimeout(r.Context(), 60*time.Second)
+	defer cancel()
+
+	response, err := services.ListWalletTickets(ctx, filter)
+	if err != nil {
+		log.Printf("Error listing tickets: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// GetStakeReturnsHandler returns realized staking rewards per ticket, month and
+// account with realized and network APY. Use format=csv with view=tickets
+// (default), months or accounts for a CSV download.
+func GetStakeReturnsHandler(w http.ResponseWriter, r *http.Request) {
+	if rpc.WalletClient == nil || rpc.WalletGrpcClient == nil {
+		http.Error(w, "Wallet RPC client not initialized", http.StatusServiceUnavailable)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 60*time.Second)
+	defer cancel()
+
+	returns, err := services.FetchStakeReturns(ctx)
+	if err != nil {
+		log.Printf("Error fetching stake returns: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	query := r.URL.Query()
+	if query.Get("format") != "csv" {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(returns)
+		return
+	}
+
+	var rows [][]string
+	view := query.Get("view")
+	switch view {
+	case "months", "accounts":
+		periods := returns.ByMonth
+		if view == "accounts" {
+			periods = returns.ByAccount
+		}
+		rows = append(rows, []string{"key", "tickets", "voted", "revoked", "total_invested", "total_net_reward", "avg_days_locked", "realized_apy"})
+		for _, p := range periods {
+			rows = append(rows, []string{
+				p.Key,
+				strconv.Itoa(p.Tickets),
+				strconv.Itoa(p.Voted),
+				strconv.Itoa(p.Revoked),
+				formatCSVAmount(p.TotalInvested),
+				formatCSVAmount(p.TotalNetReward),
+				strconv.FormatFloat(p.AvgDaysLocked, 'f', 2, 64),
+				strconv.FormatFloat(p.RealizedAPY, 'f', 4, 64),
+			})
+		}
+	case "", "tickets":
+		view = "tickets"
+		rows = append(rows, []string{"hash", "account", "status", "purchase_time", "spender_time", "price", "fee", "returned", "net_reward", "days_locked", "annualized_return"})
+		for _, t := range returns.Tickets {
+			rows = append(rows, []string{
+				t.Hash,
+				t.Account,
+				t.Status,
+				time.Unix(t.PurchaseTime, 0).UTC().Format(time.RFC3339),
+				time.Unix(t.SpenderTime, 0).UTC().Format(time.RFC3339),
+				formatCSVAmount(t.Price),
+				formatCSVAmount(t.Fee),
+				formatCSVAmount(t.Returned),
+				formatCSVAmount(t.NetReward),
+				strconv.FormatFloat(t.DaysLocked, 'f', 2, 64),
+				strconv.FormatFloat(t.AnnualizedReturn, 'f', 4, 64),
+			})
+		}
+	default:
+		http.Error(w, "Invalid view, expected tickets, months or accounts", http.StatusBadRequest)
+		return
+	}
+
+	writeCSV(w, fmt.Sprintf("stake-returns-%s.csv", view), rows)
+}
+
+// writeCSV sends rows as a CSV file download
+func writeCSV(w http.ResponseWriter, filename string, rows [][]string) {
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+
+	writer := csv.NewWriter(w)
+	if err := writer.WriteAll(rows); err != nil {
+		log.Printf("Failed to write CSV %s: %v", filename, err)
+	}
+}
+
+// formatCSVAmount formats a DCR amount with full atom precision
+func formatCSVAmount(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', 8, 64)
+}
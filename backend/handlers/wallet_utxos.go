@@ -0,0 +1,204 @@
+// Copyright (c) 2015-2025 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"decred-pulse-backend/rpc"
+	"decred-pulse-backend/services"
+	"decred-pulse-backend/types"
+)
+
+// ListUTXOsHandler returns a page of the wallet's unspent outputs
+func ListUTXOsHandler(w http.ResponseWriter, r *http.Request) {
+	if rpc.WalletClient == nil {
+		http.Error(w, "Wallet RPC client not initialized", http.StatusServiceUnavailable)
+		return
+	}
+
+	query := r.URL.Query()
+	filter := services.UTXOFilter{
+		Account:  query.Get("account"),
+		Page:     1,
+		PageSize: 100,
+	}
+	if s := query.Get("minConf"); s != "" {
+		minConf, err := strconv.ParseInt(s, 10, 64)
+		if err != nil || minConf < 0 {
+			http.Error(w, "minConf must be a non-negative integer", http.StatusBadRequest)
+			return
+		}
+		filter.MinConf = minConf
+	}
+	if s := query.Get("mixed"); s != "" {
+		mixed, err := strconv.ParseBool(s)
+		if err != nil {
+			http.Error(w, "mixed must be true or false", http.StatusBadRequest)
+			return
+		}
+		filter.Mixed = &mixed
+	}
+	if s := query.Get("locked"); s != "" {
+		locked, err := strconv.ParseBool(s)
+		if err != nil {
+			http.Error(w, "locked must be true or false", http.StatusBadRequest)
+			return
+		}
+		filter.Locked = &locked
+	}
+	if s := query.Get("dust"); s != "" {
+		dust, err := strconv.ParseBool(s)
+		if err != nil {
+			http.Error(w, "dust must be true or false", http.StatusBadRequest)
+			return
+		}
+		filter.DustOnly = dust
+	}
+	if p, err := strconv.Atoi(query.Get("page")); err == nil && p > 0 {
+		filter.Page = p
+	}
+	if ps, err := strconv.Atoi(query.Get("pageSize")); err == nil && ps > 0 {
+		filter.PageSize = ps
+		if filter.PageSize > 500 {
+			filter.PageSize = 500
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 60*time.Second)
+	defer cancel()
+
+	response, err := services.ListUTXOs(ctx, filter)
+	if err != nil {
+		writeAccountError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// LockUTXOsHandler excludes outputs from automatic coin selection
+func LockUTXOsHandler(w http.ResponseWriter, r *http.Request) {
+	lockUTXOs(w, r, false)
+}
+
+// UnlockUTXOsHandler returns locked outputs to automatic coin selection
+func UnlockUTXOsHandler(w http.ResponseWriter, r *http.Request) {
+	lockUTXOs(w, r, true)
+}
+
+func lockUTXOs(w http.ResponseWriter, r *http.Request, unlock bool) {
+	if rpc.WalletClient == nil {
+		http.Error(w, "Wallet RPC client not initialized", http.StatusServiceUnavailable)
+		return
+	}
+
+	var req types.UTXOLockRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	err := services.LockUTXOs(ctx, req.Outputs, unlock)
+	switch {
+	case errors.Is(err, services.ErrInvalidOutpoint):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case errors.Is(err, services.ErrOutputReserved):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case err != nil:
+		log.Printf("Error updating output locks: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"count":   len(req.Outputs),
+	})
+}
+
+// PlanConsolidationHandler proposes consolidation transactions for an
+// account's small outputs without spending anything
+func PlanConsolidationHandler(w http.ResponseWriter, r *http.Request) {
+	if rpc.WalletClient == nil {
+		http.Error(w, "Wallet RPC client not initialized", http.StatusServiceUnavailable)
+		return
+	}
+
+	var req types.ConsolidationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 60*time.Second)
+	defer cancel()
+
+	plan, err := services.PlanConsolidation(ctx, req)
+	if errors.Is(err, services.ErrInvalidConsolidation) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		writeAccountError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(plan)
+}
+
+// StartConsolidationHandler signs and publishes a consolidation as a job
+func StartConsolidationHandler(w http.ResponseWriter, r *http.Request) {
+	if rpc.WalletClient == nil || rpc.WalletGrpcClient == nil {
+		http.Error(w, "Wallet RPC client not initialized", http.StatusServiceUnavailable)
+		return
+	}
+
+	var req types.ConsolidationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 60*time.Second)
+	defer cancel()
+
+	plan, job, err := services.StartConsolidation(ctx, req)
+	switch {
+	case errors.Is(err, services.ErrInvalidConsolidation), errors.Is(err, services.ErrNothingToConsolidate):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case errors.Is(err, services.ErrConsolidationRunning):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case err != nil:
+		writeAccountError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(types.ConsolidationResponse{
+		Success: true,
+		Message: fmt.Sprintf("Consolidating %d outputs in %d transactions", plan.InputCount, len(plan.Batches)),
+		JobID:   job.ID,
+		Plan:    *plan,
+	})
+}
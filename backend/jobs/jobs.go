@@ -0,0 +1,481 @@
+// Copyright (c) 2015-2025 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+// Package jobs tracks long-running background operations such as xpub
+// imports, wallet rescans and historical chain scans. Every job gets an ID,
+// a list of named steps, progress, a bounded log, and a final result or
+// error. Job state is persisted to disk so a restart does not lose track of
+// what was running.
+package jobs
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"decred-pulse-backend/types"
+	"decred-pulse-backend/utils"
+)
+
+const (
+	maxLogEntries   = 200             // Per-job log lines kept (oldest dropped first)
+	maxFinishedJobs = 100             // Finished jobs kept in history
+	persistInterval = 2 * time.Second // Minimum delay between progress-only writes
+)
+
+var (
+	// ErrJobNotFound is returned when a job ID is unknown
+	ErrJobNotFound = errors.New("job not found")
+
+	// ErrJobFinished is returned when cancelling a job that already finished
+	ErrJobFinished = errors.New("job already finished")
+)
+
+// RunFunc is the body of a job. The context is cancelled when the job is
+// cancelled; the returned value is stored as the job result.
+type RunFunc func(ctx context.Context, job *Handle) (interface{}, error)
+
+type jobEntry struct {
+	job    types.Job
+	cancel context.CancelFunc
+}
+
+var (
+	jobsMutex   sync.RWMutex
+	jobsByID    = make(map[string]*jobEntry)
+	storePath   string
+	lastPersist time.Time
+	persistSeq  uint64
+
+	// Serializes file writes; writtenSeq prevents an older snapshot from
+	// overwriting a newer one
+	persistMutex sync.Mutex
+	writtenSeq   uint64
+
+	subscribersMutex sync.Mutex
+	subscribers      []chan types.JobEvent
+)
+
+// Init loads persisted jobs from path and enables persistence.
+// Jobs that were queued or running when the backend stopped are marked as
+// interrupted since their goroutines no longer exist.
+func Init(path string) error {
+	storePath = path
+
+	var stored []types.Job
+	found, err := utils.ReadJSONFile(path, &stored)
+	if err != nil {
+		return err
+	}
+	if !found {
+		log.Printf("No job history found at %s, starting fresh", path)
+		return nil
+	}
+
+	now := time.Now()
+	interrupted := 0
+
+	jobsMutex.Lock()
+	for _, job := range stored {
+		if !isFinished(job.Status) {
+			job.Status = types.JobStatusInterrupted
+			job.Error = "backend restarted while job was running"
+			job.FinishedAt = &now
+			if job.CurrentStep >= 0 && job.CurrentStep < len(job.Steps) {
+				job.Steps[job.CurrentStep].Status = types.JobStepFailed
+				job.Steps[job.CurrentStep].FinishedAt = &now
+			}
+			interrupted++
+		}
+		jobsByID[job.ID] = &jobEntry{job: job}
+	}
+	jobsMutex.Unlock()
+
+	log.Printf("Loaded %d jobs from %s (%d marked interrupted)", len(stored), path, interrupted)
+	if interrupted > 0 {
+		persist(true)
+	}
+	return nil
+}
+
+// Start creates a new job and runs fn in a background goroutine.
+// It returns a snapshot of the job as created.
+func Start(jobType string, steps []string, fn RunFunc) types.Job {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	job := types.Job{
+		ID:          newJobID(),
+		Type:        jobType,
+		Status:      types.JobStatusQueued,
+		Steps:       make([]types.JobStep, len(steps)),
+		CurrentStep: -1,
+		Logs:        []types.JobLogEntry{},
+		CreatedAt:   time.Now(),
+	}
+	for i, name := range steps {
+		job.Steps[i] = types.JobStep{Name: name, Status: types.JobStepPending}
+	}
+
+	jobsMutex.Lock()
+	jobsByID[job.ID] = &jobEntry{job: job, cancel: cancel}
+	pruneLocked()
+	snapshot := cloneJob(job)
+	jobsMutex.Unlock()
+
+	log.Printf("Job %s (%s) created", job.ID, jobType)
+	broadcast("created", snapshot)
+	persist(true)
+
+	handle := &Handle{id: job.ID}
+	go run(ctx, cancel, handle, fn)
+
+	return snapshot
+}
+
+// run executes the job body and records its outcome
+func run(ctx context.Context, cancel context.CancelFunc, handle *Handle, fn RunFunc) {
+	defer cancel()
+
+	handle.update(true, func(job *types.Job) {
+		now := time.Now()
+		job.Status = types.JobStatusRunning
+		job.StartedAt = &now
+	})
+
+	result, err := fn(ctx, handle)
+
+	var resultJSON json.RawMessage
+	if result != nil {
+		data, marshalErr := json.Marshal(result)
+		if marshalErr != nil {
+			log.Printf("Job %s: failed to marshal result: %v", handle.id, marshalErr)
+		} else {
+			resultJSON = data
+		}
+	}
+
+	snapshot := handle.update(true, func(job *types.Job) {
+		now := time.Now()
+		job.FinishedAt = &now
+		job.Result = resultJSON
+
+		stepStatus := types.JobStepCompleted
+		switch {
+		case err != nil && ctx.Err() != nil:
+			job.Status = types.JobStatusCancelled
+			job.Error = "cancelled"
+			stepStatus = types.JobStepFailed
+		case err != nil:
+			job.Status = types.JobStatusFailed
+			job.Error = err.Error()
+			stepStatus = types.JobStepFailed
+		default:
+			job.Status = types.JobStatusSucceeded
+			job.Progress = 100
+		}
+
+		if job.CurrentStep >= 0 && job.CurrentStep < len(job.Steps) {
+			step := &job.Steps[job.CurrentStep]
+			if step.Status == types.JobStepRunning {
+				step.Status = stepStatus
+				step.FinishedAt = &now
+			}
+		}
+
+		// Steps that never started are skipped on failure or cancellation
+		for i := range job.Steps {
+			if job.Steps[i].Status == types.JobStepPending && job.Status != types.JobStatusSucceeded {
+				job.Steps[i].Status = types.JobStepSkipped
+			}
+		}
+	})
+
+	log.Printf("Job %s (%s) finished with status %s", snapshot.ID, snapshot.Type, snapshot.Status)
+	broadcast("finished", snapshot)
+}
+
+// Get returns a snapshot of a single job
+func Get(id string) (types.Job, error) {
+	jobsMutex.RLock()
+	defer jobsMutex.RUnlock()
+
+	entry, ok := jobsByID[id]
+	if !ok {
+		return types.Job{}, ErrJobNotFound
+	}
+	return cloneJob(entry.job), nil
+}
+
+// List returns job snapshots, newest first.
+// Empty jobType or status values match all jobs.
+func List(jobType, status string) []types.Job {
+	jobsMutex.RLock()
+	defer jobsMutex.RUnlock()
+
+	jobs := make([]types.Job, 0, len(jobsByID))
+	for _, entry := range jobsByID {
+		if jobType != "" && entry.job.Type != jobType {
+			continue
+		}
+		if status != "" && entry.job.Status != status {
+			continue
+		}
+		jobs = append(jobs, cloneJob(entry.job))
+	}
+
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].CreatedAt.After(jobs[j].CreatedAt)
+	})
+	return jobs
+}
+
+// Active returns the first queued or running job of the given type, if any
+func Active(jobType string) (types.Job, bool) {
+	jobsMutex.RLock()
+	defer jobsMutex.RUnlock()
+
+	for _, entry := range jobsByID {
+		if entry.job.Type == jobType && !isFinished(entry.job.Status) {
+			return cloneJob(entry.job), true
+		}
+	}
+	return types.Job{}, false
+}
+
+// Cancel requests cancellation of a running job. The job is marked as
+// cancelled once its body observes the cancelled context and returns.
+func Cancel(id string) error {
+	jobsMutex.RLock()
+	entry, ok := jobsByID[id]
+	if !ok {
+		jobsMutex.RUnlock()
+		return ErrJobNotFound
+	}
+	finished := isFinished(entry.job.Status)
+	cancel := entry.cancel
+	jobsMutex.RUnlock()
+
+	if finished || cancel == nil {
+		return ErrJobFinished
+	}
+
+	log.Printf("Job %s: cancellation requested", id)
+	cancel()
+	return nil
+}
+
+// Subscribe returns a channel that receives an event for every job change
+func Subscribe() chan types.JobEvent {
+	ch := make(chan types.JobEvent, 32)
+	subscribersMutex.Lock()
+	subscribers = append(subscribers, ch)
+	subscribersMutex.Unlock()
+	return ch
+}
+
+// Unsubscribe stops delivery of job events to ch
+func Unsubscribe(ch chan types.JobEvent) {
+	subscribersMutex.Lock()
+	defer subscribersMutex.Unlock()
+
+	for i, c := range subscribers {
+		if c == ch {
+			subscribers = append(subscribers[:i], subscribers[i+1:]...)
+			break
+		}
+	}
+}
+
+// Handle is passed to a running job to report steps, progress and logs
+type Handle struct {
+	id string
+}
+
+// ID returns the job ID
+func (h *Handle) ID() string {
+	return h.id
+}
+
+// BeginStep marks the previous running step completed and starts step index
+func (h *Handle) BeginStep(index int) {
+	h.update(true, func(job *types.Job) {
+		if index < 0 || index >= len(job.Steps) {
+			return
+		}
+		now := time.Now()
+		if job.CurrentStep >= 0 && job.CurrentStep < len(job.Steps) && job.Steps[job.CurrentStep].Status == types.JobStepRunning {
+			job.Steps[job.CurrentStep].Status = types.JobStepCompleted
+			job.Steps[job.CurrentStep].FinishedAt = &now
+		}
+		job.CurrentStep = index
+		job.Progress = 0
+		job.Message = job.Steps[index].Name
+		job.Steps[index].Status = types.JobStepRunning
+		job.Steps[index].StartedAt = &now
+	})
+}
+
+// SkipStep marks a pending step as skipped
+func (h *Handle) SkipStep(index int) {
+	h.update(true, func(job *types.Job) {
+		if index >= 0 && index < len(job.Steps) && job.Steps[index].Status == types.JobStepPending {
+			job.Steps[index].Status = types.JobStepSkipped
+		}
+	})
+}
+
+// SetProgress updates the progress (0-100) and status message of the current step
+func (h *Handle) SetProgress(progress float64, message string) {
+	if progress < 0 {
+		progress = 0
+	} else if progress > 100 {
+		progress = 100
+	}
+	h.update(false, func(job *types.Job) {
+		job.Progress = progress
+		if message != "" {
+			job.Message = message
+		}
+	})
+}
+
+// Logf appends a line to the job log and mirrors it to the server log
+func (h *Handle) Logf(format string, args ...interface{}) {
+	message := fmt.Sprintf(format, args...)
+	log.Printf("Job %s: %s", h.id, message)
+
+	h.update(false, func(job *types.Job) {
+		job.Logs = append(job.Logs, types.JobLogEntry{Time: time.Now(), Message: message})
+		if len(job.Logs) > maxLogEntries {
+			job.Logs = job.Logs[len(job.Logs)-maxLogEntries:]
+		}
+	})
+}
+
+// update applies fn to the job, broadcasts the change and persists it.
+// Progress-only updates (force=false) are persisted at most every persistInterval.
+func (h *Handle) update(force bool, fn func(job *types.Job)) types.Job {
+	jobsMutex.Lock()
+	entry, ok := jobsByID[h.id]
+	if !ok {
+		jobsMutex.Unlock()
+		return types.Job{}
+	}
+	fn(&entry.job)
+	snapshot := cloneJob(entry.job)
+	jobsMutex.Unlock()
+
+	if snapshot.Status != types.JobStatusQueued && !isFinished(snapshot.Status) {
+		broadcast("updated", snapshot)
+	}
+	persist(force)
+	return snapshot
+}
+
+// persist writes all jobs to disk
+func persist(force bool) {
+	if storePath == "" {
+		return
+	}
+
+	jobsMutex.Lock()
+	if !force && time.Since(lastPersist) < persistInterval {
+		jobsMutex.Unlock()
+		return
+	}
+	lastPersist = time.Now()
+	persistSeq++
+	seq := persistSeq
+	all := make([]types.Job, 0, len(jobsByID))
+	for _, entry := range jobsByID {
+		all = append(all, cloneJob(entry.job))
+	}
+	jobsMutex.Unlock()
+
+	sort.Slice(all, func(i, j int) bool {
+		return all[i].CreatedAt.Before(all[j].CreatedAt)
+	})
+
+	persistMutex.Lock()
+	defer persistMutex.Unlock()
+	if seq < writtenSeq {
+		return
+	}
+	if err := utils.WriteJSONFile(storePath, all); err != nil {
+		log.Printf("Warning: Failed to persist jobs: %v", err)
+		return
+	}
+	writtenSeq = seq
+}
+
+// pruneLocked drops the oldest finished jobs beyond maxFinishedJobs.
+// Caller must hold jobsMutex.
+func pruneLocked() {
+	finished := make([]*jobEntry, 0)
+	for _, entry := range jobsByID {
+		if isFinished(entry.job.Status) {
+			finished = append(finished, entry)
+		}
+	}
+	if len(finished) <= maxFinishedJobs {
+		return
+	}
+
+	sort.Slice(finished, func(i, j int) bool {
+		return finished[i].job.CreatedAt.Before(finished[j].job.CreatedAt)
+	})
+	for _, entry := range finished[:len(finished)-maxFinishedJobs] {
+		delete(jobsByID, entry.job.ID)
+	}
+}
+
+// broadcast sends a job event to all subscribers without blocking
+func broadcast(event string, job types.Job) {
+	subscribersMutex.Lock()
+	defer subscribersMutex.Unlock()
+
+	for _, ch := range subscribers {
+		select {
+		case ch <- types.JobEvent{Event: event, Job: job}:
+		default:
+			// Channel full, skip
+		}
+	}
+}
+
+func isFinished(status string) bool {
+	switch status {
+	case types.JobStatusSucceeded, types.JobStatusFailed, types.JobStatusCancelled, types.JobStatusInterrupted:
+		return true
+	}
+	return false
+}
+
+// cloneJob deep-copies the slices of a job so snapshots can be shared safely
+func cloneJob(job types.Job) types.Job {
+	clone := job
+	clone.Steps = make([]types.JobStep, len(job.Steps))
+	copy(clone.Steps, job.Steps)
+	clone.Logs = make([]types.JobLogEntry, len(job.Logs))
+	copy(clone.Logs, job.Logs)
+	if job.Result != nil {
+		clone.Result = append(json.RawMessage(nil), job.Result...)
+	}
+	return clone
+}
+
+func newJobID() string {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(buf)
+}
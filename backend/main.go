@@ -9,15 +9,44 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/rs/cors"
 
 	"decred-pulse-backend/handlers"
+	"decred-pulse-backend/jobs"
+	"decred-pulse-backend/pricing"
 	"decred-pulse-backend/rpc"
+	"decred-pulse-backend/services"
 )
 
 func main() {
+	// Directory for persisted backend state (job history, caches)
+	dataDir := getEnv("DATA_DIR", "./data")
+
+	if err := jobs.Init(filepath.Join(dataDir, "jobs.json")); err != nil {
+		log.Printf("Warning: Could not load job history: %v", err)
+	}
+
+	if err := services.InitCoinJoinCache(filepath.Join(dataDir, "coinjoin-cache.json")); err != nil {
+		log.Printf("Warning: Could not load CoinJoin cache: %v", err)
+	}
+	if err := services.InitMixStats(filepath.Join(dataDir, "mix-stats.json")); err != nil {
+		log.Printf("Warning: Could not load mix statistics: %v", err)
+	}
+	services.StartMixStatsUpdater()
+	if err := services.InitInvoices(filepath.Join(dataDir, "invoices.json")); err != nil {
+		log.Printf("Warning: Could not load invoices: %v", err)
+	}
+	services.StartInvoiceWatcher()
+	if err := services.InitOfflineTransactions(filepath.Join(dataDir, "offline-transactions.json")); err != nil {
+		log.Printf("Warning: Could not load offline transactions: %v", err)
+	}
+
 	// Load dcrd configuration from environment variables
 	dcrdConfig := rpc.Config{
 		RPCHost:     getEnv("DCRD_RPC_HOST", "localhost"),
@@ -55,6 +84,8 @@ func main() {
 	} else {
 		log.Println("No dcrwallet RPC credentials provided. Wallet features disabled.")
 	}
+	// Re-lock offline transaction inputs lost to a dcrwallet restart
+	services.StartOfflineTxWatcher()
 
 	// Initialize wallet gRPC client for streaming
 	grpcConfig := rpc.GrpcConfig{
@@ -67,11 +98,65 @@ func main() {
 		if err := rpc.InitWalletGrpcClient(grpcConfig); err != nil {
 			log.Printf("Warning: Could not connect to dcrwallet gRPC on startup: %v", err)
 			log.Println("Streaming features will be unavailable")
+		} else {
+			services.StartWalletNotifications()
+			services.TriggerCoinJoinPrewarm()
 		}
 	} else {
 		log.Println("No gRPC certificate provided. Streaming features disabled.")
 	}
 
+	// Webhook endpoints receiving wallet notification events
+	services.InitWebhooks(services.WebhookConfig{
+		URLs:   getEnvList("WEBHOOK_URLS"),
+		Secret: getEnv("WEBHOOK_SECRET", ""),
+		Events: getEnvList("WEBHOOK_EVENTS"),
+	})
+
+	// Default accounts for the account mixer
+	services.InitMixer(services.MixerConfig{
+		MixedAccount:       getEnv("MIXER_MIXED_ACCOUNT", ""),
+		MixedAccountBranch: uint32(getEnvInt("MIXER_MIXED_BRANCH", 0)),
+		ChangeAccount:      getEnv("MIXER_CHANGE_ACCOUNT", ""),
+		CSPPServer:         getEnv("MIXER_CSPP_SERVER", ""),
+	})
+
+	// How wallets created or opened through the wallet loader sync
+	services.InitWalletLoader(services.WalletLoaderConfig{
+		SyncMode:     getEnv("WALLET_SYNC_MODE", "rpc"),
+		DcrdAddress:  getEnv("WALLET_SYNC_DCRD_ADDRESS", dcrdConfig.RPCHost+":"+dcrdConfig.RPCPort),
+		DcrdUser:     dcrdConfig.RPCUser,
+		DcrdPassword: dcrdConfig.RPCPassword,
+		DcrdCertPath: dcrdConfig.RPCCert,
+		SpvConnect:   getEnvList("SPV_CONNECT"),
+	})
+	services.ResumeWalletSync()
+
+	// Exchange rates for fiat equivalents, from a JSON file or endpoint
+	pricingConfig := pricing.Config{
+		Currency:        getEnv("PRICE_CURRENCY", "USD"),
+		RefreshInterval: getEnvDuration("PRICE_REFRESH_INTERVAL", 5*time.Minute),
+		MaxAge:          getEnvDuration("PRICE_MAX_AGE", 30*time.Minute),
+	}
+	if source := getEnv("PRICE_SOURCE", ""); source != "" {
+		pricingConfig.Provider = pricing.NewJSONProvider(source, getEnv("PRICE_FIELD", ""))
+	}
+	if path := getEnv("PRICE_HISTORY_FILE", ""); path != "" {
+		history, err := pricing.LoadPriceHistory(path)
+		if err != nil {
+			log.Printf("Warning: Could not load price history: %v", err)
+		} else {
+			pricingConfig.History = history
+		}
+	}
+	pricing.Init(pricingConfig)
+
+	// API tokens for privileged endpoints (wallet lock management)
+	handlers.InitAuth(handlers.AuthConfig{
+		AdminToken:    getEnv("API_ADMIN_TOKEN", ""),
+		OperatorToken: getEnv("API_OPERATOR_TOKEN", ""),
+	})
+
 	// Setup router
 	r := mux.NewRouter()
 
@@ -90,13 +175,83 @@ func main() {
 	api.HandleFunc("/wallet/status", handlers.GetWalletStatusHandler).Methods("GET")
 	api.HandleFunc("/wallet/dashboard", handlers.GetWalletDashboardHandler).Methods("GET")
 	api.HandleFunc("/wallet/transactions", handlers.ListTransactionsHandler).Methods("GET")
+	api.HandleFunc("/wallet/history", handlers.GetWalletHistoryHandler).Methods("GET")
+	api.HandleFunc("/wallet/tax-export", handlers.TaxExportHandler).Methods("POST")
 	api.HandleFunc("/wallet/importxpub", handlers.ImportXpubHandler).Methods("POST")
 	api.HandleFunc("/wallet/rescan", handlers.RescanWalletHandler).Methods("POST")
+	api.HandleFunc("/wallet/rescan/status", handlers.GetRescanStatusHandler).Methods("GET")
+	api.HandleFunc("/wallet/rescan/cancel", handlers.RequireRole(handlers.RoleOperator, handlers.CancelRescanHandler)).Methods("POST")
 	api.HandleFunc("/wallet/sync-progress", handlers.GetSyncProgressHandler).Methods("GET")
+	api.HandleFunc("/wallet/network", handlers.GetWalletNetworkHandler).Methods("GET")
+	api.HandleFunc("/wallet/peers", handlers.ListWalletPeersHandler).Methods("GET")
+
+	// Wallet onboarding: create, restore, open and sync (requires an admin token)
+	api.HandleFunc("/wallet/loader", handlers.GetWalletLoaderHandler).Methods("GET")
+	api.HandleFunc("/wallet/create", handlers.RequireRole(handlers.RoleAdmin, handlers.CreateWalletHandler)).Methods("POST")
+	api.HandleFunc("/wallet/restore", handlers.RequireRole(handlers.RoleAdmin, handlers.RestoreWalletHandler)).Methods("POST")
+	api.HandleFunc("/wallet/watch-only", handlers.RequireRole(handlers.RoleAdmin, handlers.CreateWatchOnlyWalletHandler)).Methods("POST")
+	api.HandleFunc("/wallet/open", handlers.RequireRole(handlers.RoleAdmin, handlers.OpenWalletHandler)).Methods("POST")
+	api.HandleFunc("/wallet/sync", handlers.RequireRole(handlers.RoleAdmin, handlers.StartWalletSyncHandler)).Methods("POST")
+
+	// Wallet lock management (requires an API token)
+	api.HandleFunc("/wallet/lock-status", handlers.GetWalletLockStatusHandler).Methods("GET")
+	api.HandleFunc("/wallet/unlock", handlers.RequireRole(handlers.RoleOperator, handlers.UnlockWalletHandler)).Methods("POST")
+	api.HandleFunc("/wallet/lock", handlers.RequireRole(handlers.RoleOperator, handlers.LockWalletHandler)).Methods("POST")
+	api.HandleFunc("/wallet/accounts/{account}/unlock", handlers.RequireRole(handlers.RoleOperator, handlers.UnlockAccountHandler)).Methods("POST")
+	api.HandleFunc("/wallet/accounts/{account}/lock", handlers.RequireRole(handlers.RoleOperator, handlers.LockAccountHandler)).Methods("POST")
+
+	// Account management (requires an API token, except address indexes)
+	api.HandleFunc("/wallet/accounts", handlers.RequireRole(handlers.RoleOperator, handlers.CreateAccountHandler)).Methods("POST")
+	api.HandleFunc("/wallet/accounts/{account}/rename", handlers.RequireRole(handlers.RoleOperator, handlers.RenameAccountHandler)).Methods("POST")
+	api.HandleFunc("/wallet/accounts/{account}/xpub", handlers.RequireRole(handlers.RoleOperator, handlers.GetAccountXpubHandler)).Methods("GET")
+	api.HandleFunc("/wallet/accounts/{account}/address-index", handlers.GetAccountAddressIndexHandler).Methods("GET")
+
+	// Receive addresses: listing, and deriving new ones (requires an API token)
+	api.HandleFunc("/wallet/addresses", handlers.ListAddressesHandler).Methods("GET")
+	api.HandleFunc("/wallet/addresses", handlers.RequireRole(handlers.RoleOperator, handlers.NewAddressHandler)).Methods("POST")
+
+	// Invoices: payment requests tracked until settled (creation requires an API token)
+	api.HandleFunc("/invoices", handlers.ListInvoicesHandler).Methods("GET")
+	api.HandleFunc("/invoices", handlers.RequireRole(handlers.RoleOperator, handlers.CreateInvoiceHandler)).Methods("POST")
+	api.HandleFunc("/invoices/{id}", handlers.GetInvoiceHandler).Methods("GET")
+
+	// Sending funds: prepare a preview, then confirm it (requires an admin token)
+	api.HandleFunc("/wallet/send/prepare", handlers.RequireRole(handlers.RoleAdmin, handlers.PrepareSendHandler)).Methods("POST")
+	api.HandleFunc("/wallet/send/confirm", handlers.RequireRole(handlers.RoleAdmin, handlers.ConfirmSendHandler)).Methods("POST")
+	api.HandleFunc("/wallet/send/{token}", handlers.RequireRole(handlers.RoleAdmin, handlers.CancelSendHandler)).Methods("DELETE")
+
+	// Unspent outputs: coin control, output locking and consolidation (requires an API token)
+	api.HandleFunc("/wallet/utxos", handlers.ListUTXOsHandler).Methods("GET")
+	api.HandleFunc("/wallet/utxos/lock", handlers.RequireRole(handlers.RoleOperator, handlers.LockUTXOsHandler)).Methods("POST")
+	api.HandleFunc("/wallet/utxos/unlock", handlers.RequireRole(handlers.RoleOperator, handlers.UnlockUTXOsHandler)).Methods("POST")
+	api.HandleFunc("/wallet/utxos/consolidate/plan", handlers.RequireRole(handlers.RoleOperator, handlers.PlanConsolidationHandler)).Methods("POST")
+	api.HandleFunc("/wallet/utxos/consolidate", handlers.RequireRole(handlers.RoleAdmin, handlers.StartConsolidationHandler)).Methods("POST")
 
-	// WebSocket streaming routes (log-based monitoring, does not start rescans)
+	// Message signing: prove control of a wallet address (requires an API token)
+	api.HandleFunc("/wallet/sign-message", handlers.RequireRole(handlers.RoleOperator, handlers.SignMessageHandler)).Methods("POST")
+
+	// Offline signing: export unsigned transactions, broadcast signed ones (requires an admin token)
+	api.HandleFunc("/wallet/offline", handlers.ListOfflineTransactionsHandler).Methods("GET")
+	api.HandleFunc("/wallet/offline", handlers.RequireRole(handlers.RoleAdmin, handlers.PrepareOfflineTransactionHandler)).Methods("POST")
+	api.HandleFunc("/wallet/offline/{id}", handlers.GetOfflineTransactionHandler).Methods("GET")
+	api.HandleFunc("/wallet/offline/{id}/submit", handlers.RequireRole(handlers.RoleAdmin, handlers.SubmitOfflineTransactionHandler)).Methods("POST")
+	api.HandleFunc("/wallet/offline/{id}", handlers.RequireRole(handlers.RoleAdmin, handlers.CancelOfflineTransactionHandler)).Methods("DELETE")
+
+	// Account mixer: privacy breakdown, and start/stop (requires an API token)
+	api.HandleFunc("/wallet/mixer", handlers.GetMixerHandler).Methods("GET")
+	api.HandleFunc("/wallet/mixer/start", handlers.RequireRole(handlers.RoleOperator, handlers.StartMixerHandler)).Methods("POST")
+	api.HandleFunc("/wallet/mixer/stop", handlers.RequireRole(handlers.RoleOperator, handlers.StopMixerHandler)).Methods("POST")
+
+	// Tickets: listing, and purchasing through a VSP (requires an admin token)
+	api.HandleFunc("/wallet/tickets", handlers.ListWalletTicketsHandler).Methods("GET")
+	api.HandleFunc("/wallet/stake-returns", handlers.GetStakeReturnsHandler).Methods("GET")
+	api.HandleFunc("/wallet/tickets/purchase/preview", handlers.RequireRole(handlers.RoleAdmin, handlers.PreviewTicketPurchaseHandler)).Methods("POST")
+	api.HandleFunc("/wallet/tickets/purchase", handlers.RequireRole(handlers.RoleAdmin, handlers.PurchaseTicketsHandler)).Methods("POST")
+
+	// WebSocket streaming routes (rescan controller progress, does not start rescans)
 	api.HandleFunc("/wallet/stream-rescan-progress", handlers.StreamRescanProgressHandler).Methods("GET")
 	api.HandleFunc("/wallet/grpc/stream-rescan", handlers.StreamRescanGrpcHandler).Methods("GET")
+	api.HandleFunc("/wallet/stream-transactions", handlers.StreamWalletEventsHandler).Methods("GET")
 
 	// Explorer routes
 	api.HandleFunc("/explorer/search", handlers.SearchHandler).Methods("GET")
@@ -105,12 +260,34 @@ func main() {
 	api.HandleFunc("/explorer/blocks/hash/{hash}", handlers.GetBlockByHashHandler).Methods("GET")
 	api.HandleFunc("/explorer/transactions/{txhash}", handlers.GetTransactionHandler).Methods("GET")
 	api.HandleFunc("/explorer/address/{address}", handlers.GetAddressHandler).Methods("GET")
+	api.HandleFunc("/explorer/verify-message", handlers.VerifyMessageHandler).Methods("POST")
 
 	// Treasury/Governance routes
+	api.HandleFunc("/price", handlers.GetExchangeRateHandler).Methods("GET")
+	api.HandleFunc("/mixing/stats", handlers.GetMixStatsHandler).Methods("GET")
+	api.HandleFunc("/mixing/scan", handlers.RequireRole(handlers.RoleOperator, handlers.TriggerMixStatsScanHandler)).Methods("POST")
 	api.HandleFunc("/treasury/info", handlers.GetTreasuryInfoHandler).Methods("GET")
 	api.HandleFunc("/treasury/scan-history", handlers.TriggerTSpendScanHandler).Methods("POST")
 	api.HandleFunc("/treasury/scan-progress", handlers.GetTSpendScanProgressHandler).Methods("GET")
 	api.HandleFunc("/treasury/scan-results", handlers.GetTSpendScanResultsHandler).Methods("GET")
+	api.HandleFunc("/governance/agendas", handlers.GetAgendasHandler).Methods("GET")
+
+	// Wallet vote choices for consensus agendas (changes require an API token)
+	api.HandleFunc("/wallet/vote-choices", handlers.GetVoteChoicesHandler).Methods("GET")
+	api.HandleFunc("/wallet/vote-choices", handlers.RequireRole(handlers.RoleOperator, handlers.SetVoteChoiceHandler)).Methods("POST")
+
+	// Wallet treasury spend voting policies (changes require an API token)
+	api.HandleFunc("/wallet/treasury-policy", handlers.ListTreasuryPoliciesHandler).Methods("GET")
+	api.HandleFunc("/wallet/treasury-policy", handlers.RequireRole(handlers.RoleOperator, handlers.SetTreasuryPolicyHandler)).Methods("POST")
+	api.HandleFunc("/wallet/tspend-policy", handlers.ListTSpendPoliciesHandler).Methods("GET")
+	api.HandleFunc("/wallet/tspend-policy", handlers.RequireRole(handlers.RoleOperator, handlers.SetTSpendPolicyHandler)).Methods("POST")
+	api.HandleFunc("/wallet/tspend-policy/{hash}", handlers.GetTSpendPolicyHandler).Methods("GET")
+
+	// Background job routes (cancelling requires an API token)
+	api.HandleFunc("/jobs", handlers.ListJobsHandler).Methods("GET")
+	api.HandleFunc("/jobs/stream", handlers.StreamJobsHandler).Methods("GET")
+	api.HandleFunc("/jobs/{id}", handlers.GetJobHandler).Methods("GET")
+	api.HandleFunc("/jobs/{id}/cancel", handlers.RequireRole(handlers.RoleOperator, handlers.CancelJobHandler)).Methods("POST")
 
 	// CORS configuration
 	corsHandler := cors.New(cors.Options{
@@ -127,8 +304,9 @@ func main() {
 	log.Printf("Starting Decred Dashboard API server on %s", address)
 	log.Println("Node endpoints: /api/dashboard, /api/node/*, /api/blockchain/*, /api/network/*")
 	log.Println("Wallet endpoints: /api/wallet/status, /api/wallet/dashboard, /api/wallet/importxpub")
-	log.Println("Wallet gRPC endpoints: /api/wallet/grpc/stream-rescan (real-time streaming)")
+	log.Println("Wallet gRPC endpoints: /api/wallet/grpc/stream-rescan, /api/wallet/stream-transactions (real-time streaming)")
 	log.Println("Explorer endpoints: /api/explorer/search, /api/explorer/blocks/*, /api/explorer/transactions/*")
+	log.Println("Job endpoints: /api/jobs, /api/jobs/{id}, /api/jobs/{id}/cancel, /api/jobs/stream (WebSocket)")
 	log.Fatal(http.ListenAndServe(address, corsHandler.Handler(r)))
 }
 
@@ -139,3 +317,42 @@ func getEnv(key, defaultValue string) string {
 	}
 	return value
 }
+
+// getEnvDuration parses a duration such as "5m", falling back to defaultValue
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		log.Printf("Warning: Invalid %s %q, using %s", key, value, defaultValue)
+		return defaultValue
+	}
+	return d
+}
+
+// getEnvInt parses a non-negative integer, falling back to defaultValue
+func getEnvInt(key string, defaultValue int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		log.Printf("Warning: Invalid %s %q, using %d", key, value, defaultValue)
+		return defaultValue
+	}
+	return n
+}
+
+// getEnvList splits a comma-separated value, dropping empty entries
+func getEnvList(key string) []string {
+	var items []string
+	for _, item := range strings.Split(os.Getenv(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
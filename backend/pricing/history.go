@@ -0,0 +1,130 @@
+// Copyright (c) 2015-2025 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package pricing
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxPriceGapDays is how many days a price may be carried forward when the
+// price file has no entry for the day of a transaction
+const maxPriceGapDays = 7
+
+// PriceHistory maps UTC days to the fiat price of one DCR
+type PriceHistory struct {
+	days   []time.Time // Sorted ascending
+	prices map[time.Time]float64
+}
+
+// ParsePriceHistory reads a CSV of date,price rows. Dates may be YYYY-MM-DD,
+// RFC 3339 or Unix seconds. A header row and extra columns are ignored.
+func ParsePriceHistory(r io.Reader) (*PriceHistory, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	history := &PriceHistory{prices: make(map[time.Time]float64)}
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read price file: %w", err)
+		}
+		if len(record) < 2 {
+			return nil, fmt.Errorf("price file line %d: expected date,price", line)
+		}
+
+		price, err := strconv.ParseFloat(strings.TrimSpace(record[1]), 64)
+		if err != nil {
+			if line == 1 {
+				continue // Header
+			}
+			return nil, fmt.Errorf("price file line %d: invalid price %q", line, record[1])
+		}
+		if price < 0 {
+			return nil, fmt.Errorf("price file line %d: negative price", line)
+		}
+		day, err := parsePriceDate(strings.TrimSpace(record[0]))
+		if err != nil {
+			return nil, fmt.Errorf("price file line %d: %w", line, err)
+		}
+
+		if _, ok := history.prices[day]; !ok {
+			history.days = append(history.days, day)
+		}
+		history.prices[day] = price
+	}
+
+	if len(history.days) == 0 {
+		return nil, fmt.Errorf("price file has no prices")
+	}
+	sort.Slice(history.days, func(i, j int) bool {
+		return history.days[i].Before(history.days[j])
+	})
+	return history, nil
+}
+
+func parsePriceDate(s string) (time.Time, error) {
+	if t, err := time.Parse("2006-01-02", s); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return utcDay(t), nil
+	}
+	if secs, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return utcDay(time.Unix(secs, 0)), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q", s)
+}
+
+func utcDay(t time.Time) time.Time {
+	y, m, d := t.UTC().Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+// Lookup returns the price for the day of t, carrying the last known price
+// forward for up to maxPriceGapDays
+func (h *PriceHistory) Lookup(t time.Time) (float64, bool) {
+	day := utcDay(t)
+	if price, ok := h.prices[day]; ok {
+		return price, true
+	}
+
+	// Index of the first day after t, the one before it is the latest known price
+	i := sort.Search(len(h.days), func(i int) bool { return h.days[i].After(day) })
+	if i == 0 {
+		return 0, false
+	}
+	prev := h.days[i-1]
+	if day.Sub(prev) > maxPriceGapDays*24*time.Hour {
+		return 0, false
+	}
+	return h.prices[prev], true
+}
+
+// LoadPriceHistory reads a date,price CSV file
+func LoadPriceHistory(path string) (*PriceHistory, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open price history: %w", err)
+	}
+	defer f.Close()
+	return ParsePriceHistory(f)
+}
+
+// Len returns the number of days with a price
+func (h *PriceHistory) Len() int {
+	return len(h.days)
+}
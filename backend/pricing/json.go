@@ -0,0 +1,133 @@
+// Copyright (c) 2015-2025 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package pricing
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// maxResponseSize bounds the JSON document read from a price source
+const maxResponseSize = 1 << 20
+
+// JSONProvider reads the rate from a JSON document at a local file path or an
+// HTTP(S) URL. Field is a dotted path to the rate inside the document, where
+// "{currency}" is replaced by the lower-case currency code and numeric
+// segments index arrays. For example "decred.{currency}" reads a CoinGecko
+// simple price response, and the default "{currency}" reads {"usd": 17.42}.
+type JSONProvider struct {
+	Source string
+	Field  string
+	client *http.Client
+}
+
+// NewJSONProvider creates a provider for a JSON file or endpoint
+func NewJSONProvider(source, field string) *JSONProvider {
+	if field == "" {
+		field = "{currency}"
+	}
+	return &JSONProvider{
+		Source: source,
+		Field:  field,
+		client: &http.Client{},
+	}
+}
+
+// Name implements Provider
+func (p *JSONProvider) Name() string {
+	return p.Source
+}
+
+// FetchRate implements Provider
+func (p *JSONProvider) FetchRate(ctx context.Context, currency string) (float64, error) {
+	data, err := p.read(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var doc interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return 0, fmt.Errorf("invalid JSON from %s: %w", p.Source, err)
+	}
+
+	path := strings.ReplaceAll(p.Field, "{currency}", strings.ToLower(currency))
+	rate, err := lookupNumber(doc, path)
+	if err != nil {
+		return 0, err
+	}
+	if rate <= 0 {
+		return 0, fmt.Errorf("non-positive rate %v at %q", rate, path)
+	}
+	return rate, nil
+}
+
+func (p *JSONProvider) read(ctx context.Context) ([]byte, error) {
+	if !strings.HasPrefix(p.Source, "http://") && !strings.HasPrefix(p.Source, "https://") {
+		data, err := os.ReadFile(p.Source)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read price file: %w", err)
+		}
+		return data, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.Source, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch price: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("price source returned HTTP %d", resp.StatusCode)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxResponseSize))
+}
+
+// lookupNumber follows a dotted path through decoded JSON and returns the
+// number (or numeric string) at its end
+func lookupNumber(doc interface{}, path string) (float64, error) {
+	value := doc
+	for _, segment := range strings.Split(path, ".") {
+		switch v := value.(type) {
+		case map[string]interface{}:
+			next, ok := v[segment]
+			if !ok {
+				return 0, fmt.Errorf("field %q not found", path)
+			}
+			value = next
+		case []interface{}:
+			i, err := strconv.Atoi(segment)
+			if err != nil || i < 0 || i >= len(v) {
+				return 0, fmt.Errorf("invalid index %q in %q", segment, path)
+			}
+			value = v[i]
+		default:
+			return 0, fmt.Errorf("field %q not found", path)
+		}
+	}
+
+	switch v := value.(type) {
+	case float64:
+		return v, nil
+	case string:
+		rate, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return 0, fmt.Errorf("field %q is not a number", path)
+		}
+		return rate, nil
+	}
+	return 0, fmt.Errorf("field %q is not a number", path)
+}
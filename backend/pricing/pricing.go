@@ -0,0 +1,183 @@
+// Copyright (c) 2015-2025 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+// Package pricing provides DCR exchange rates for fiat equivalents. Rates come
+// from a pluggable Provider and are refreshed in the background into a cache
+// that records when each rate was fetched, so callers can tell stale values
+// apart. An optional price history supplies rates for past days.
+package pricing
+
+import (
+	"context"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"decred-pulse-backend/types"
+)
+
+const (
+	defaultRefreshInterval = 5 * time.Minute
+	defaultMaxAge          = 30 * time.Minute
+	fetchTimeout           = 15 * time.Second
+)
+
+// Provider fetches the current DCR price in a fiat currency
+type Provider interface {
+	// Name identifies the provider in rate metadata
+	Name() string
+
+	// FetchRate returns the fiat price of one DCR
+	FetchRate(ctx context.Context, currency string) (float64, error)
+}
+
+// Config selects the rate provider. A nil Provider disables current rates.
+type Config struct {
+	Provider        Provider
+	Currency        string        // Fiat currency code, default USD
+	RefreshInterval time.Duration // Delay between fetches
+	MaxAge          time.Duration // Rates older than this are reported as stale
+	History         *PriceHistory // Optional daily rates for past transactions
+}
+
+var (
+	cacheMutex sync.RWMutex
+	config     Config
+	cached     *types.ExchangeRate
+	lastError  string
+
+	initOnce sync.Once
+)
+
+// Init configures the provider and starts refreshing the cached rate.
+// Only the first call has an effect.
+func Init(cfg Config) {
+	initOnce.Do(func() {
+		if cfg.Currency == "" {
+			cfg.Currency = "USD"
+		}
+		cfg.Currency = strings.ToUpper(cfg.Currency)
+		if cfg.RefreshInterval <= 0 {
+			cfg.RefreshInterval = defaultRefreshInterval
+		}
+		if cfg.MaxAge <= 0 {
+			cfg.MaxAge = defaultMaxAge
+		}
+
+		cacheMutex.Lock()
+		config = cfg
+		cacheMutex.Unlock()
+
+		if cfg.History != nil {
+			log.Printf("💱 Loaded %d days of %s price history", cfg.History.Len(), cfg.Currency)
+		}
+		if cfg.Provider == nil {
+			log.Println("No exchange rate provider configured. Fiat values disabled.")
+			return
+		}
+
+		log.Printf("💱 Exchange rates from %s (%s, refresh every %s)", cfg.Provider.Name(), cfg.Currency, cfg.RefreshInterval)
+		go refreshLoop(cfg)
+	})
+}
+
+// Currency returns the configured fiat currency code
+func Currency() string {
+	cacheMutex.RLock()
+	defer cacheMutex.RUnlock()
+	if config.Currency == "" {
+		return "USD"
+	}
+	return config.Currency
+}
+
+// History returns the configured price history, or nil
+func History() *PriceHistory {
+	cacheMutex.RLock()
+	defer cacheMutex.RUnlock()
+	return config.History
+}
+
+// refreshLoop fetches the rate immediately and then every refresh interval
+func refreshLoop(cfg Config) {
+	ticker := time.NewTicker(cfg.RefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		refresh(cfg)
+		<-ticker.C
+	}
+}
+
+func refresh(cfg Config) {
+	ctx, cancel := context.WithTimeout(context.Background(), fetchTimeout)
+	defer cancel()
+
+	rate, err := cfg.Provider.FetchRate(ctx, cfg.Currency)
+
+	cacheMutex.Lock()
+	defer cacheMutex.Unlock()
+
+	if err != nil {
+		// Keep serving the last rate, it is flagged stale once it ages out
+		lastError = err.Error()
+		log.Printf("Warning: Failed to fetch %s exchange rate from %s: %v", cfg.Currency, cfg.Provider.Name(), err)
+		return
+	}
+	lastError = ""
+	cached = &types.ExchangeRate{
+		Currency:  cfg.Currency,
+		Rate:      rate,
+		Source:    cfg.Provider.Name(),
+		FetchedAt: time.Now(),
+	}
+}
+
+// CurrentRate returns the cached rate with its age and staleness, or nil when
+// no rate has been fetched yet
+func CurrentRate() *types.ExchangeRate {
+	cacheMutex.RLock()
+	defer cacheMutex.RUnlock()
+
+	if cached == nil {
+		return nil
+	}
+	rate := *cached
+	age := time.Since(rate.FetchedAt)
+	rate.AgeSeconds = int64(age.Seconds())
+	rate.Stale = age > config.MaxAge
+	rate.LastError = lastError
+	return &rate
+}
+
+// Convert values a DCR amount at the current rate, or returns nil without a rate
+func Convert(amount float64) *types.FiatValue {
+	rate := CurrentRate()
+	if rate == nil {
+		return nil
+	}
+	return &types.FiatValue{
+		Currency: rate.Currency,
+		Value:    amount * rate.Rate,
+		Rate:     rate.Rate,
+		Stale:    rate.Stale,
+	}
+}
+
+// ConvertAt values a DCR amount at the rate of the day of t when the price
+// history covers it, and at the current rate otherwise
+func ConvertAt(amount float64, t time.Time) *types.FiatValue {
+	if history := History(); history != nil {
+		if price, ok := history.Lookup(t); ok {
+			return &types.FiatValue{
+				Currency:   Currency(),
+				Value:      amount * price,
+				Rate:       price,
+				Historical: true,
+			}
+		}
+	}
+	return Convert(amount)
+}
@@ -29,6 +29,15 @@ var (
 	// WalletGrpcClient is the gRPC client for dcrwallet (for streaming)
 	WalletGrpcClient pb.WalletServiceClient
 
+	// AccountMixerClient is the gRPC client for the dcrwallet account mixer
+	AccountMixerClient pb.AccountMixerServiceClient
+
+	// WalletLoaderClient is the gRPC client for creating, opening and syncing wallets
+	WalletLoaderClient pb.WalletLoaderServiceClient
+
+	// SeedClient is the gRPC client for generating and decoding wallet seeds
+	SeedClient pb.SeedServiceClient
+
 	// WalletGrpcConn is the gRPC connection (kept for cleanup)
 	WalletGrpcConn *grpc.ClientConn
 )
@@ -175,6 +184,9 @@ func InitWalletGrpcClient(config GrpcConfig) error {
 
 	WalletGrpcConn = conn
 	WalletGrpcClient = pb.NewWalletServiceClient(conn)
+	AccountMixerClient = pb.NewAccountMixerServiceClient(conn)
+	WalletLoaderClient = pb.NewWalletLoaderServiceClient(conn)
+	SeedClient = pb.NewSeedServiceClient(conn)
 
 	log.Println("dcrwallet gRPC client initialized with mutual TLS authentication")
 	return nil
@@ -0,0 +1,195 @@
+// Copyright (c) 2015-2025 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	pb "decred.org/dcrwallet/v4/rpc/walletrpc"
+	"github.com/decred/dcrd/dcrjson/v4"
+
+	"decred-pulse-backend/rpc"
+	"decred-pulse-backend/types"
+)
+
+var (
+	// ErrAccountNotFound is returned for account names the wallet does not know
+	ErrAccountNotFound = errors.New("account not found")
+
+	// ErrInvalidAccountName is returned for empty, reserved or duplicate names
+	ErrInvalidAccountName = errors.New("invalid account name")
+)
+
+// Address branches of a BIP0044 account
+const (
+	branchExternal = 0
+	branchInternal = 1
+)
+
+// accountError maps dcrwallet account errors to the errors handlers report
+func accountError(action string, err error) error {
+	var rpcErr *dcrjson.RPCError
+	if errors.As(err, &rpcErr) {
+		switch rpcErr.Code {
+		case dcrjson.ErrRPCWalletInvalidAccountName:
+			return ErrAccountNotFound
+		case dcrjson.ErrRPCWalletUnlockNeeded:
+			return ErrWalletLocked
+		case dcrjson.ErrRPCInvalidParameter:
+			return fmt.Errorf("%w: %s", ErrInvalidAccountName, rpcErr.Message)
+		}
+		if strings.Contains(rpcErr.Message, "exists") {
+			return fmt.Errorf("%w: account name already in use", ErrInvalidAccountName)
+		}
+	}
+	return fmt.Errorf("failed to %s: %w", action, err)
+}
+
+// validateAccountName rejects names dcrwallet reserves
+func validateAccountName(name string) error {
+	switch strings.TrimSpace(name) {
+	case "":
+		return fmt.Errorf("%w: name is required", ErrInvalidAccountName)
+	case "*", "imported":
+		return fmt.Errorf("%w: %q is reserved", ErrInvalidAccountName, name)
+	}
+	if name != strings.TrimSpace(name) {
+		return fmt.Errorf("%w: leading or trailing whitespace", ErrInvalidAccountName)
+	}
+	return nil
+}
+
+// CreateAccount creates a BIP0044 account with createnewaccount. The wallet
+// must be unlocked to derive the account keys.
+func CreateAccount(ctx context.Context, name string) (*types.AccountDetails, error) {
+	if err := validateAccountName(name); err != nil {
+		return nil, err
+	}
+
+	params, err := marshalParams(name)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := rpc.WalletClient.RawRequest(ctx, "createnewaccount", params); err != nil {
+		return nil, accountError("create account", err)
+	}
+	log.Printf("Account %q created", name)
+
+	refreshAccountNamesIfConnected(ctx)
+	return FetchAccountDetails(ctx, name)
+}
+
+// RenameAccount renames an account with renameaccount
+func RenameAccount(ctx context.Context, oldName, newName string) (*types.AccountDetails, error) {
+	if err := validateAccountName(newName); err != nil {
+		return nil, err
+	}
+	if oldName == "imported" {
+		return nil, fmt.Errorf("%w: the imported account cannot be renamed", ErrInvalidAccountName)
+	}
+
+	params, err := marshalParams(oldName, newName)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := rpc.WalletClient.RawRequest(ctx, "renameaccount", params); err != nil {
+		return nil, accountError("rename account", err)
+	}
+	log.Printf("Account %q renamed to %q", oldName, newName)
+
+	refreshAccountNamesIfConnected(ctx)
+	return FetchAccountDetails(ctx, newName)
+}
+
+// FetchAccountDetails returns the account number and the next external and
+// internal address indexes of an account
+func FetchAccountDetails(ctx context.Context, name string) (*types.AccountDetails, error) {
+	details := &types.AccountDetails{AccountName: name}
+
+	// The account number is only available through gRPC
+	if rpc.WalletGrpcClient != nil {
+		resp, err := rpc.WalletGrpcClient.AccountNumber(ctx, &pb.AccountNumberRequest{AccountName: name})
+		if err == nil {
+			details.AccountNumber = &resp.AccountNumber
+		}
+	}
+
+	external, err := fetchAccountAddressIndex(ctx, name, branchExternal)
+	if err != nil {
+		return nil, err
+	}
+	internal, err := fetchAccountAddressIndex(ctx, name, branchInternal)
+	if err != nil {
+		return nil, err
+	}
+	details.ExternalIndex = external
+	details.InternalIndex = internal
+	return details, nil
+}
+
+// fetchAccountAddressIndex returns the next child index of an account branch
+// with accountaddressindex
+func fetchAccountAddressIndex(ctx context.Context, account string, branch int) (uint32, error) {
+	params, err := marshalParams(account, branch)
+	if err != nil {
+		return 0, err
+	}
+	result, err := rpc.WalletClient.RawRequest(ctx, "accountaddressindex", params)
+	if err != nil {
+		return 0, accountError("get address index", err)
+	}
+
+	var index uint32
+	if err := json.Unmarshal(result, &index); err != nil {
+		return 0, fmt.Errorf("invalid accountaddressindex result: %w", err)
+	}
+	return index, nil
+}
+
+// FetchAccountXpub returns the extended public key of an account with
+// getmasterpubkey, with the payload to encode in a QR code
+func FetchAccountXpub(ctx context.Context, name string) (*types.AccountXpub, error) {
+	params, err := marshalParams(name)
+	if err != nil {
+		return nil, err
+	}
+	result, err := rpc.WalletClient.RawRequest(ctx, "getmasterpubkey", params)
+	if err != nil {
+		return nil, accountError("get master pubkey", err)
+	}
+
+	var xpub string
+	if err := json.Unmarshal(result, &xpub); err != nil {
+		return nil, fmt.Errorf("invalid getmasterpubkey result: %w", err)
+	}
+
+	export := &types.AccountXpub{
+		AccountName: name,
+		Xpub:        xpub,
+		// Extended keys are case-sensitive base58, so the QR code carries the
+		// key verbatim, as read by ImportXpub
+		QRPayload: xpub,
+	}
+	if params, err := ActiveNetParams(ctx); err == nil {
+		export.Network = params.Name
+	}
+	return export, nil
+}
+
+// refreshAccountNamesIfConnected updates the account name cache after account
+// changes so notifications and history use the new names
+func refreshAccountNamesIfConnected(ctx context.Context) {
+	if rpc.WalletGrpcClient == nil {
+		return
+	}
+	if err := refreshAccountNames(ctx); err != nil {
+		log.Printf("Warning: Failed to refresh account names: %v", err)
+	}
+}
@@ -0,0 +1,317 @@
+// Copyright (c) 2015-2025 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/decred/dcrd/dcrjson/v4"
+
+	"decred-pulse-backend/rpc"
+	"decred-pulse-backend/types"
+)
+
+var (
+	// ErrInvalidGapPolicy is returned for gap policies dcrwallet does not know
+	ErrInvalidGapPolicy = errors.New(`gap policy must be "error", "ignore" or "wrap"`)
+
+	// ErrInvalidBranch is returned for branches other than receive and change
+	ErrInvalidBranch = errors.New("branch must be 0 (receive) or 1 (change)")
+
+	// ErrGapLimit is returned when a new receive address would exceed the gap
+	// of unused addresses under the "error" gap policy
+	ErrGapLimit = errors.New(`unused address gap limit reached, use gap policy "ignore" or "wrap"`)
+)
+
+// AddressFilter selects and paginates the addresses of an account
+type AddressFilter struct {
+	Account  string
+	Branch   *uint32
+	Used     *bool
+	Page     int
+	PageSize int
+}
+
+// addressInfo holds the validateaddress fields used for derivation paths
+type addressInfo struct {
+	IsMine   bool    `json:"ismine"`
+	Account  string  `json:"account"`
+	AccountN *uint32 `json:"accountn"` // Not set for imported xpub accounts
+	Branch   *uint32 `json:"branch"`
+	Index    *uint32 `json:"index"`
+}
+
+// receivedByAddress is the listreceivedbyaddress result for one address
+type receivedByAddress struct {
+	Address       string   `json:"address"`
+	Amount        float64  `json:"amount"`
+	Confirmations int64    `json:"confirmations"` // Of the latest payment
+	TxIDs         []string `json:"txids"`
+}
+
+// PaymentURI builds a decred: payment URI for an address
+func PaymentURI(address string, amount float64, label string) string {
+	params := url.Values{}
+	if amount > 0 {
+		params.Set("amount", strconv.FormatFloat(amount, 'f', -1, 64))
+	}
+	if label != "" {
+		params.Set("label", label)
+	}
+	uri := "decred:" + address
+	if len(params) > 0 {
+		uri += "?" + params.Encode()
+	}
+	return uri
+}
+
+// bip44Path formats the derivation path of an account address. Imported xpub
+// accounts have no account number and get a path relative to the xpub.
+func bip44Path(coinType uint32, account *uint32, branch, index uint32) string {
+	if account == nil {
+		return fmt.Sprintf("M/%d/%d", branch, index)
+	}
+	return fmt.Sprintf("m/44'/%d'/%d'/%d/%d", coinType, *account, branch, index)
+}
+
+// GenerateAddress derives the next receive (branch 0, getnewaddress) or change
+// (branch 1, getrawchangeaddress) address of an account
+func GenerateAddress(ctx context.Context, req types.NewAddressRequest) (*types.Address, error) {
+	account := req.Account
+	if account == "" {
+		account = "default"
+	}
+
+	var method string
+	var params []json.RawMessage
+	var err error
+	switch req.Branch {
+	case branchExternal:
+		method = "getnewaddress"
+		switch req.GapPolicy {
+		case "":
+			params, err = marshalParams(account)
+		case "error", "ignore", "wrap":
+			params, err = marshalParams(account, req.GapPolicy)
+		default:
+			return nil, ErrInvalidGapPolicy
+		}
+	case branchInternal:
+		if req.GapPolicy != "" {
+			return nil, fmt.Errorf("%w: gap policies apply to receive addresses only", ErrInvalidGapPolicy)
+		}
+		method = "getrawchangeaddress"
+		params, err = marshalParams(account)
+	default:
+		return nil, ErrInvalidBranch
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := rpc.WalletClient.RawRequest(ctx, method, params)
+	if err != nil {
+		var rpcErr *dcrjson.RPCError
+		if errors.As(err, &rpcErr) && strings.Contains(rpcErr.Message, "gap limit") {
+			return nil, ErrGapLimit
+		}
+		return nil, accountError("generate address", err)
+	}
+	var address string
+	if err := json.Unmarshal(result, &address); err != nil {
+		return nil, fmt.Errorf("invalid %s result: %w", method, err)
+	}
+
+	info, err := fetchAddressInfo(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+	wallet, err := fetchWalletInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	addr := &types.Address{
+		Address: address,
+		Account: account,
+		Branch:  req.Branch,
+		URI:     PaymentURI(address, req.Amount, req.Label),
+	}
+	if info.Branch != nil && info.Index != nil {
+		addr.Branch = *info.Branch
+		addr.Index = *info.Index
+		addr.Path = bip44Path(wallet.CoinType, info.AccountN, *info.Branch, *info.Index)
+	}
+	log.Printf("Generated %s address for account %q", method, account)
+	return addr, nil
+}
+
+// ListAddresses returns every address derived for an account with its path,
+// usage and received amount. Addresses are ordered by branch, newest first.
+func ListAddresses(ctx context.Context, filter AddressFilter) (*types.AddressListResponse, error) {
+	account := filter.Account
+	if account == "" {
+		account = "default"
+	}
+
+	response := &types.AddressListResponse{Account: account}
+
+	// getaddressesbyaccount lists external addresses 0..ext-1, then internal
+	// addresses 0..int-1, so positions map to derivation paths
+	imported := account == "imported"
+	if !imported {
+		external, err := fetchAccountAddressIndex(ctx, account, branchExternal)
+		if err != nil {
+			return nil, err
+		}
+		internal, err := fetchAccountAddressIndex(ctx, account, branchInternal)
+		if err != nil {
+			return nil, err
+		}
+		response.ExternalIndex = external
+		response.InternalIndex = internal
+	}
+
+	params, err := marshalParams(account)
+	if err != nil {
+		return nil, err
+	}
+	result, err := rpc.WalletClient.RawRequest(ctx, "getaddressesbyaccount", params)
+	if err != nil {
+		return nil, accountError("list addresses", err)
+	}
+	var listed []string
+	if err := json.Unmarshal(result, &listed); err != nil {
+		return nil, fmt.Errorf("invalid getaddressesbyaccount result: %w", err)
+	}
+
+	received, err := fetchReceivedByAddress(ctx, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	// Paths need the coin type and account number, read from the first address
+	var coinType uint32
+	var accountNumber *uint32
+	if !imported && len(listed) > 0 {
+		wallet, err := fetchWalletInfo(ctx)
+		if err != nil {
+			return nil, err
+		}
+		coinType = wallet.CoinType
+		info, err := fetchAddressInfo(ctx, listed[0])
+		if err != nil {
+			return nil, err
+		}
+		accountNumber = info.AccountN
+	}
+	// A new address between the index and list calls shifts positions
+	positional := uint32(len(listed)) == response.ExternalIndex+response.InternalIndex
+
+	matched := make([]types.Address, 0, len(listed))
+	for i, address := range listed {
+		addr := types.Address{
+			Address: address,
+			Account: account,
+			URI:     PaymentURI(address, 0, ""),
+		}
+		if r, ok := received[address]; ok {
+			addr.Received = r.Amount
+			addr.TxCount = len(r.TxIDs)
+			addr.Used = len(r.TxIDs) > 0
+		}
+
+		if !imported {
+			if positional {
+				addr.Index = uint32(i)
+				if addr.Index >= response.ExternalIndex {
+					addr.Branch = branchInternal
+					addr.Index -= response.ExternalIndex
+				}
+			} else {
+				info, err := fetchAddressInfo(ctx, address)
+				if err != nil || info.Branch == nil || info.Index == nil {
+					log.Printf("Warning: No derivation path for %s: %v", address, err)
+					continue
+				}
+				addr.Branch, addr.Index = *info.Branch, *info.Index
+			}
+			addr.Path = bip44Path(coinType, accountNumber, addr.Branch, addr.Index)
+		}
+
+		if filter.Branch != nil && addr.Branch != *filter.Branch {
+			continue
+		}
+		if filter.Used != nil && addr.Used != *filter.Used {
+			continue
+		}
+		matched = append(matched, addr)
+	}
+
+	sort.SliceStable(matched, func(i, j int) bool {
+		if matched[i].Branch != matched[j].Branch {
+			return matched[i].Branch < matched[j].Branch
+		}
+		return matched[i].Index > matched[j].Index
+	})
+
+	window := paginate(len(matched), filter.Page, filter.PageSize, 50)
+
+	response.Addresses = matched[window.start:window.end]
+	response.Total = len(matched)
+	response.CurrentPage = window.page
+	response.PageSize = window.pageSize
+	response.TotalPages = window.totalPages
+	return response, nil
+}
+
+// fetchAddressInfo calls validateaddress for a wallet address
+func fetchAddressInfo(ctx context.Context, address string) (*addressInfo, error) {
+	params, err := marshalParams(address)
+	if err != nil {
+		return nil, err
+	}
+	result, err := rpc.WalletClient.RawRequest(ctx, "validateaddress", params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to validate address: %w", err)
+	}
+
+	var info addressInfo
+	if err := json.Unmarshal(result, &info); err != nil {
+		return nil, fmt.Errorf("invalid validateaddress result: %w", err)
+	}
+	return &info, nil
+}
+
+// fetchReceivedByAddress returns the amounts received by every wallet address
+// with funds in transactions with at least minConf confirmations
+func fetchReceivedByAddress(ctx context.Context, minConf int64) (map[string]receivedByAddress, error) {
+	params, err := marshalParams(minConf, false)
+	if err != nil {
+		return nil, err
+	}
+	result, err := rpc.WalletClient.RawRequest(ctx, "listreceivedbyaddress", params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list received amounts: %w", err)
+	}
+
+	var list []receivedByAddress
+	if err := json.Unmarshal(result, &list); err != nil {
+		return nil, fmt.Errorf("invalid listreceivedbyaddress result: %w", err)
+	}
+	received := make(map[string]receivedByAddress, len(list))
+	for _, r := range list {
+		received[r.Address] = r
+	}
+	return received, nil
+}
@@ -0,0 +1,277 @@
+// Copyright (c) 2015-2025 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/chaincfg/v3"
+	"github.com/decred/dcrd/dcrjson/v4"
+	"github.com/decred/dcrd/dcrutil/v4"
+
+	"decred-pulse-backend/rpc"
+	"decred-pulse-backend/types"
+)
+
+var (
+	// ErrInvalidVoteChoice is returned for unknown agendas, choices or tickets
+	ErrInvalidVoteChoice = errors.New("invalid vote choice")
+
+	// ErrUnknownVoteVersion is returned when dcrd has no agendas for a version
+	ErrUnknownVoteVersion = errors.New("unknown vote version")
+)
+
+// latestVoteVersion returns the highest vote version with deployments on
+// the network
+func latestVoteVersion(params *chaincfg.Params) uint32 {
+	var latest uint32
+	for version := range params.Deployments {
+		if version > latest {
+			latest = version
+		}
+	}
+	return latest
+}
+
+// FetchAgendas builds the consensus agenda dashboard for a vote version from
+// dcrd getvoteinfo, getstakeversioninfo and getblocksubsidy. A zero version
+// selects the latest one. The wallet's default choices are included when a
+// wallet is connected.
+func FetchAgendas(ctx context.Context, version uint32) (*types.AgendasResponse, error) {
+	if rpc.DcrdClient == nil {
+		return nil, fmt.Errorf("dcrd RPC client not initialized")
+	}
+
+	params, err := ActiveNetParams(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if version == 0 {
+		version = latestVoteVersion(params)
+	}
+
+	voteInfo, err := rpc.DcrdClient.GetVoteInfo(ctx, version)
+	if err != nil {
+		var rpcErr *dcrjson.RPCError
+		if errors.As(err, &rpcErr) && rpcErr.Code == dcrjson.ErrRPCInvalidParameter {
+			return nil, fmt.Errorf("%w: %d", ErrUnknownVoteVersion, version)
+		}
+		return nil, fmt.Errorf("failed to get vote info: %w", err)
+	}
+
+	response := &types.AgendasResponse{
+		VoteVersion:         voteInfo.VoteVersion,
+		CurrentHeight:       voteInfo.CurrentHeight,
+		IntervalStartHeight: voteInfo.StartHeight,
+		IntervalEndHeight:   voteInfo.EndHeight,
+		BlocksRemaining:     voteInfo.EndHeight - voteInfo.CurrentHeight,
+		Quorum:              voteInfo.Quorum,
+		TotalVotes:          voteInfo.TotalVotes,
+		Agendas:             make([]types.ConsensusAgenda, 0, len(voteInfo.Agendas)),
+	}
+	if response.BlocksRemaining < 0 {
+		response.BlocksRemaining = 0
+	}
+
+	// Lock in needs this share of the non-abstain votes in an interval
+	threshold := float64(params.RuleChangeActivationMultiplier) / float64(params.RuleChangeActivationDivisor) * 100
+
+	for _, a := range voteInfo.Agendas {
+		agenda := types.ConsensusAgenda{
+			ID:                a.ID,
+			Description:       a.Description,
+			Status:            a.Status,
+			Mask:              a.Mask,
+			StartTime:         time.Unix(int64(a.StartTime), 0).UTC(),
+			ExpireTime:        time.Unix(int64(a.ExpireTime), 0).UTC(),
+			Choices:           make([]types.AgendaChoice, 0, len(a.Choices)),
+			QuorumProgress:    a.QuorumProgress * 100,
+			QuorumMet:         a.QuorumProgress >= 1,
+			ApprovalThreshold: threshold,
+		}
+		for _, c := range a.Choices {
+			agenda.Choices = append(agenda.Choices, types.AgendaChoice{
+				ID:          c.ID,
+				Description: c.Description,
+				Bits:        c.Bits,
+				IsAbstain:   c.IsAbstain,
+				IsNo:        c.IsNo,
+				Count:       c.Count,
+				Progress:    c.Progress * 100,
+			})
+			switch {
+			case c.IsAbstain:
+				agenda.AbstainVotes += c.Count
+			case c.IsNo:
+				agenda.NoVotes += c.Count
+			default:
+				agenda.YesVotes += c.Count
+			}
+		}
+		if cast := agenda.YesVotes + agenda.NoVotes; cast > 0 {
+			agenda.Approval = float64(agenda.YesVotes) / float64(cast) * 100
+		}
+		response.Agendas = append(response.Agendas, agenda)
+	}
+
+	if progress, err := fetchStakeVersionProgress(ctx, params, response.VoteVersion); err == nil {
+		response.StakeVersion = progress
+	} else {
+		log.Printf("Warning: Could not get stake version info: %v", err)
+	}
+
+	// Each of the block's voters earns an equal share of the PoS subsidy
+	subsidy, err := rpc.DcrdClient.GetBlockSubsidy(ctx, voteInfo.CurrentHeight+1, params.TicketsPerBlock)
+	if err == nil && params.TicketsPerBlock > 0 {
+		response.VoteReward = dcrutil.Amount(subsidy.PoS / int64(params.TicketsPerBlock)).ToCoin()
+	} else if err != nil {
+		log.Printf("Warning: Could not get block subsidy: %v", err)
+	}
+
+	if rpc.WalletClient != nil {
+		if choices, err := FetchVoteChoices(ctx, ""); err == nil && choices.Version == response.VoteVersion {
+			defaults := make(map[string]string, len(choices.Choices))
+			for _, c := range choices.Choices {
+				defaults[c.AgendaID] = c.ChoiceID
+			}
+			for i := range response.Agendas {
+				response.Agendas[i].WalletChoice = defaults[response.Agendas[i].ID]
+			}
+		}
+	}
+
+	return response, nil
+}
+
+// fetchStakeVersionProgress reports how many votes in the current stake
+// version interval were cast with version or later
+func fetchStakeVersionProgress(ctx context.Context, params *chaincfg.Params, version uint32) (*types.StakeVersionProgress, error) {
+	info, err := rpc.DcrdClient.GetStakeVersionInfo(ctx, 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(info.Intervals) == 0 {
+		return nil, fmt.Errorf("no stake version intervals")
+	}
+
+	interval := info.Intervals[0]
+	progress := &types.StakeVersionProgress{
+		Version:       version,
+		IntervalStart: interval.StartHeight,
+		IntervalEnd:   interval.StartHeight + params.StakeVersionInterval,
+		Threshold:     float64(params.StakeMajorityMultiplier) / float64(params.StakeMajorityDivisor) * 100,
+	}
+	for _, v := range interval.VoteVersions {
+		progress.TotalVotes += v.Count
+		if v.Version >= version {
+			progress.UpgradedVotes += v.Count
+		}
+	}
+	if progress.TotalVotes > 0 {
+		progress.Progress = float64(progress.UpgradedVotes) / float64(progress.TotalVotes) * 100
+	}
+	progress.ThresholdReached = progress.Progress >= progress.Threshold
+	return progress, nil
+}
+
+// FetchVoteChoices returns the wallet's default vote choices, or with a
+// ticket hash that ticket's choices including its overrides
+func FetchVoteChoices(ctx context.Context, ticketHash string) (*types.WalletVoteChoicesResponse, error) {
+	var values []interface{}
+	if ticketHash != "" {
+		if _, err := chainhash.NewHashFromStr(ticketHash); err != nil {
+			return nil, fmt.Errorf("%w: invalid ticket hash", ErrInvalidVoteChoice)
+		}
+		values = append(values, ticketHash)
+	}
+	params, err := marshalParams(values...)
+	if err != nil {
+		return nil, err
+	}
+	result, err := rpc.WalletClient.RawRequest(ctx, "getvotechoices", params)
+	if err != nil {
+		return nil, voteChoiceError("get vote choices", err)
+	}
+
+	var choices struct {
+		Version uint32 `json:"version"`
+		Choices []struct {
+			AgendaID          string `json:"agendaid"`
+			AgendaDescription string `json:"agendadescription"`
+			ChoiceID          string `json:"choiceid"`
+			ChoiceDescription string `json:"choicedescription"`
+		} `json:"choices"`
+	}
+	if err := json.Unmarshal(result, &choices); err != nil {
+		return nil, fmt.Errorf("failed to parse vote choices: %w", err)
+	}
+
+	response := &types.WalletVoteChoicesResponse{
+		Version:    choices.Version,
+		TicketHash: ticketHash,
+		Choices:    make([]types.WalletVoteChoice, 0, len(choices.Choices)),
+	}
+	for _, c := range choices.Choices {
+		response.Choices = append(response.Choices, types.WalletVoteChoice{
+			AgendaID:          c.AgendaID,
+			AgendaDescription: c.AgendaDescription,
+			ChoiceID:          c.ChoiceID,
+			ChoiceDescription: c.ChoiceDescription,
+		})
+	}
+	return response, nil
+}
+
+// SetVoteChoice sets the wallet's choice for an agenda with setvotechoice,
+// for all tickets or, with a ticket hash, only that ticket. dcrwallet also
+// updates the VSP of a ticket registered with one.
+func SetVoteChoice(ctx context.Context, req types.SetVoteChoiceRequest) (*types.WalletVoteChoicesResponse, error) {
+	agendaID := strings.TrimSpace(req.AgendaID)
+	choiceID := strings.TrimSpace(req.ChoiceID)
+	if agendaID == "" || choiceID == "" {
+		return nil, fmt.Errorf("%w: agendaId and choiceId are required", ErrInvalidVoteChoice)
+	}
+
+	values := []interface{}{agendaID, choiceID}
+	if req.TicketHash != "" {
+		if _, err := chainhash.NewHashFromStr(req.TicketHash); err != nil {
+			return nil, fmt.Errorf("%w: invalid ticket hash", ErrInvalidVoteChoice)
+		}
+		values = append(values, req.TicketHash)
+	}
+	params, err := marshalParams(values...)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := rpc.WalletClient.RawRequest(ctx, "setvotechoice", params); err != nil {
+		return nil, voteChoiceError("set vote choice", err)
+	}
+
+	if req.TicketHash != "" {
+		log.Printf("Set vote choice %s=%s for ticket %s", agendaID, choiceID, req.TicketHash)
+	} else {
+		log.Printf("Set default vote choice %s=%s", agendaID, choiceID)
+	}
+	return FetchVoteChoices(ctx, req.TicketHash)
+}
+
+// voteChoiceError maps getvotechoices/setvotechoice errors
+func voteChoiceError(action string, err error) error {
+	var rpcErr *dcrjson.RPCError
+	if errors.As(err, &rpcErr) {
+		switch rpcErr.Code {
+		case dcrjson.ErrRPCInvalidParameter, dcrjson.ErrRPCNoTxInfo:
+			return fmt.Errorf("%w: %s", ErrInvalidVoteChoice, rpcErr.Message)
+		}
+	}
+	return fmt.Errorf("failed to %s: %w", action, err)
+}
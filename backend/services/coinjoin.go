@@ -0,0 +1,378 @@
+// Copyright (c) 2015-2025 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package services
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"sync"
+	"time"
+
+	pb "decred.org/dcrwallet/v4/rpc/walletrpc"
+	"github.com/decred/dcrd/txscript/v4/stdscript"
+	"github.com/decred/dcrd/wire"
+
+	"decred-pulse-backend/rpc"
+	"decred-pulse-backend/utils"
+)
+
+const (
+	// minMixPeers is the smallest number of mixed outputs (and inputs)
+	// accepted as a mix. Mixes with fewer peers offer no anonymity set.
+	minMixPeers = 3
+
+	// coinJoinCacheVersion is bumped whenever the classifier changes so stale
+	// classifications are discarded on load
+	coinJoinCacheVersion = 2
+
+	// coinJoinPersistInterval is the minimum delay between cache writes
+	coinJoinPersistInterval = 10 * time.Second
+
+	// coinJoinPrewarmTimeout bounds a single pre-warm pass
+	coinJoinPrewarmTimeout = 10 * time.Minute
+)
+
+// coinJoinCacheFile is the persisted classification cache. A txid commits to
+// the inputs and outputs the classifier looks at, so a classification never
+// changes once computed.
+type coinJoinCacheFile struct {
+	Version       int             `json:"version"`
+	ScannedHeight int32           `json:"scannedHeight"` // Wallet history is classified up to this height
+	Transactions  map[string]bool `json:"transactions"`
+}
+
+var (
+	coinJoinMutex       sync.Mutex
+	coinJoinCache       = make(map[string]bool)
+	coinJoinScanned     int32
+	coinJoinPath        string
+	coinJoinDirty       bool
+	coinJoinLastPersist time.Time
+
+	// Serializes cache file writes
+	coinJoinPersistMutex sync.Mutex
+
+	coinJoinPrewarmMutex   sync.Mutex
+	coinJoinPrewarming     bool
+	coinJoinPrewarmPending bool
+)
+
+// mixDenominations are the output values created by dcrwallet's StakeShuffle
+// mixing (CSPP and the mixpool): powers of two from 2^18 to 2^36 atoms
+var mixDenominations = func() map[int64]bool {
+	denominations := make(map[int64]bool)
+	for shift := 18; shift <= 36; shift += 2 {
+		denominations[1<<shift] = true
+	}
+	return denominations
+}()
+
+// mixOutput is the part of a transaction output the mix classifier looks at
+type mixOutput struct {
+	value int64
+	p2pkh bool // Version 0 pay-to-pubkey-hash script
+}
+
+// mixClassification describes the mixed outputs of a transaction
+type mixClassification struct {
+	mixed        bool
+	denomination int64 // Atoms per mixed output
+	mixedOutputs int
+}
+
+// classifyMix recognizes StakeShuffle mix transactions, shared by wallet
+// history and mempool and chain analysis. Every peer of a mix receives one or
+// more P2PKH outputs of the same standard denomination and at most one P2PKH
+// change output, and contributes at least one input. Equal outputs of other
+// values (ticket splits, batch payouts) are not mixes.
+func classifyMix(numInputs int, outputs []mixOutput) mixClassification {
+	if numInputs < minMixPeers || len(outputs) < minMixPeers {
+		return mixClassification{}
+	}
+
+	counts := make(map[int64]int)
+	for _, out := range outputs {
+		if !out.p2pkh {
+			return mixClassification{}
+		}
+		if mixDenominations[out.value] {
+			counts[out.value]++
+		}
+	}
+
+	var result mixClassification
+	for value, count := range counts {
+		if count > result.mixedOutputs {
+			result.denomination = value
+			result.mixedOutputs = count
+		}
+	}
+	if result.mixedOutputs < minMixPeers {
+		return mixClassification{}
+	}
+
+	// Remaining outputs are change, one per peer at most
+	if len(outputs)-result.mixedOutputs > numInputs {
+		return mixClassification{}
+	}
+
+	result.mixed = true
+	return result
+}
+
+// classifyMixMsgTx applies the mix classifier to a decoded transaction
+func classifyMixMsgTx(tx *wire.MsgTx) mixClassification {
+	outputs := make([]mixOutput, len(tx.TxOut))
+	for i, out := range tx.TxOut {
+		outputs[i] = mixOutput{
+			value: out.Value,
+			p2pkh: out.Version == 0 && stdscript.IsPubKeyHashScriptV0(out.PkScript),
+		}
+	}
+	return classifyMix(len(tx.TxIn), outputs)
+}
+
+// isCoinJoinMsgTx reports whether a decoded transaction is a mix
+func isCoinJoinMsgTx(tx *wire.MsgTx) bool {
+	return classifyMixMsgTx(tx).mixed
+}
+
+// InitCoinJoinCache loads the persisted classification cache from path and
+// enables persistence
+func InitCoinJoinCache(path string) error {
+	coinJoinMutex.Lock()
+	defer coinJoinMutex.Unlock()
+
+	coinJoinPath = path
+
+	var stored coinJoinCacheFile
+	found, err := utils.ReadJSONFile(path, &stored)
+	if err != nil {
+		return err
+	}
+	if !found {
+		return nil
+	}
+	if stored.Version != coinJoinCacheVersion {
+		log.Printf("Discarding CoinJoin cache from an older classifier")
+		return nil
+	}
+	if stored.Transactions != nil {
+		coinJoinCache = stored.Transactions
+	}
+	coinJoinScanned = stored.ScannedHeight
+	log.Printf("Loaded %d CoinJoin classifications from %s", len(coinJoinCache), path)
+	return nil
+}
+
+// classifyCoinJoinTx classifies a decoded transaction and caches the result
+func classifyCoinJoinTx(txid string, tx *wire.MsgTx) bool {
+	coinJoinMutex.Lock()
+	mixed, ok := coinJoinCache[txid]
+	if !ok {
+		mixed = isCoinJoinMsgTx(tx)
+		coinJoinCache[txid] = mixed
+		coinJoinDirty = true
+	}
+	coinJoinMutex.Unlock()
+
+	if !ok {
+		persistCoinJoinCache(false)
+	}
+	return mixed
+}
+
+// isCoinJoinTransaction reports whether a wallet transaction is a CoinJoin,
+// fetching and classifying it only when it is not cached yet
+func isCoinJoinTransaction(ctx context.Context, txid string) bool {
+	coinJoinMutex.Lock()
+	mixed, ok := coinJoinCache[txid]
+	coinJoinMutex.Unlock()
+	if ok {
+		return mixed
+	}
+
+	tx, err := fetchMsgTx(ctx, txid)
+	if err != nil {
+		log.Printf("Warning: CoinJoin check failed for %s: %v", txid, err)
+		return false
+	}
+	return classifyCoinJoinTx(txid, tx)
+}
+
+// fetchMsgTx loads a transaction from the wallet, falling back to dcrd for
+// transactions the wallet does not store
+func fetchMsgTx(ctx context.Context, txid string) (*wire.MsgTx, error) {
+	params, err := marshalParams(txid)
+	if err != nil {
+		return nil, err
+	}
+
+	var txHex string
+	if rpc.WalletClient != nil {
+		if result, err := rpc.WalletClient.RawRequest(ctx, "gettransaction", params); err == nil {
+			var walletTx struct {
+				Hex string `json:"hex"`
+			}
+			if err := json.Unmarshal(result, &walletTx); err == nil {
+				txHex = walletTx.Hex
+			}
+		}
+	}
+	if txHex == "" {
+		if rpc.DcrdClient == nil {
+			return nil, fmt.Errorf("transaction not in wallet and no dcrd connection")
+		}
+		result, err := rpc.DcrdClient.RawRequest(ctx, "getrawtransaction", params)
+		if err != nil {
+			return nil, fmt.Errorf("getrawtransaction: %w", err)
+		}
+		if err := json.Unmarshal(result, &txHex); err != nil {
+			return nil, fmt.Errorf("invalid getrawtransaction result: %w", err)
+		}
+	}
+
+	raw, err := hex.DecodeString(txHex)
+	if err != nil {
+		return nil, fmt.Errorf("invalid transaction hex: %w", err)
+	}
+	tx := new(wire.MsgTx)
+	if err := tx.FromBytes(raw); err != nil {
+		return nil, fmt.Errorf("failed to decode transaction: %w", err)
+	}
+	return tx, nil
+}
+
+// persistCoinJoinCache writes the cache if it changed, at most once per
+// coinJoinPersistInterval unless forced
+func persistCoinJoinCache(force bool) {
+	coinJoinMutex.Lock()
+	if coinJoinPath == "" || !coinJoinDirty ||
+		(!force && time.Since(coinJoinLastPersist) < coinJoinPersistInterval) {
+		coinJoinMutex.Unlock()
+		return
+	}
+	snapshot := coinJoinCacheFile{
+		Version:       coinJoinCacheVersion,
+		ScannedHeight: coinJoinScanned,
+		Transactions:  make(map[string]bool, len(coinJoinCache)),
+	}
+	for txid, mixed := range coinJoinCache {
+		snapshot.Transactions[txid] = mixed
+	}
+	path := coinJoinPath
+	coinJoinDirty = false
+	coinJoinLastPersist = time.Now()
+	coinJoinMutex.Unlock()
+
+	coinJoinPersistMutex.Lock()
+	defer coinJoinPersistMutex.Unlock()
+	if err := utils.WriteJSONFile(path, snapshot); err != nil {
+		log.Printf("Warning: Failed to persist CoinJoin cache: %v", err)
+	}
+}
+
+// TriggerCoinJoinPrewarm classifies wallet transactions mined since the last
+// pass in the background. The first pass covers the whole history. Triggers
+// while a pass is running schedule one more pass.
+func TriggerCoinJoinPrewarm() {
+	coinJoinPrewarmMutex.Lock()
+	defer coinJoinPrewarmMutex.Unlock()
+
+	if coinJoinPrewarming {
+		coinJoinPrewarmPending = true
+		return
+	}
+	coinJoinPrewarming = true
+	go runCoinJoinPrewarm()
+}
+
+func runCoinJoinPrewarm() {
+	for {
+		ctx, cancel := context.WithTimeout(context.Background(), coinJoinPrewarmTimeout)
+		if err := prewarmCoinJoinCache(ctx); err != nil {
+			log.Printf("Warning: CoinJoin cache pre-warm failed: %v", err)
+		}
+		cancel()
+
+		coinJoinPrewarmMutex.Lock()
+		if !coinJoinPrewarmPending {
+			coinJoinPrewarming = false
+			coinJoinPrewarmMutex.Unlock()
+			return
+		}
+		coinJoinPrewarmPending = false
+		coinJoinPrewarmMutex.Unlock()
+	}
+}
+
+// prewarmCoinJoinCache walks the wallet's mined transactions above the last
+// scanned height with the gRPC GetTransactions stream, which carries the
+// serialized transactions, so no per-transaction lookups are needed
+func prewarmCoinJoinCache(ctx context.Context) error {
+	if rpc.WalletGrpcClient == nil {
+		return fmt.Errorf("wallet gRPC client not initialized")
+	}
+
+	coinJoinMutex.Lock()
+	start := coinJoinScanned + 1
+	coinJoinMutex.Unlock()
+
+	stream, err := rpc.WalletGrpcClient.GetTransactions(ctx, &pb.GetTransactionsRequest{
+		StartingBlockHeight: start,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get transactions: %w", err)
+	}
+
+	classified := 0
+	var lastHeight int32
+	for {
+		resp, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to receive transactions: %w", err)
+		}
+		block := resp.MinedTransactions
+		if block == nil {
+			continue
+		}
+
+		for _, details := range block.Transactions {
+			if details.TransactionType != pb.TransactionDetails_REGULAR {
+				continue
+			}
+			var tx wire.MsgTx
+			if err := tx.FromBytes(details.Transaction); err != nil {
+				continue
+			}
+			classifyCoinJoinTx(hashString(details.Hash), &tx)
+			classified++
+		}
+		lastHeight = block.Height
+	}
+
+	if lastHeight > 0 {
+		coinJoinMutex.Lock()
+		if lastHeight > coinJoinScanned {
+			coinJoinScanned = lastHeight
+			coinJoinDirty = true
+		}
+		coinJoinMutex.Unlock()
+	}
+	persistCoinJoinCache(true)
+
+	if classified > 0 {
+		log.Printf("CoinJoin cache pre-warmed with %d transactions up to height %d", classified, lastHeight)
+	}
+	return nil
+}
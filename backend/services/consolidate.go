@@ -0,0 +1,436 @@
+// Copyright (c) 2015-2025 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package services
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+
+	pb "decred.org/dcrwallet/v4/rpc/walletrpc"
+	"decred.org/dcrwallet/v4/wallet/txrules"
+	"decred.org/dcrwallet/v4/wallet/txsizes"
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/dcrutil/v4"
+	"github.com/decred/dcrd/wire"
+
+	"decred-pulse-backend/jobs"
+	"decred-pulse-backend/rpc"
+	"decred-pulse-backend/types"
+)
+
+const (
+	// maxConsolidationTxSize is dcrd's largest standard transaction
+	maxConsolidationTxSize = 100000
+
+	// minConsolidationTxSize leaves room for a few inputs
+	minConsolidationTxSize = 1000
+)
+
+var (
+	// ErrInvalidConsolidation is returned for invalid consolidation parameters
+	ErrInvalidConsolidation = errors.New("invalid consolidation")
+
+	// ErrNothingToConsolidate is returned when no batch has two or more inputs
+	ErrNothingToConsolidate = errors.New("nothing to consolidate")
+
+	// ErrConsolidationRunning is returned when a consolidation job is active
+	ErrConsolidationRunning = errors.New("a consolidation is already running")
+)
+
+var (
+	consolidationMutex sync.Mutex
+	consolidationJobID string
+)
+
+// consolidationCandidate is an unspent output eligible for consolidation
+type consolidationCandidate struct {
+	outpoint types.Outpoint
+	atoms    int64
+}
+
+// PlanConsolidation proposes batched self-sends that merge an account's small
+// outputs. Mixed and unmixed outputs never share a transaction, each
+// transaction stays under the size limit, and batches stop at the fee budget.
+func PlanConsolidation(ctx context.Context, req types.ConsolidationRequest) (*types.ConsolidationPlan, error) {
+	account := req.Account
+	if account == "" {
+		account = "default"
+	}
+	destination := req.DestinationAccount
+	if destination == "" {
+		destination = account
+	}
+	maxTxSize := req.MaxTxSize
+	if maxTxSize == 0 {
+		maxTxSize = maxConsolidationTxSize
+	}
+	if maxTxSize < minConsolidationTxSize || maxTxSize > maxConsolidationTxSize {
+		return nil, fmt.Errorf("%w: max transaction size must be between %d and %d bytes",
+			ErrInvalidConsolidation, minConsolidationTxSize, maxConsolidationTxSize)
+	}
+	minConf := req.MinConf
+	if minConf == 0 {
+		minConf = defaultSendConfirmations
+	}
+	if minConf < 0 {
+		return nil, fmt.Errorf("%w: minConf must not be negative", ErrInvalidConsolidation)
+	}
+	if req.FeeRate < 0 || req.FutureFeeRate < 0 || req.FeeBudget < 0 || req.MaxAmount < 0 {
+		return nil, fmt.Errorf("%w: amounts and fee rates must not be negative", ErrInvalidConsolidation)
+	}
+
+	// Unmixed funds enter the mixed account only through the mixer
+	mixer := FetchMixerStatus()
+	if destination == mixer.MixedAccount && account != mixer.MixedAccount {
+		return nil, fmt.Errorf("%w: only the mixer may move funds into the mixed account %q",
+			ErrInvalidConsolidation, mixer.MixedAccount)
+	}
+
+	feePerKb, err := dcrutil.NewAmount(req.FeeRate)
+	if err != nil {
+		return nil, fmt.Errorf("%w: fee rate: %v", ErrInvalidConsolidation, err)
+	}
+	if feePerKb == 0 {
+		if feePerKb, err = fetchRelayFee(ctx); err != nil {
+			return nil, err
+		}
+	}
+	futureFeePerKb := feePerKb
+	if req.FutureFeeRate > 0 {
+		if futureFeePerKb, err = dcrutil.NewAmount(req.FutureFeeRate); err != nil {
+			return nil, fmt.Errorf("%w: future fee rate: %v", ErrInvalidConsolidation, err)
+		}
+	}
+	maxAmount, err := dcrutil.NewAmount(req.MaxAmount)
+	if err != nil {
+		return nil, fmt.Errorf("%w: max amount: %v", ErrInvalidConsolidation, err)
+	}
+	feeBudget, err := dcrutil.NewAmount(req.FeeBudget)
+	if err != nil {
+		return nil, fmt.Errorf("%w: fee budget: %v", ErrInvalidConsolidation, err)
+	}
+
+	// listunspent leaves out locked outputs, including offline transaction inputs
+	relockOfflineInputs(ctx)
+	unspent, err := fetchUnspent(ctx, minConf, account)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &types.ConsolidationPlan{
+		Account:            account,
+		DestinationAccount: destination,
+		FeeRate:            feePerKb.ToCoin(),
+		FutureFeeRate:      futureFeePerKb.ToCoin(),
+		Batches:            []types.ConsolidationBatch{},
+	}
+
+	spendCost := inputSpendCost(feePerKb)
+	var unmixed, mixed []consolidationCandidate
+	spendable := 0
+	for _, out := range unspent {
+		if !out.Spendable {
+			continue
+		}
+		spendable++
+		atoms := toAtoms(out.Amount)
+		isMixed := isMixedOutput(ctx, out.TxID, out.Tree, atoms)
+		switch {
+		case atoms <= spendCost:
+			plan.SkippedDust++
+		case maxAmount > 0 && atoms > int64(maxAmount):
+			plan.SkippedLarge++
+		case isMixed && !req.IncludeMixed:
+			plan.SkippedMixed++
+		default:
+			c := consolidationCandidate{
+				outpoint: types.Outpoint{TxID: out.TxID, Vout: out.Vout, Tree: out.Tree},
+				atoms:    atoms,
+			}
+			if isMixed {
+				mixed = append(mixed, c)
+			} else {
+				unmixed = append(unmixed, c)
+			}
+		}
+	}
+
+	maxInputs := maxConsolidationInputs(maxTxSize)
+	var totalInput, totalFee int64
+	for _, group := range []struct {
+		outputs []consolidationCandidate
+		mixed   bool
+	}{{unmixed, false}, {mixed, true}} {
+		// Smallest outputs first: they cost the most to spend relative to value
+		sort.Slice(group.outputs, func(i, j int) bool {
+			return group.outputs[i].atoms < group.outputs[j].atoms
+		})
+		for _, batch := range splitBatches(group.outputs, maxInputs) {
+			b := planBatch(batch, group.mixed, feePerKb)
+			// The fee also pays for the transaction overhead and the output,
+			// so a batch of near-dust inputs can merge into dust
+			if output := toAtoms(b.Output); output <= 0 ||
+				txrules.IsDustAmount(dcrutil.Amount(output), txsizes.P2PKHPkScriptSize, feePerKb) {
+				plan.SkippedDust += len(batch)
+				continue
+			}
+			fee := toAtoms(b.Fee)
+			if feeBudget > 0 && totalFee+fee > int64(feeBudget) {
+				plan.SkippedBudget += len(batch)
+				continue
+			}
+			plan.Batches = append(plan.Batches, b)
+			plan.InputCount += b.InputCount
+			totalInput += toAtoms(b.TotalInput)
+			totalFee += fee
+		}
+	}
+
+	// Spending every output of the account in one transaction is a sweep
+	plan.Sweep = len(plan.Batches) == 1 && plan.InputCount == spendable
+
+	// Without consolidation every input is spent later; with it, the fees are
+	// paid now and only one input per batch is spent later
+	inputSize := int64(txsizes.EstimateInputSize(txsizes.RedeemP2PKHSigScriptSize))
+	avoided := int64(plan.InputCount-len(plan.Batches)) * inputSize * int64(futureFeePerKb) / 1000
+	plan.TotalInput = dcrutil.Amount(totalInput).ToCoin()
+	plan.TotalFee = dcrutil.Amount(totalFee).ToCoin()
+	plan.FeesSaved = dcrutil.Amount(avoided - totalFee).ToCoin()
+
+	if len(mixed) > 0 {
+		plan.Warnings = append(plan.Warnings, "Consolidating mixed outputs links them on chain, and the merged output is no longer a mix denomination")
+	}
+	if len(plan.Batches) > 0 && plan.FeesSaved < 0 {
+		plan.Warnings = append(plan.Warnings, "At these fee rates consolidation costs more than it saves on later spends")
+	}
+	if plan.SkippedBudget > 0 {
+		plan.Warnings = append(plan.Warnings, fmt.Sprintf("%d outputs are left for a later run by the fee budget", plan.SkippedBudget))
+	}
+	return plan, nil
+}
+
+// maxConsolidationInputs is the number of P2PKH inputs that fit a transaction
+// of maxTxSize bytes with a single P2PKH output
+func maxConsolidationInputs(maxTxSize int) int {
+	output := []*wire.TxOut{{PkScript: make([]byte, txsizes.P2PKHPkScriptSize)}}
+	n := 1
+	for {
+		scriptSizes := make([]int, n+1)
+		for i := range scriptSizes {
+			scriptSizes[i] = txsizes.RedeemP2PKHSigScriptSize
+		}
+		if txsizes.EstimateSerializeSize(scriptSizes, output, 0) > maxTxSize {
+			return n
+		}
+		n++
+	}
+}
+
+// splitBatches splits outputs into the fewest batches of at most maxInputs,
+// balanced so the last batch is not left with a single input. Batches with
+// fewer than two inputs are dropped.
+func splitBatches(outputs []consolidationCandidate, maxInputs int) [][]consolidationCandidate {
+	if len(outputs) < 2 {
+		return nil
+	}
+	count := (len(outputs) + maxInputs - 1) / maxInputs
+	batches := make([][]consolidationCandidate, 0, count)
+	start := 0
+	for i := 0; i < count; i++ {
+		end := start + (len(outputs)-start)/(count-i)
+		batches = append(batches, outputs[start:end])
+		start = end
+	}
+	return batches
+}
+
+// planBatch computes the size, fee and output of one consolidation transaction
+func planBatch(inputs []consolidationCandidate, mixed bool, feePerKb dcrutil.Amount) types.ConsolidationBatch {
+	scriptSizes := make([]int, len(inputs))
+	outpoints := make([]types.Outpoint, len(inputs))
+	var total int64
+	for i, in := range inputs {
+		scriptSizes[i] = txsizes.RedeemP2PKHSigScriptSize
+		outpoints[i] = in.outpoint
+		total += in.atoms
+	}
+	output := []*wire.TxOut{{PkScript: make([]byte, txsizes.P2PKHPkScriptSize)}}
+	size := txsizes.EstimateSerializeSize(scriptSizes, output, 0)
+	fee := int64(txrules.FeeForSerializeSize(feePerKb, size))
+
+	return types.ConsolidationBatch{
+		Inputs:        outpoints,
+		InputCount:    len(inputs),
+		Mixed:         mixed,
+		TotalInput:    dcrutil.Amount(total).ToCoin(),
+		Output:        dcrutil.Amount(total - fee).ToCoin(),
+		Fee:           dcrutil.Amount(fee).ToCoin(),
+		EstimatedSize: size,
+	}
+}
+
+// StartConsolidation plans a consolidation and executes it as a tracked job.
+// Each batch is built, signed with the passphrase (or an unlocked wallet) and
+// published in turn; a sweep plan is built with sweepaccount instead.
+func StartConsolidation(ctx context.Context, req types.ConsolidationRequest) (*types.ConsolidationPlan, types.Job, error) {
+	if rpc.WalletGrpcClient == nil {
+		return nil, types.Job{}, fmt.Errorf("wallet gRPC client not initialized")
+	}
+
+	consolidationMutex.Lock()
+	running := consolidationJobID != ""
+	consolidationMutex.Unlock()
+	if running {
+		return nil, types.Job{}, ErrConsolidationRunning
+	}
+
+	plan, err := PlanConsolidation(ctx, req)
+	if err != nil {
+		return nil, types.Job{}, err
+	}
+	if len(plan.Batches) == 0 {
+		return nil, types.Job{}, ErrNothingToConsolidate
+	}
+	minConf := req.MinConf
+	if minConf == 0 {
+		minConf = defaultSendConfirmations
+	}
+
+	consolidationMutex.Lock()
+	if consolidationJobID != "" {
+		consolidationMutex.Unlock()
+		return nil, types.Job{}, ErrConsolidationRunning
+	}
+	steps := []string{"Build transactions", "Sign and publish transactions"}
+	job := jobs.Start("utxo_consolidation", steps, func(ctx context.Context, job *jobs.Handle) (interface{}, error) {
+		defer func() {
+			consolidationMutex.Lock()
+			consolidationJobID = ""
+			consolidationMutex.Unlock()
+		}()
+		return runConsolidation(ctx, job, *plan, minConf, req.Passphrase)
+	})
+	consolidationJobID = job.ID
+	consolidationMutex.Unlock()
+
+	log.Printf("Consolidation of %d outputs in %d transactions started (job %s)", plan.InputCount, len(plan.Batches), job.ID)
+	return plan, job, nil
+}
+
+// runConsolidation builds every batch, then signs and publishes them in turn.
+// Published transactions stay published if a later batch fails.
+func runConsolidation(ctx context.Context, job *jobs.Handle, plan types.ConsolidationPlan, minConf int64, passphrase string) (*types.ConsolidationResult, error) {
+	result := &types.ConsolidationResult{Plan: plan, TxIDs: []string{}}
+
+	job.BeginStep(0)
+	relockOfflineInputs(ctx)
+	// Outputs spent since planning fail their batch rather than the signing
+	unspent, err := fetchUnspent(ctx, minConf, plan.Account)
+	if err != nil {
+		return result, err
+	}
+	amounts := make(map[types.Outpoint]int64, len(unspent))
+	for _, out := range unspent {
+		amounts[types.Outpoint{TxID: out.TxID, Vout: out.Vout, Tree: out.Tree}] = toAtoms(out.Amount)
+	}
+
+	unsigned := make([][]byte, 0, len(plan.Batches))
+	for i, batch := range plan.Batches {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		address, err := GenerateAddress(ctx, types.NewAddressRequest{Account: plan.DestinationAccount, Branch: branchInternal})
+		if err != nil {
+			return result, err
+		}
+		var tx []byte
+		if plan.Sweep {
+			tx, err = buildSweepTransaction(ctx, plan.Account, address.Address, minConf, plan.FeeRate)
+		} else {
+			tx, err = buildConsolidationTransaction(ctx, batch, address.Address, amounts)
+		}
+		if err != nil {
+			return result, fmt.Errorf("batch %d: %w", i+1, err)
+		}
+		unsigned = append(unsigned, tx)
+		job.SetProgress(float64(i+1)/float64(len(plan.Batches))*100, fmt.Sprintf("Built %d of %d transactions", i+1, len(plan.Batches)))
+	}
+
+	job.BeginStep(1)
+	for i, tx := range unsigned {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+		signed, err := signTransaction(ctx, tx, passphrase)
+		if err != nil {
+			return result, fmt.Errorf("batch %d: %w", i+1, err)
+		}
+		resp, err := rpc.WalletGrpcClient.PublishTransaction(ctx, &pb.PublishTransactionRequest{SignedTransaction: signed})
+		if err != nil {
+			return result, fmt.Errorf("batch %d: failed to publish transaction: %w", i+1, err)
+		}
+		txid := hashString(resp.TransactionHash)
+		result.TxIDs = append(result.TxIDs, txid)
+		result.Published++
+		job.Logf("Published %s consolidating %d outputs", txid, plan.Batches[i].InputCount)
+		job.SetProgress(float64(i+1)/float64(len(unsigned))*100, fmt.Sprintf("Published %d of %d transactions", i+1, len(unsigned)))
+	}
+
+	log.Printf("Consolidated %d outputs of account %q in %d transactions", plan.InputCount, plan.Account, result.Published)
+	return result, nil
+}
+
+// buildConsolidationTransaction spends a batch's inputs to a single output
+// paying address, with the planned fee. amounts holds the unspent outputs.
+func buildConsolidationTransaction(ctx context.Context, batch types.ConsolidationBatch, address string, amounts map[types.Outpoint]int64) ([]byte, error) {
+	addr, err := DecodeAddress(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+
+	tx := wire.NewMsgTx()
+	for _, op := range batch.Inputs {
+		atoms, ok := amounts[op]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s:%d is no longer unspent", ErrInvalidOutpoint, op.TxID, op.Vout)
+		}
+		hash, err := chainhash.NewHashFromStr(op.TxID)
+		if err != nil {
+			return nil, fmt.Errorf("%w: txid %q", ErrInvalidOutpoint, op.TxID)
+		}
+		tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(hash, op.Vout, op.Tree), atoms, nil))
+	}
+	scriptVersion, pkScript := addr.PaymentScript()
+	tx.AddTxOut(&wire.TxOut{Value: toAtoms(batch.Output), Version: scriptVersion, PkScript: pkScript})
+
+	return tx.Bytes()
+}
+
+// buildSweepTransaction builds a transaction moving every spendable output of
+// account to address with sweepaccount
+func buildSweepTransaction(ctx context.Context, account, address string, minConf int64, feeRate float64) ([]byte, error) {
+	params, err := marshalParams(account, address, minConf, feeRate)
+	if err != nil {
+		return nil, err
+	}
+	result, err := rpc.WalletClient.RawRequest(ctx, "sweepaccount", params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sweep account: %w", err)
+	}
+
+	var sweep struct {
+		UnsignedTransaction string `json:"unsignedtransaction"`
+	}
+	if err := json.Unmarshal(result, &sweep); err != nil {
+		return nil, fmt.Errorf("invalid sweepaccount result: %w", err)
+	}
+	return hex.DecodeString(sweep.UnsignedTransaction)
+}
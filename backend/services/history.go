@@ -0,0 +1,366 @@
+// Copyright (c) 2015-2025 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	pb "decred.org/dcrwallet/v4/rpc/walletrpc"
+	"github.com/decred/dcrd/dcrutil/v4"
+	"github.com/decred/dcrd/wire"
+
+	"decred-pulse-backend/pricing"
+	"decred-pulse-backend/rpc"
+	"decred-pulse-backend/types"
+)
+
+const (
+	// DefaultHistoryLimit and MaxHistoryLimit bound a history page
+	DefaultHistoryLimit = 50
+	MaxHistoryLimit     = 500
+
+	// historyTimestampSlack allows for block timestamps that are not strictly
+	// increasing with height when stopping at the start of a date range
+	historyTimestampSlack = 2 * 60 * 60
+)
+
+// HistoryFilter selects transactions for FetchHistory. Empty fields match everything.
+type HistoryFilter struct {
+	Cursor     string
+	Limit      int
+	Account    string
+	TxTypes    []string
+	Categories []string
+	Since      time.Time
+	Until      time.Time
+	MinAmount  *float64 // Compared with the absolute net amount
+	MaxAmount  *float64
+	Mixed      *bool
+}
+
+// historyCursor is a position in the mined history: a block height and the
+// index of a transaction within the wallet's transactions of that block
+type historyCursor struct {
+	height int32
+	index  int
+}
+
+// parseHistoryCursor parses a "height:index" cursor
+func parseHistoryCursor(s string) (historyCursor, error) {
+	heightStr, indexStr, ok := strings.Cut(s, ":")
+	if !ok {
+		return historyCursor{}, fmt.Errorf("invalid cursor %q", s)
+	}
+	height, err := strconv.ParseInt(heightStr, 10, 32)
+	if err != nil || height <= 0 {
+		return historyCursor{}, fmt.Errorf("invalid cursor %q", s)
+	}
+	index, err := strconv.Atoi(indexStr)
+	if err != nil || index < 0 {
+		return historyCursor{}, fmt.Errorf("invalid cursor %q", s)
+	}
+	return historyCursor{height: int32(height), index: index}, nil
+}
+
+func (c historyCursor) String() string {
+	return fmt.Sprintf("%d:%d", c.height, c.index)
+}
+
+// FetchHistory returns one page of the complete wallet history, newest first.
+// Pages are addressed by a block height and index cursor, so they stay stable
+// when new transactions arrive. Unmined transactions are returned separately
+// on the first page.
+func FetchHistory(ctx context.Context, filter HistoryFilter) (*types.HistoryResponse, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = DefaultHistoryLimit
+	}
+	if filter.Limit > MaxHistoryLimit {
+		filter.Limit = MaxHistoryLimit
+	}
+
+	var cursor *historyCursor
+	if filter.Cursor != "" {
+		c, err := parseHistoryCursor(filter.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		cursor = &c
+	}
+
+	if err := refreshAccountNames(ctx); err != nil {
+		log.Printf("Warning: Could not load account names: %v", err)
+	}
+
+	_, tipHeight, err := rpc.WalletClient.GetBestBlock(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get best block: %w", err)
+	}
+
+	response := &types.HistoryResponse{
+		Transactions: make([]types.HistoryEntry, 0, filter.Limit),
+	}
+
+	if cursor == nil {
+		unmined, err := fetchUnminedHistory(ctx, int32(tipHeight), filter)
+		if err != nil {
+			return nil, err
+		}
+		response.Unmined = unmined
+	}
+
+	startHeight := int32(tipHeight)
+	if cursor != nil {
+		startHeight = cursor.height
+	}
+	if startHeight < 1 {
+		return response, nil
+	}
+
+	streamCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// Iterating from a higher to a lower height walks the chain backwards
+	stream, err := rpc.WalletGrpcClient.GetTransactions(streamCtx, &pb.GetTransactionsRequest{
+		StartingBlockHeight: startHeight,
+		EndingBlockHeight:   1,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transactions: %w", err)
+	}
+
+	for {
+		resp, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to receive transactions: %w", err)
+		}
+
+		block := resp.MinedTransactions
+		if block == nil {
+			continue
+		}
+		if !filter.Since.IsZero() && block.Timestamp < filter.Since.Unix()-historyTimestampSlack {
+			break
+		}
+
+		done := false
+		// Walk the block's transactions backwards to keep newest-first order
+		for i := len(block.Transactions) - 1; i >= 0; i-- {
+			if cursor != nil && block.Height == cursor.height && i >= cursor.index {
+				continue
+			}
+			if !filter.matchesTime(block.Timestamp) {
+				continue
+			}
+
+			entry := convertHistoryEntry(block.Transactions[i])
+			entry.BlockHeight = block.Height
+			entry.BlockIndex = i
+			entry.BlockHash = hashString(block.Hash)
+			entry.BlockTime = block.Timestamp
+			entry.Confirmations = tipHeight - int64(block.Height) + 1
+			entry.Cursor = historyCursor{height: block.Height, index: i}.String()
+			entry.Fiat = pricing.ConvertAt(entry.Amount, time.Unix(block.Timestamp, 0))
+			if !filter.matches(entry) {
+				continue
+			}
+
+			if len(response.Transactions) == filter.Limit {
+				response.HasMore = true
+				done = true
+				break
+			}
+			response.Transactions = append(response.Transactions, entry)
+		}
+		if done {
+			break
+		}
+	}
+
+	if response.HasMore {
+		response.NextCursor = response.Transactions[len(response.Transactions)-1].Cursor
+	}
+	return response, nil
+}
+
+// fetchUnminedHistory returns the unmined transactions matching filter. Starting
+// past the tip skips all mined blocks and only streams the unmined set.
+func fetchUnminedHistory(ctx context.Context, tipHeight int32, filter HistoryFilter) ([]types.HistoryEntry, error) {
+	stream, err := rpc.WalletGrpcClient.GetTransactions(ctx, &pb.GetTransactionsRequest{
+		StartingBlockHeight: tipHeight + 1,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get unmined transactions: %w", err)
+	}
+
+	entries := make([]types.HistoryEntry, 0)
+	for {
+		resp, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to receive unmined transactions: %w", err)
+		}
+
+		// A block attached since the tip was read belongs to the mined history
+		for _, details := range resp.UnminedTransactions {
+			entry := convertHistoryEntry(details)
+			entry.BlockHeight = -1
+			entry.BlockIndex = -1
+			entry.Fiat = pricing.Convert(entry.Amount)
+			if filter.matchesTime(details.Timestamp) && filter.matches(entry) {
+				entries = append(entries, entry)
+			}
+		}
+	}
+	return entries, nil
+}
+
+// convertHistoryEntry turns gRPC transaction details into a history record with
+// the wallet's debits and credits broken out
+func convertHistoryEntry(details *pb.TransactionDetails) types.HistoryEntry {
+	entry := types.HistoryEntry{
+		TxID:     hashString(details.Hash),
+		Time:     time.Unix(details.Timestamp, 0),
+		TxType:   transactionTypeName(details.TransactionType),
+		Accounts: make([]string, 0),
+		Debits:   make([]types.HistoryDebit, 0, len(details.Debits)),
+		Credits:  make([]types.HistoryCredit, 0, len(details.Credits)),
+	}
+
+	seenAccounts := make(map[string]bool)
+	addAccount := func(name string) {
+		if !seenAccounts[name] {
+			seenAccounts[name] = true
+			entry.Accounts = append(entry.Accounts, name)
+		}
+	}
+
+	var debited, credited int64
+	for _, debit := range details.Debits {
+		name := accountName(debit.PreviousAccount)
+		addAccount(name)
+		debited += debit.PreviousAmount
+		entry.Debits = append(entry.Debits, types.HistoryDebit{
+			Index:   debit.Index,
+			Account: name,
+			Amount:  dcrutil.Amount(debit.PreviousAmount).ToCoin(),
+		})
+	}
+	for _, credit := range details.Credits {
+		name := accountName(credit.Account)
+		addAccount(name)
+		credited += credit.Amount
+		entry.Credits = append(entry.Credits, types.HistoryCredit{
+			Index:    credit.Index,
+			Account:  name,
+			Address:  credit.Address,
+			Amount:   dcrutil.Amount(credit.Amount).ToCoin(),
+			Internal: credit.Internal,
+		})
+	}
+
+	net := credited - debited
+	entry.Amount = dcrutil.Amount(net).ToCoin()
+	if debited > 0 {
+		entry.Fee = dcrutil.Amount(details.Fee).ToCoin()
+	}
+
+	var tx *wire.MsgTx
+	if len(details.Transaction) > 0 {
+		tx = new(wire.MsgTx)
+		if err := tx.FromBytes(details.Transaction); err != nil {
+			log.Printf("Warning: Could not decode transaction %s: %v", entry.TxID, err)
+			tx = nil
+		}
+	}
+
+	switch {
+	case details.TransactionType == pb.TransactionDetails_COINBASE:
+		entry.Category = "generate"
+	case details.TransactionType == pb.TransactionDetails_REGULAR && debited > 0 &&
+		tx != nil && len(details.Credits) == len(tx.TxOut):
+		// Every output pays back to the wallet, only the fee left it
+		entry.Category = "transfer"
+	case net < 0:
+		entry.Category = "send"
+	default:
+		entry.Category = "receive"
+	}
+
+	if details.TransactionType == pb.TransactionDetails_REGULAR && tx != nil {
+		entry.IsMixed = classifyCoinJoinTx(entry.TxID, tx)
+	}
+
+	return entry
+}
+
+// matchesTime reports whether a block or receive timestamp is inside the date range
+func (f HistoryFilter) matchesTime(timestamp int64) bool {
+	if !f.Since.IsZero() && timestamp < f.Since.Unix() {
+		return false
+	}
+	if !f.Until.IsZero() && timestamp >= f.Until.Unix() {
+		return false
+	}
+	return true
+}
+
+// matches applies every filter except the date range to a history record
+func (f HistoryFilter) matches(entry types.HistoryEntry) bool {
+	if f.Account != "" {
+		found := false
+		for _, name := range entry.Accounts {
+			if name == f.Account {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(f.TxTypes) > 0 && !containsString(f.TxTypes, entry.TxType) {
+		return false
+	}
+	if len(f.Categories) > 0 && !containsString(f.Categories, entry.Category) {
+		return false
+	}
+
+	amount := entry.Amount
+	if amount < 0 {
+		amount = -amount
+	}
+	if f.MinAmount != nil && amount < *f.MinAmount {
+		return false
+	}
+	if f.MaxAmount != nil && amount > *f.MaxAmount {
+		return false
+	}
+
+	if f.Mixed != nil && entry.IsMixed != *f.Mixed {
+		return false
+	}
+	return true
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
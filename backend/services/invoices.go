@@ -0,0 +1,366 @@
+// Copyright (c) 2015-2025 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/decred/dcrd/dcrutil/v4"
+
+	"decred-pulse-backend/pricing"
+	"decred-pulse-backend/rpc"
+	"decred-pulse-backend/types"
+	"decred-pulse-backend/utils"
+)
+
+const (
+	// defaultInvoiceExpiry applies when a request sets no expiry
+	defaultInvoiceExpiry = time.Hour
+
+	// maxInvoiceExpiry bounds how long an invoice waits for payment
+	maxInvoiceExpiry = 30 * 24 * time.Hour
+
+	// maxInvoiceConfirmations bounds the confirmations an invoice can require
+	maxInvoiceConfirmations = 100
+
+	// invoiceUpdateInterval is how often open invoices are checked without
+	// wallet notifications, e.g. to expire them
+	invoiceUpdateInterval = 30 * time.Second
+
+	invoicesVersion = 1
+)
+
+var (
+	// ErrInvoiceNotFound is returned for unknown invoice IDs
+	ErrInvoiceNotFound = errors.New("invoice not found")
+
+	// ErrInvalidInvoice is returned for invalid invoice parameters
+	ErrInvalidInvoice = errors.New("invalid invoice")
+)
+
+// invoiceStore is the persisted invoice state
+type invoiceStore struct {
+	Version  int                       `json:"version"`
+	Invoices map[string]*types.Invoice `json:"invoices"`
+}
+
+var (
+	invoicesMutex sync.Mutex
+	invoices      = make(map[string]*types.Invoice)
+	invoicesPath  string
+
+	invoiceWatcherOnce sync.Once
+	invoiceUpdates     = make(chan struct{}, 1)
+)
+
+// InitInvoices loads the persisted invoices from path and enables persistence
+func InitInvoices(path string) error {
+	invoicesMutex.Lock()
+	defer invoicesMutex.Unlock()
+
+	invoicesPath = path
+
+	var stored invoiceStore
+	found, err := utils.ReadJSONFile(path, &stored)
+	if err != nil {
+		return err
+	}
+	if !found || stored.Version != invoicesVersion || stored.Invoices == nil {
+		return nil
+	}
+	invoices = stored.Invoices
+	log.Printf("Loaded %d invoices", len(invoices))
+	return nil
+}
+
+// StartInvoiceWatcher keeps open invoices up to date. Updates run on wallet
+// transaction notifications and every invoiceUpdateInterval.
+func StartInvoiceWatcher() {
+	invoiceWatcherOnce.Do(func() {
+		go func() {
+			ticker := time.NewTicker(invoiceUpdateInterval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ticker.C:
+				case <-invoiceUpdates:
+				}
+				if rpc.WalletClient == nil {
+					continue
+				}
+				ctx, cancel := context.WithTimeout(context.Background(), invoiceUpdateInterval)
+				if err := updateInvoices(ctx); err != nil {
+					log.Printf("Warning: Invoice update failed: %v", err)
+				}
+				cancel()
+			}
+		}()
+	})
+}
+
+// notifyInvoiceWatcher schedules an invoice update without blocking
+func notifyInvoiceWatcher() {
+	select {
+	case invoiceUpdates <- struct{}{}:
+	default:
+		// An update is already pending
+	}
+}
+
+// CreateInvoice allocates a fresh receive address for a payment request
+func CreateInvoice(ctx context.Context, req types.InvoiceCreateRequest) (*types.Invoice, error) {
+	if amount, err := dcrutil.NewAmount(req.Amount); err != nil || amount <= 0 || amount > dcrutil.MaxAmount {
+		return nil, fmt.Errorf("%w: amount must be positive and within the DCR supply", ErrInvalidInvoice)
+	}
+	expiry := defaultInvoiceExpiry
+	if req.Expiry < 0 {
+		return nil, fmt.Errorf("%w: expiry must not be negative", ErrInvalidInvoice)
+	}
+	if req.Expiry > 0 {
+		expiry = time.Duration(req.Expiry) * time.Second
+	}
+	if expiry > maxInvoiceExpiry {
+		return nil, fmt.Errorf("%w: expiry must not exceed %s", ErrInvalidInvoice, maxInvoiceExpiry)
+	}
+	confirmations := req.RequiredConfirmations
+	if confirmations == 0 {
+		confirmations = 1
+	}
+	if confirmations < 0 || confirmations > maxInvoiceConfirmations {
+		return nil, fmt.Errorf("%w: required confirmations must be between 1 and %d", ErrInvalidInvoice, maxInvoiceConfirmations)
+	}
+
+	id, err := newInvoiceID()
+	if err != nil {
+		return nil, err
+	}
+
+	// Unpaid invoices leave unused addresses behind, so the gap limit is
+	// ignored rather than failing once enough invoices expire unpaid
+	address, err := GenerateAddress(ctx, types.NewAddressRequest{
+		Account:   req.Account,
+		Branch:    branchExternal,
+		GapPolicy: "ignore",
+		Amount:    req.Amount,
+		Label:     req.Memo,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	invoice := &types.Invoice{
+		ID:                    id,
+		Status:                types.InvoiceStatusPending,
+		Address:               address.Address,
+		Account:               address.Account,
+		URI:                   address.URI,
+		Amount:                req.Amount,
+		Memo:                  req.Memo,
+		RequiredConfirmations: confirmations,
+		CreatedAt:             now,
+		ExpiresAt:             now.Add(expiry),
+		UpdatedAt:             now,
+		Fiat:                  pricing.Convert(req.Amount),
+	}
+
+	invoicesMutex.Lock()
+	invoices[id] = invoice
+	created := *invoice
+	invoicesMutex.Unlock()
+	persistInvoices()
+
+	log.Printf("Invoice %s created for %s DCR to %s", id, formatDCR(req.Amount), invoice.Address)
+	DispatchWebhook("invoice.created", created)
+	return &created, nil
+}
+
+// GetInvoice returns an invoice by ID
+func GetInvoice(id string) (*types.Invoice, error) {
+	invoicesMutex.Lock()
+	defer invoicesMutex.Unlock()
+
+	invoice, ok := invoices[id]
+	if !ok {
+		return nil, ErrInvoiceNotFound
+	}
+	copied := *invoice
+	return &copied, nil
+}
+
+// ListInvoices returns a page of invoices, newest first. statuses may be empty
+// for all invoices.
+func ListInvoices(statuses []string, page, pageSize int) *types.InvoiceListResponse {
+	wanted := make(map[string]bool, len(statuses))
+	for _, s := range statuses {
+		wanted[s] = true
+	}
+
+	invoicesMutex.Lock()
+	statusCount := make(map[string]int)
+	matched := make([]types.Invoice, 0, len(invoices))
+	for _, invoice := range invoices {
+		statusCount[invoice.Status]++
+		if len(wanted) > 0 && !wanted[invoice.Status] {
+			continue
+		}
+		matched = append(matched, *invoice)
+	}
+	invoicesMutex.Unlock()
+
+	sort.Slice(matched, func(i, j int) bool {
+		return matched[i].CreatedAt.After(matched[j].CreatedAt)
+	})
+
+	window := paginate(len(matched), page, pageSize, 25)
+
+	return &types.InvoiceListResponse{
+		Invoices:    matched[window.start:window.end],
+		StatusCount: statusCount,
+		Total:       len(matched),
+		CurrentPage: window.page,
+		PageSize:    window.pageSize,
+		TotalPages:  window.totalPages,
+	}
+}
+
+// updateInvoices re-evaluates every open invoice against the amounts its
+// address received, and notifies webhooks of state changes
+func updateInvoices(ctx context.Context) error {
+	invoicesMutex.Lock()
+	open := make([]string, 0)
+	depths := make(map[int64]bool)
+	for id, invoice := range invoices {
+		if !invoice.Final {
+			open = append(open, id)
+			depths[invoice.RequiredConfirmations] = true
+		}
+	}
+	invoicesMutex.Unlock()
+
+	if len(open) == 0 {
+		return nil
+	}
+
+	// One listreceivedbyaddress call per confirmation depth covers all invoices
+	received, err := fetchReceivedByAddress(ctx, 0)
+	if err != nil {
+		return err
+	}
+	confirmedAt := make(map[int64]map[string]receivedByAddress, len(depths))
+	for depth := range depths {
+		confirmed, err := fetchReceivedByAddress(ctx, depth)
+		if err != nil {
+			return err
+		}
+		confirmedAt[depth] = confirmed
+	}
+
+	now := time.Now()
+	var changed []types.Invoice
+	dirty := false
+	invoicesMutex.Lock()
+	for _, id := range open {
+		invoice, ok := invoices[id]
+		if !ok {
+			continue
+		}
+		previous := invoice.Status
+		previousConfirmations := invoice.Confirmations
+		evaluateInvoice(invoice, received[invoice.Address], confirmedAt[invoice.RequiredConfirmations][invoice.Address], now)
+		if invoice.Status != previous {
+			changed = append(changed, *invoice)
+			dirty = true
+			log.Printf("Invoice %s: %s -> %s", id, previous, invoice.Status)
+		} else if invoice.Confirmations != previousConfirmations {
+			invoice.UpdatedAt = now
+			dirty = true
+		}
+	}
+	invoicesMutex.Unlock()
+
+	if dirty {
+		persistInvoices()
+	}
+	for _, invoice := range changed {
+		DispatchWebhook("invoice."+invoice.Status, invoice)
+	}
+	return nil
+}
+
+// evaluateInvoice applies the invoice state machine. received includes
+// unconfirmed payments, confirmed only those with the required confirmations.
+func evaluateInvoice(invoice *types.Invoice, received, confirmed receivedByAddress, now time.Time) {
+	amount := toAtoms(invoice.Amount)
+	receivedAtoms := toAtoms(received.Amount)
+	confirmedAtoms := toAtoms(confirmed.Amount)
+	expired := now.After(invoice.ExpiresAt)
+
+	invoice.Received = received.Amount
+	invoice.ConfirmedReceived = confirmed.Amount
+	invoice.Confirmations = received.Confirmations
+	if receivedAtoms > 0 && invoice.PaidAt == nil {
+		paidAt := now
+		invoice.PaidAt = &paidAt
+	}
+
+	status := invoice.Status
+	switch {
+	case confirmedAtoms > amount:
+		status = types.InvoiceStatusOverpaid
+		invoice.Final = true
+	case confirmedAtoms == amount:
+		status = types.InvoiceStatusConfirmed
+		invoice.Final = true
+	case receivedAtoms > confirmedAtoms:
+		// Payments are still confirming, even past the expiry
+		status = types.InvoiceStatusSeen
+	case confirmedAtoms > 0:
+		// Everything received is confirmed but short of the amount, a top-up
+		// can still complete the invoice until it expires
+		status = types.InvoiceStatusUnderpaid
+		invoice.Final = expired
+	case expired:
+		status = types.InvoiceStatusExpired
+		invoice.Final = true
+	default:
+		status = types.InvoiceStatusPending
+	}
+
+	if status != invoice.Status {
+		invoice.Status = status
+		invoice.UpdatedAt = now
+	}
+}
+
+// persistInvoices writes the invoices to disk
+func persistInvoices() {
+	invoicesMutex.Lock()
+	defer invoicesMutex.Unlock()
+
+	if invoicesPath == "" {
+		return
+	}
+	stored := invoiceStore{Version: invoicesVersion, Invoices: invoices}
+	if err := utils.WriteJSONFile(invoicesPath, stored); err != nil {
+		log.Printf("Warning: Failed to persist invoices: %v", err)
+	}
+}
+
+func newInvoiceID() (string, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate invoice ID: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
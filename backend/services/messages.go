@@ -0,0 +1,160 @@
+// Copyright (c) 2015-2025 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package services
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	pb "decred.org/dcrwallet/v4/rpc/walletrpc"
+	"github.com/decred/dcrd/txscript/v4/stdaddr"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"decred-pulse-backend/rpc"
+	"decred-pulse-backend/types"
+)
+
+const (
+	// maxMessageLength bounds signed and verified messages, in bytes
+	maxMessageLength = 8192
+
+	// compactSignatureLength is the size of a decoded message signature
+	compactSignatureLength = 65
+)
+
+var (
+	// ErrInvalidMessage is returned for empty or oversized messages
+	ErrInvalidMessage = errors.New("invalid message")
+
+	// ErrInvalidSignature is returned for signatures that are not base64
+	// compact signatures
+	ErrInvalidSignature = errors.New("signature must be a base64 encoded 65 byte compact signature")
+
+	// ErrInvalidSigningAddress is returned for malformed addresses, addresses
+	// of another network and address types that cannot sign messages
+	ErrInvalidSigningAddress = errors.New("invalid signing address")
+
+	// ErrAddressNotOwned is returned when signing with an address the wallet
+	// does not control
+	ErrAddressNotOwned = errors.New("address does not belong to the wallet")
+)
+
+// decodeSigningAddress decodes a P2PKH address of the active network. Only
+// secp256k1 pubkey hash addresses can sign messages.
+func decodeSigningAddress(ctx context.Context, address string) (stdaddr.Address, string, error) {
+	params, err := ActiveNetParams(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	addr, err := stdaddr.DecodeAddress(address, params)
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: not a %s address: %v", ErrInvalidSigningAddress, params.Name, err)
+	}
+	if _, ok := addr.(*stdaddr.AddressPubKeyHashEcdsaSecp256k1V0); !ok {
+		return nil, "", fmt.Errorf("%w: only pubkey hash (Ds/Ts/Ss) addresses can sign messages", ErrInvalidSigningAddress)
+	}
+	return addr, params.Name, nil
+}
+
+// validateMessage checks the message length
+func validateMessage(message string) error {
+	if message == "" {
+		return fmt.Errorf("%w: message must not be empty", ErrInvalidMessage)
+	}
+	if len(message) > maxMessageLength {
+		return fmt.Errorf("%w: message exceeds %d bytes", ErrInvalidMessage, maxMessageLength)
+	}
+	return nil
+}
+
+// SignMessage signs a message with the key of a wallet address using gRPC
+// SignMessage. With an empty passphrase the wallet must already be unlocked.
+func SignMessage(ctx context.Context, req types.SignMessageRequest) (*types.SignMessageResponse, error) {
+	if rpc.WalletGrpcClient == nil {
+		return nil, fmt.Errorf("wallet gRPC client not initialized")
+	}
+	if err := validateMessage(req.Message); err != nil {
+		return nil, err
+	}
+	addr, network, err := decodeSigningAddress(ctx, req.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	// validateaddress gives a clear error before asking for a passphrase
+	if rpc.WalletClient != nil {
+		info, err := fetchAddressInfo(ctx, addr.String())
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsMine {
+			return nil, ErrAddressNotOwned
+		}
+	}
+
+	resp, err := rpc.WalletGrpcClient.SignMessage(ctx, &pb.SignMessageRequest{
+		Address:    addr.String(),
+		Message:    req.Message,
+		Passphrase: []byte(req.Passphrase),
+	})
+	if err != nil {
+		switch status.Code(err) {
+		case codes.InvalidArgument:
+			if req.Passphrase != "" && strings.Contains(strings.ToLower(err.Error()), "passphrase") {
+				return nil, ErrIncorrectPassphrase
+			}
+		case codes.FailedPrecondition:
+			return nil, ErrWalletLocked
+		case codes.NotFound:
+			return nil, ErrAddressNotOwned
+		}
+		return nil, fmt.Errorf("failed to sign message: %w", err)
+	}
+
+	log.Printf("Signed a %d byte message with %s", len(req.Message), addr)
+	return &types.SignMessageResponse{
+		Address:   addr.String(),
+		Message:   req.Message,
+		Signature: base64.StdEncoding.EncodeToString(resp.Signature),
+		Network:   network,
+	}, nil
+}
+
+// VerifyMessage checks a message signature against an address with dcrd
+// verifymessage. A well-formed signature that does not match is not an error.
+func VerifyMessage(ctx context.Context, req types.VerifyMessageRequest) (*types.VerifyMessageResponse, error) {
+	if rpc.DcrdClient == nil {
+		return nil, fmt.Errorf("dcrd RPC client not initialized")
+	}
+	if err := validateMessage(req.Message); err != nil {
+		return nil, err
+	}
+	addr, network, err := decodeSigningAddress(ctx, req.Address)
+	if err != nil {
+		return nil, err
+	}
+	signature := strings.TrimSpace(req.Signature)
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil || len(sig) != compactSignatureLength {
+		return nil, ErrInvalidSignature
+	}
+
+	valid, err := rpc.DcrdClient.VerifyMessage(ctx, addr, signature, req.Message)
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify message: %w", err)
+	}
+
+	return &types.VerifyMessageResponse{
+		Valid:   valid,
+		Address: addr.String(),
+		Message: req.Message,
+		Network: network,
+	}, nil
+}
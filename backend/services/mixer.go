@@ -0,0 +1,288 @@
+// Copyright (c) 2015-2025 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	pb "decred.org/dcrwallet/v4/rpc/walletrpc"
+	"github.com/decred/dcrd/dcrutil/v4"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"decred-pulse-backend/rpc"
+	"decred-pulse-backend/types"
+)
+
+// mixerStartupGrace is how long StartAccountMixer waits for dcrwallet to
+// reject a run (wrong passphrase, unknown account) before reporting success
+const mixerStartupGrace = 3 * time.Second
+
+// ErrMixerRunning is returned when the mixer is started twice
+var ErrMixerRunning = errors.New("account mixer is already running")
+
+// MixerConfig holds the default accounts used by the account mixer
+type MixerConfig struct {
+	MixedAccount       string
+	MixedAccountBranch uint32
+	ChangeAccount      string
+
+	// CSPPServer enables mixing when set. dcrwallet versions with the mixpool
+	// only check that it is not empty, older ones connect to it.
+	CSPPServer string
+}
+
+var (
+	mixerMutex  sync.Mutex
+	mixerConfig = MixerConfig{
+		MixedAccount:  "mixed",
+		ChangeAccount: "unmixed",
+		CSPPServer:    "mix.decred.org:5760",
+	}
+	mixerStatus types.MixerStatus
+	mixerCancel context.CancelFunc
+)
+
+// InitMixer sets the default mixer accounts. Empty fields keep the defaults.
+func InitMixer(cfg MixerConfig) {
+	mixerMutex.Lock()
+	defer mixerMutex.Unlock()
+
+	if cfg.MixedAccount != "" {
+		mixerConfig.MixedAccount = cfg.MixedAccount
+	}
+	if cfg.ChangeAccount != "" {
+		mixerConfig.ChangeAccount = cfg.ChangeAccount
+	}
+	if cfg.CSPPServer != "" {
+		mixerConfig.CSPPServer = cfg.CSPPServer
+	}
+	mixerConfig.MixedAccountBranch = cfg.MixedAccountBranch
+}
+
+// StartAccountMixer runs the dcrwallet account mixer with gRPC RunAccountMixer.
+// The mixer runs for as long as the stream stays open, so the stream outlives
+// the request and is only closed by StopAccountMixer or a wallet error.
+func StartAccountMixer(ctx context.Context, req types.MixerStartRequest) (*types.MixerStatus, error) {
+	if rpc.AccountMixerClient == nil {
+		return nil, fmt.Errorf("wallet gRPC client not initialized")
+	}
+
+	mixerMutex.Lock()
+	cfg := mixerConfig
+	running := mixerStatus.Running
+	mixerMutex.Unlock()
+	if running {
+		return nil, ErrMixerRunning
+	}
+
+	if req.MixedAccount != "" {
+		cfg.MixedAccount = req.MixedAccount
+	}
+	if req.MixedAccountBranch != nil {
+		cfg.MixedAccountBranch = *req.MixedAccountBranch
+	}
+	if req.ChangeAccount != "" {
+		cfg.ChangeAccount = req.ChangeAccount
+	}
+	if req.CSPPServer != "" {
+		cfg.CSPPServer = req.CSPPServer
+	}
+	if cfg.MixedAccountBranch > 1 {
+		return nil, fmt.Errorf("mixed account branch must be 0 (external) or 1 (internal)")
+	}
+	if cfg.MixedAccount == cfg.ChangeAccount {
+		return nil, fmt.Errorf("mixed and change accounts must differ")
+	}
+
+	mixedResp, err := rpc.WalletGrpcClient.AccountNumber(ctx, &pb.AccountNumberRequest{AccountName: cfg.MixedAccount})
+	if err != nil {
+		return nil, fmt.Errorf("unknown account %q: %w", cfg.MixedAccount, err)
+	}
+	changeResp, err := rpc.WalletGrpcClient.AccountNumber(ctx, &pb.AccountNumberRequest{AccountName: cfg.ChangeAccount})
+	if err != nil {
+		return nil, fmt.Errorf("unknown account %q: %w", cfg.ChangeAccount, err)
+	}
+
+	mixerMutex.Lock()
+	if mixerStatus.Running {
+		mixerMutex.Unlock()
+		return nil, ErrMixerRunning
+	}
+	runCtx, cancel := context.WithCancel(context.Background())
+	stream, err := rpc.AccountMixerClient.RunAccountMixer(runCtx, &pb.RunAccountMixerRequest{
+		Passphrase:         []byte(req.Passphrase),
+		MixedAccount:       mixedResp.AccountNumber,
+		MixedAccountBranch: cfg.MixedAccountBranch,
+		ChangeAccount:      changeResp.AccountNumber,
+		CsppServer:         cfg.CSPPServer,
+	})
+	if err != nil {
+		mixerMutex.Unlock()
+		cancel()
+		return nil, fmt.Errorf("failed to start account mixer: %w", err)
+	}
+	now := time.Now()
+	mixerStatus = types.MixerStatus{
+		Running:            true,
+		MixedAccount:       cfg.MixedAccount,
+		MixedAccountBranch: cfg.MixedAccountBranch,
+		ChangeAccount:      cfg.ChangeAccount,
+		CSPPServer:         cfg.CSPPServer,
+		StartedAt:          &now,
+	}
+	mixerCancel = cancel
+	mixerMutex.Unlock()
+
+	done := make(chan error, 1)
+	go func() {
+		// RunAccountMixer sends no messages, Recv returns when the mixer ends
+		_, err := stream.Recv()
+		done <- err
+		finishAccountMixer(runCtx, err)
+	}()
+
+	select {
+	case err := <-done:
+		switch status.Code(err) {
+		case codes.InvalidArgument:
+			if req.Passphrase != "" && strings.Contains(strings.ToLower(err.Error()), "passphrase") {
+				return nil, ErrIncorrectPassphrase
+			}
+		case codes.FailedPrecondition:
+			return nil, ErrWalletLocked
+		}
+		return nil, fmt.Errorf("account mixer stopped: %w", err)
+	case <-time.After(mixerStartupGrace):
+	}
+
+	log.Printf("Account mixer started: %s (branch %d) <- %s", cfg.MixedAccount, cfg.MixedAccountBranch, cfg.ChangeAccount)
+	return FetchMixerStatus(), nil
+}
+
+// finishAccountMixer records the end of a mixer run
+func finishAccountMixer(runCtx context.Context, err error) {
+	mixerMutex.Lock()
+	defer mixerMutex.Unlock()
+
+	now := time.Now()
+	mixerStatus.Running = false
+	mixerStatus.StoppedAt = &now
+	mixerStatus.LastError = ""
+	if runCtx.Err() == nil && err != nil {
+		mixerStatus.LastError = err.Error()
+		log.Printf("Warning: Account mixer stopped: %v", err)
+	} else {
+		log.Println("Account mixer stopped")
+	}
+	if mixerCancel != nil {
+		mixerCancel()
+		mixerCancel = nil
+	}
+}
+
+// StopAccountMixer closes the mixer stream, which stops dcrwallet's mixer and
+// locks the wallet again if the run unlocked it. It reports whether a mixer
+// was running.
+func StopAccountMixer() bool {
+	mixerMutex.Lock()
+	cancel := mixerCancel
+	mixerMutex.Unlock()
+
+	if cancel == nil {
+		return false
+	}
+	cancel()
+	return true
+}
+
+// FetchMixerStatus returns the state of the mixer run by this backend. A mixer
+// enabled in dcrwallet's own configuration is not visible here.
+func FetchMixerStatus() *types.MixerStatus {
+	mixerMutex.Lock()
+	defer mixerMutex.Unlock()
+
+	s := mixerStatus
+	if !s.Running && s.StartedAt == nil {
+		s.MixedAccount = mixerConfig.MixedAccount
+		s.MixedAccountBranch = mixerConfig.MixedAccountBranch
+		s.ChangeAccount = mixerConfig.ChangeAccount
+		s.CSPPServer = mixerConfig.CSPPServer
+	}
+	return &s
+}
+
+// FetchAccountPrivacy splits every account's unspent outputs into mixed and
+// unmixed funds. An output is mixed when its transaction carries the IsMixed
+// flag and it has the mix denomination; mix change is unmixed.
+func FetchAccountPrivacy(ctx context.Context) ([]types.AccountPrivacy, error) {
+	if rpc.WalletClient == nil {
+		return nil, fmt.Errorf("wallet RPC client not initialized")
+	}
+
+	unspent, err := fetchUnspent(ctx, 0, "")
+	if err != nil {
+		return nil, err
+	}
+
+	mixer := FetchMixerStatus()
+	byAccount := make(map[string]*types.AccountPrivacy)
+	get := func(name string) *types.AccountPrivacy {
+		a, ok := byAccount[name]
+		if !ok {
+			a = &types.AccountPrivacy{
+				AccountName:     name,
+				IsMixedAccount:  name == mixer.MixedAccount,
+				IsChangeAccount: name == mixer.ChangeAccount,
+			}
+			byAccount[name] = a
+		}
+		return a
+	}
+
+	// Accounts without unspent outputs are listed too
+	if accounts, err := FetchAllAccounts(ctx); err == nil {
+		for _, account := range accounts {
+			get(account.AccountName)
+		}
+	} else {
+		log.Printf("Warning: Could not list accounts: %v", err)
+	}
+
+	mixedAtoms := make(map[string]int64)
+	unmixedAtoms := make(map[string]int64)
+	for _, out := range unspent {
+		a := get(out.Account)
+		atoms := toAtoms(out.Amount)
+		if isMixedOutput(ctx, out.TxID, out.Tree, atoms) {
+			a.MixedOutputs++
+			mixedAtoms[out.Account] += atoms
+		} else {
+			a.UnmixedOutputs++
+			unmixedAtoms[out.Account] += atoms
+		}
+	}
+
+	accounts := make([]types.AccountPrivacy, 0, len(byAccount))
+	for name, a := range byAccount {
+		a.MixedBalance = dcrutil.Amount(mixedAtoms[name]).ToCoin()
+		a.UnmixedBalance = dcrutil.Amount(unmixedAtoms[name]).ToCoin()
+		if total := mixedAtoms[name] + unmixedAtoms[name]; total > 0 {
+			a.MixedPercent = float64(mixedAtoms[name]) / float64(total) * 100
+		}
+		accounts = append(accounts, *a)
+	}
+	sort.Slice(accounts, func(i, j int) bool {
+		return accounts[i].AccountName < accounts[j].AccountName
+	})
+	return accounts, nil
+}
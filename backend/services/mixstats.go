@@ -0,0 +1,363 @@
+// Copyright (c) 2015-2025 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/decred/dcrd/dcrutil/v4"
+	"github.com/decred/dcrd/wire"
+
+	"decred-pulse-backend/jobs"
+	"decred-pulse-backend/rpc"
+	"decred-pulse-backend/types"
+	"decred-pulse-backend/utils"
+)
+
+const (
+	// MixStatsMainnetStartHeight is a mainnet block from spring 2019, before
+	// dcrwallet shipped StakeShuffle mixing
+	MixStatsMainnetStartHeight = 340000
+
+	// mixStatsConfirmations keeps the scan this many blocks behind the tip so
+	// reorganizations never touch scanned blocks
+	mixStatsConfirmations = 6
+
+	// mixStatsCheckpointBlocks is how often a scan persists its state
+	mixStatsCheckpointBlocks = 5000
+
+	// mixStatsUpdateInterval is how often the statistics catch up with the chain
+	mixStatsUpdateInterval = 10 * time.Minute
+
+	// mixStatsDays is the number of recent days reported
+	mixStatsDays = 30
+
+	mixStatsVersion = 1
+)
+
+// mixDayStats is the StakeShuffle activity of one UTC day
+type mixDayStats struct {
+	Transactions int   `json:"transactions"`
+	MixedOutputs int   `json:"mixedOutputs"`
+	Volume       int64 `json:"volume"` // Atoms
+}
+
+// mixStatsState is the persisted state of the chain mix statistics. It tracks
+// every unspent mixed output, so the mixed supply is their sum.
+type mixStatsState struct {
+	Version       int                     `json:"version"`
+	ScannedHeight int64                   `json:"scannedHeight"`
+	UTXOs         map[string]int64        `json:"utxos"` // "txid:index" -> atoms
+	Daily         map[string]*mixDayStats `json:"daily"` // YYYY-MM-DD
+}
+
+var (
+	mixStatsMutex   sync.Mutex
+	mixStats        *mixStatsState // nil until a scan has run
+	mixStatsPath    string
+	mixStatsRunning bool
+
+	mixStatsUpdaterOnce sync.Once
+)
+
+// InitMixStats loads the persisted mix statistics from path and enables persistence
+func InitMixStats(path string) error {
+	mixStatsMutex.Lock()
+	defer mixStatsMutex.Unlock()
+
+	mixStatsPath = path
+
+	var stored mixStatsState
+	found, err := utils.ReadJSONFile(path, &stored)
+	if err != nil {
+		return err
+	}
+	if !found || stored.Version != mixStatsVersion {
+		return nil
+	}
+	if stored.UTXOs == nil {
+		stored.UTXOs = make(map[string]int64)
+	}
+	if stored.Daily == nil {
+		stored.Daily = make(map[string]*mixDayStats)
+	}
+	mixStats = &stored
+	log.Printf("Loaded mix statistics up to block %d (%d mixed outputs)", stored.ScannedHeight, len(stored.UTXOs))
+	return nil
+}
+
+// StartMixStatsUpdater keeps the mix statistics up to date with the chain once
+// an initial scan has run
+func StartMixStatsUpdater() {
+	mixStatsUpdaterOnce.Do(func() {
+		go func() {
+			ticker := time.NewTicker(mixStatsUpdateInterval)
+			defer ticker.Stop()
+			for range ticker.C {
+				mixStatsMutex.Lock()
+				ready := mixStats != nil && !mixStatsRunning && rpc.DcrdClient != nil
+				if ready {
+					mixStatsRunning = true
+				}
+				mixStatsMutex.Unlock()
+				if !ready {
+					continue
+				}
+
+				ctx, cancel := context.WithTimeout(context.Background(), mixStatsUpdateInterval)
+				if _, err := scanMixBlocks(ctx, nil); err != nil {
+					log.Printf("Warning: Mix statistics update failed: %v", err)
+				}
+				cancel()
+
+				mixStatsMutex.Lock()
+				mixStatsRunning = false
+				mixStatsMutex.Unlock()
+			}
+		}()
+	})
+}
+
+// TriggerMixStatsScan starts the chain scan for mix statistics as a tracked
+// job. With rescan, or when no statistics exist yet, the scan starts over from
+// startHeight (0 picks the default for the network); otherwise it continues
+// from the last scanned block.
+func TriggerMixStatsScan(startHeight int64, rescan bool) (string, error) {
+	if rpc.DcrdClient == nil {
+		return "", fmt.Errorf("dcrd RPC client not initialized")
+	}
+
+	mixStatsMutex.Lock()
+	if mixStatsRunning {
+		mixStatsMutex.Unlock()
+		return "", fmt.Errorf("mix statistics scan already in progress")
+	}
+	mixStatsRunning = true
+	mixStatsMutex.Unlock()
+
+	job := jobs.Start("mix_stats_scan", []string{"Scan blocks for mix transactions"}, func(ctx context.Context, job *jobs.Handle) (interface{}, error) {
+		defer func() {
+			mixStatsMutex.Lock()
+			mixStatsRunning = false
+			mixStatsMutex.Unlock()
+		}()
+
+		mixStatsMutex.Lock()
+		fresh := rescan || mixStats == nil
+		mixStatsMutex.Unlock()
+
+		if fresh {
+			if startHeight <= 0 {
+				startHeight = 1
+				params, err := ActiveNetParams(ctx)
+				if err != nil {
+					return nil, err
+				}
+				if params.Net == wire.MainNet {
+					startHeight = MixStatsMainnetStartHeight
+				}
+			}
+			mixStatsMutex.Lock()
+			mixStats = &mixStatsState{
+				Version:       mixStatsVersion,
+				ScannedHeight: startHeight - 1,
+				UTXOs:         make(map[string]int64),
+				Daily:         make(map[string]*mixDayStats),
+			}
+			mixStatsMutex.Unlock()
+		}
+
+		return scanMixBlocks(ctx, job)
+	})
+	return job.ID, nil
+}
+
+// scanMixBlocks processes blocks after the last scanned height up to the
+// confirmed tip. job may be nil for background updates.
+func scanMixBlocks(ctx context.Context, job *jobs.Handle) (interface{}, error) {
+	if job != nil {
+		job.BeginStep(0)
+	}
+
+	bestHeight, err := rpc.DcrdClient.GetBlockCount(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get block count: %w", err)
+	}
+	endHeight := bestHeight - mixStatsConfirmations
+
+	mixStatsMutex.Lock()
+	startHeight := mixStats.ScannedHeight + 1
+	mixStatsMutex.Unlock()
+
+	if job != nil {
+		job.Logf("Scanning blocks %d to %d for mix transactions", startHeight, endHeight)
+	}
+
+	mixes := 0
+	for h := startHeight; h <= endHeight; h++ {
+		if err := ctx.Err(); err != nil {
+			persistMixStats()
+			if job != nil {
+				job.Logf("Scan cancelled at block %d, progress saved", h)
+			}
+			return nil, err
+		}
+
+		hash, err := rpc.DcrdClient.GetBlockHash(ctx, h)
+		if err != nil {
+			persistMixStats()
+			return nil, fmt.Errorf("failed to get block hash at height %d: %w", h, err)
+		}
+		block, err := rpc.DcrdClient.GetBlock(ctx, hash)
+		if err != nil {
+			persistMixStats()
+			return nil, fmt.Errorf("failed to get block %d: %w", h, err)
+		}
+
+		mixStatsMutex.Lock()
+		mixes += mixStats.addBlock(block)
+		mixStats.ScannedHeight = h
+		mixStatsMutex.Unlock()
+
+		if (h-startHeight+1)%mixStatsCheckpointBlocks == 0 {
+			persistMixStats()
+		}
+		if job != nil && endHeight > startHeight && h%100 == 0 {
+			progress := float64(h-startHeight) / float64(endHeight-startHeight) * 100
+			job.SetProgress(progress, fmt.Sprintf("Scanning block %d/%d (%d mixes found)", h, endHeight, mixes))
+		}
+	}
+
+	persistMixStats()
+
+	mixStatsMutex.Lock()
+	mixedOutputs := len(mixStats.UTXOs)
+	mixStatsMutex.Unlock()
+
+	if job != nil {
+		job.Logf("Mix statistics scan complete. Found %d mix transactions", mixes)
+	}
+	return map[string]interface{}{
+		"startHeight":  startHeight,
+		"endHeight":    endHeight,
+		"mixesFound":   mixes,
+		"mixedOutputs": mixedOutputs,
+	}, nil
+}
+
+// addBlock removes spent mixed outputs and records new ones. It returns the
+// number of mix transactions in the block. Caller must hold mixStatsMutex.
+func (s *mixStatsState) addBlock(block *wire.MsgBlock) int {
+	// Mixed outputs can be spent by stake transactions too, e.g. ticket purchases
+	for _, tx := range block.STransactions {
+		s.spend(tx)
+	}
+
+	day := block.Header.Timestamp.UTC().Format("2006-01-02")
+	mixes := 0
+	for i, tx := range block.Transactions {
+		// Spend first, a later transaction of the block may spend a new mixed output
+		s.spend(tx)
+		if i == 0 {
+			continue // Coinbase
+		}
+		mix := classifyMixMsgTx(tx)
+		if !mix.mixed {
+			continue
+		}
+		mixes++
+
+		txHash := tx.TxHash()
+		for index, out := range tx.TxOut {
+			if out.Value == mix.denomination {
+				outpoint := wire.OutPoint{Hash: txHash, Index: uint32(index), Tree: wire.TxTreeRegular}
+				s.UTXOs[outpoint.String()] = out.Value
+			}
+		}
+
+		stats := s.Daily[day]
+		if stats == nil {
+			stats = &mixDayStats{}
+			s.Daily[day] = stats
+		}
+		stats.Transactions++
+		stats.MixedOutputs += mix.mixedOutputs
+		stats.Volume += mix.denomination * int64(mix.mixedOutputs)
+	}
+	return mixes
+}
+
+// spend drops the mixed outputs spent by tx
+func (s *mixStatsState) spend(tx *wire.MsgTx) {
+	for _, in := range tx.TxIn {
+		delete(s.UTXOs, in.PreviousOutPoint.String())
+	}
+}
+
+// persistMixStats writes the scan state to disk
+func persistMixStats() {
+	mixStatsMutex.Lock()
+	defer mixStatsMutex.Unlock()
+
+	if mixStatsPath == "" || mixStats == nil {
+		return
+	}
+	if err := utils.WriteJSONFile(mixStatsPath, mixStats); err != nil {
+		log.Printf("Warning: Failed to persist mix statistics: %v", err)
+	}
+}
+
+// FetchMixStats returns the chain mix statistics, or nil when no scan has run
+func FetchMixStats(ctx context.Context) *types.MixStats {
+	mixStatsMutex.Lock()
+	if mixStats == nil {
+		mixStatsMutex.Unlock()
+		return nil
+	}
+
+	var mixedAtoms int64
+	for _, value := range mixStats.UTXOs {
+		mixedAtoms += value
+	}
+	stats := &types.MixStats{
+		MixedSupply:   dcrutil.Amount(mixedAtoms).ToCoin(),
+		MixedUTXOs:    len(mixStats.UTXOs),
+		ScannedHeight: mixStats.ScannedHeight,
+		Scanning:      mixStatsRunning,
+		Daily:         make([]types.MixDay, 0, mixStatsDays),
+		LastUpdate:    time.Now(),
+	}
+
+	days := make([]string, 0, len(mixStats.Daily))
+	for day := range mixStats.Daily {
+		days = append(days, day)
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(days)))
+	if len(days) > mixStatsDays {
+		days = days[:mixStatsDays]
+	}
+	for _, day := range days {
+		d := mixStats.Daily[day]
+		stats.Daily = append(stats.Daily, types.MixDay{
+			Date:         day,
+			Transactions: d.Transactions,
+			MixedOutputs: d.MixedOutputs,
+			Volume:       dcrutil.Amount(d.Volume).ToCoin(),
+		})
+	}
+	mixStatsMutex.Unlock()
+
+	if rpc.DcrdClient != nil {
+		if supply, err := rpc.DcrdClient.GetCoinSupply(ctx); err == nil && supply > 0 {
+			stats.MixedPercent = float64(mixedAtoms) / float64(supply) * 100
+		}
+	}
+	return stats
+}
@@ -0,0 +1,103 @@
+// Copyright (c) 2015-2025 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	pb "decred.org/dcrwallet/v4/rpc/walletrpc"
+	"github.com/decred/dcrd/chaincfg/v3"
+	"github.com/decred/dcrd/txscript/v4/stdaddr"
+
+	"decred-pulse-backend/rpc"
+)
+
+// Active network parameters, resolved once from dcrwallet or dcrd
+var (
+	netParamsMutex sync.Mutex
+	netParams      *chaincfg.Params
+)
+
+// knownNetParams lists the networks the backend can operate on
+func knownNetParams() []*chaincfg.Params {
+	return []*chaincfg.Params{
+		chaincfg.MainNetParams(),
+		chaincfg.TestNet3Params(),
+		chaincfg.SimNetParams(),
+		chaincfg.RegNetParams(),
+	}
+}
+
+// ActiveNetParams returns the chain parameters of the network the wallet
+// (or, without a wallet, dcrd) is running on
+func ActiveNetParams(ctx context.Context) (*chaincfg.Params, error) {
+	netParamsMutex.Lock()
+	defer netParamsMutex.Unlock()
+
+	if netParams != nil {
+		return netParams, nil
+	}
+
+	params, err := detectNetParams(ctx)
+	if err != nil {
+		return nil, err
+	}
+	netParams = params
+	return params, nil
+}
+
+func detectNetParams(ctx context.Context) (*chaincfg.Params, error) {
+	if rpc.WalletGrpcClient != nil {
+		resp, err := rpc.WalletGrpcClient.Network(ctx, &pb.NetworkRequest{})
+		if err == nil {
+			for _, params := range knownNetParams() {
+				if uint32(params.Net) == resp.ActiveNetwork {
+					return params, nil
+				}
+			}
+			return nil, fmt.Errorf("unknown wallet network %d", resp.ActiveNetwork)
+		}
+	}
+
+	if rpc.DcrdClient != nil {
+		result, err := rpc.DcrdClient.RawRequest(ctx, "getblockchaininfo", nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get blockchain info: %w", err)
+		}
+
+		var info struct {
+			Chain string `json:"chain"`
+		}
+		if err := json.Unmarshal(result, &info); err != nil {
+			return nil, fmt.Errorf("failed to parse blockchain info: %w", err)
+		}
+
+		for _, params := range knownNetParams() {
+			if params.Name == info.Chain {
+				return params, nil
+			}
+		}
+		return nil, fmt.Errorf("unknown network %q", info.Chain)
+	}
+
+	return nil, fmt.Errorf("no RPC connection available to determine the active network")
+}
+
+// DecodeAddress validates addr against the active network and decodes it
+func DecodeAddress(ctx context.Context, addr string) (stdaddr.Address, error) {
+	params, err := ActiveNetParams(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	decoded, err := stdaddr.DecodeAddress(addr, params)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s address %q: %w", params.Name, addr, err)
+	}
+	return decoded, nil
+}
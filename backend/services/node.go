@@ -14,6 +14,7 @@ import (
 	"sync"
 	"time"
 
+	"decred-pulse-backend/pricing"
 	"decred-pulse-backend/rpc"
 	"decred-pulse-backend/types"
 	"decred-pulse-backend/utils"
@@ -341,13 +342,27 @@ func FetchSupplyInfo() (*types.SupplyInfo, error) {
 		treasuryBalance = utils.FormatDCRAmountWithDecimals(treasuryBalanceDCR, 2)
 	}
 
+	mixedPercent := "N/A"
+	mixing := FetchMixStats(ctx)
+	if mixing != nil {
+		mixedPercent = fmt.Sprintf("%.1f%%", mixing.MixedPercent)
+	}
+
+	exchangeRate := "N/A"
+	rate := pricing.CurrentRate()
+	if rate != nil {
+		exchangeRate = fmt.Sprintf("%.2f %s", rate.Rate, rate.Currency)
+	}
+
 	return &types.SupplyInfo{
 		CirculatingSupply: circulatingSupply,
 		StakedSupply:      stakedSupply,
 		StakedPercent:     stakedPercent,
-		ExchangeRate:      "N/A", // Requires external API
+		ExchangeRate:      exchangeRate,
 		TreasurySize:      treasuryBalance,
-		MixedPercent:      "N/A", // Requires mixer statistics
+		MixedPercent:      mixedPercent,
+		Rate:              rate,
+		Mixing:            mixing,
 	}, nil
 }
 
@@ -644,28 +659,17 @@ func getTransactionTypeAndStakeValueWithCoinJoin(ctx context.Context, txHash str
 		return "ticket", totalStakeValue, false
 	}
 
-	// Check if it's a CoinJoin transaction
-	// Heuristic: 3+ inputs, 3+ outputs, 3+ outputs with matching values
-	isCoinJoin := false
-	if len(decoded.Vin) >= 3 && len(decoded.Vout) >= 3 {
-		// Count output values (rounded to avoid floating point issues)
-		outputValues := make(map[int64]int)
-		for _, vout := range decoded.Vout {
-			rounded := int64(vout.Value * 1e8) // Convert to atoms
-			outputValues[rounded]++
-		}
-
-		// If we have 3+ outputs with the same value, likely a CoinJoin
-		for _, count := range outputValues {
-			if count >= 3 {
-				isCoinJoin = true
-				break
-			}
+	// Check if it's a mix transaction, with the classifier used for wallet history
+	outputs := make([]mixOutput, len(decoded.Vout))
+	for i, vout := range decoded.Vout {
+		outputs[i] = mixOutput{
+			value: int64(math.Round(vout.Value * 1e8)), // Convert to atoms
+			p2pkh: vout.ScriptPubKey.Type == "pubkeyhash",
 		}
 	}
 
 	// Regular transaction or CoinJoin
-	return "regular", 0, isCoinJoin
+	return "regular", 0, classifyMix(len(decoded.Vin), outputs).mixed
 }
 
 // analyzeMempoolTransactionsLegacy is the old transaction-counting method (fallback)
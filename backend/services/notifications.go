@@ -0,0 +1,510 @@
+// Copyright (c) 2015-2025 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	pb "decred.org/dcrwallet/v4/rpc/walletrpc"
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/wire"
+
+	"decred-pulse-backend/rpc"
+	"decred-pulse-backend/types"
+)
+
+const (
+	// notificationConfirmations is the confirmation depth after which a
+	// transaction is considered final and no longer tracked
+	notificationConfirmations = 6
+
+	// maxTrackedTransactions bounds the transactions awaiting confirmation
+	maxTrackedTransactions = 500
+
+	// Reconnect backoff for the notification streams
+	notificationMinBackoff = 1 * time.Second
+	notificationMaxBackoff = 60 * time.Second
+
+	// importedAccountNumber is the fixed number of dcrwallet's imported account
+	importedAccountNumber = 1<<31 - 1
+)
+
+// The notification subscriber keeps the dcrwallet gRPC TransactionNotifications,
+// ConfirmationNotifications and AccountNotifications streams open and turns
+// their messages into WalletEvents for WebSocket clients and webhooks.
+var (
+	notificationsOnce sync.Once
+
+	walletEventSubscribersMutex sync.Mutex
+	walletEventSubscribers      []chan types.WalletEvent
+
+	// Transactions waiting to reach notificationConfirmations, keyed by txid
+	trackedTxMutex sync.Mutex
+	trackedTxs     = make(map[string]*types.Transaction)
+
+	// Hashes to register on the active confirmation stream
+	confirmationRequests = make(chan [][]byte, 100)
+
+	accountNamesMutex sync.RWMutex
+	accountNames      = make(map[uint32]string)
+)
+
+// StartWalletNotifications starts the notification stream subscribers.
+// Streams reconnect with backoff, so this is safe to call before dcrwallet is reachable.
+func StartWalletNotifications() {
+	notificationsOnce.Do(func() {
+		ctx := context.Background()
+		go runNotificationStream(ctx, "transaction", streamTransactionNotifications)
+		go runNotificationStream(ctx, "confirmation", streamConfirmationNotifications)
+		go runNotificationStream(ctx, "account", streamAccountNotifications)
+		log.Println("📡 Wallet notification subscriber started")
+	})
+}
+
+// SubscribeWalletEvents returns a channel receiving every wallet notification event
+func SubscribeWalletEvents() chan types.WalletEvent {
+	ch := make(chan types.WalletEvent, 50)
+	walletEventSubscribersMutex.Lock()
+	walletEventSubscribers = append(walletEventSubscribers, ch)
+	walletEventSubscribersMutex.Unlock()
+	return ch
+}
+
+// UnsubscribeWalletEvents stops delivery of wallet notification events to ch
+func UnsubscribeWalletEvents(ch chan types.WalletEvent) {
+	walletEventSubscribersMutex.Lock()
+	defer walletEventSubscribersMutex.Unlock()
+
+	for i, c := range walletEventSubscribers {
+		if c == ch {
+			walletEventSubscribers = append(walletEventSubscribers[:i], walletEventSubscribers[i+1:]...)
+			break
+		}
+	}
+}
+
+// publishWalletEvent sends the event to all subscribers without blocking
+func publishWalletEvent(event types.WalletEvent) {
+	walletEventSubscribersMutex.Lock()
+	defer walletEventSubscribersMutex.Unlock()
+
+	for _, ch := range walletEventSubscribers {
+		select {
+		case ch <- event:
+		default:
+			// Channel full, skip
+		}
+	}
+}
+
+// runNotificationStream runs stream until ctx is done, reconnecting with
+// exponential backoff whenever the stream fails or the client is unavailable
+func runNotificationStream(ctx context.Context, name string, stream func(ctx context.Context) error) {
+	backoff := notificationMinBackoff
+	for {
+		started := time.Now()
+
+		var err error
+		if rpc.WalletGrpcClient == nil {
+			err = fmt.Errorf("wallet gRPC client not initialized")
+		} else {
+			err = stream(ctx)
+		}
+
+		if ctx.Err() != nil {
+			return
+		}
+
+		// A stream that stayed up for a while was healthy, start over with a short delay
+		if time.Since(started) > notificationMaxBackoff {
+			backoff = notificationMinBackoff
+		}
+		log.Printf("Warning: Wallet %s notifications interrupted: %v (retrying in %s)", name, err, backoff)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if backoff > notificationMaxBackoff {
+			backoff = notificationMaxBackoff
+		}
+	}
+}
+
+// streamTransactionNotifications forwards attached, detached and unmined wallet transactions
+func streamTransactionNotifications(ctx context.Context) error {
+	if err := refreshAccountNames(ctx); err != nil {
+		log.Printf("Warning: Could not load account names: %v", err)
+	}
+
+	stream, err := rpc.WalletGrpcClient.TransactionNotifications(ctx, &pb.TransactionNotificationsRequest{})
+	if err != nil {
+		return fmt.Errorf("failed to open stream: %w", err)
+	}
+	log.Println("✅ Transaction notification stream connected")
+
+	for {
+		resp, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+
+		for _, hash := range resp.DetachedBlocks {
+			publishWalletEvent(types.WalletEvent{
+				Type:      types.WalletEventDetached,
+				Time:      time.Now(),
+				BlockHash: hashString(hash),
+			})
+		}
+
+		// Attached blocks are sorted by height, the last one is the new tip
+		var tipHeight int32
+		if n := len(resp.AttachedBlocks); n > 0 {
+			tipHeight = resp.AttachedBlocks[n-1].Height
+			TriggerCoinJoinPrewarm()
+		}
+
+		for _, block := range resp.AttachedBlocks {
+			for _, details := range block.Transactions {
+				tx := convertTransactionDetails(ctx, details)
+				tx.BlockHash = hashString(block.Hash)
+				tx.BlockTime = block.Timestamp
+				tx.Confirmations = int64(tipHeight-block.Height) + 1
+				handleWalletTransaction(tx, block.Height)
+			}
+		}
+
+		for _, details := range resp.UnminedTransactions {
+			handleWalletTransaction(convertTransactionDetails(ctx, details), 0)
+		}
+
+		// Invoices track new payments and confirmations
+		notifyInvoiceWatcher()
+	}
+}
+
+// handleWalletTransaction publishes a transaction event, notifies webhooks the
+// first time a transaction is seen and registers it for confirmation tracking
+func handleWalletTransaction(tx types.Transaction, height int32) {
+	publishWalletEvent(types.WalletEvent{
+		Type:        types.WalletEventTransaction,
+		Time:        time.Now(),
+		Transaction: &tx,
+		BlockHash:   tx.BlockHash,
+		BlockHeight: height,
+	})
+
+	if tx.Confirmations >= notificationConfirmations {
+		return
+	}
+
+	trackedTxMutex.Lock()
+	_, seen := trackedTxs[tx.TxID]
+	if !seen && len(trackedTxs) >= maxTrackedTransactions {
+		trackedTxMutex.Unlock()
+		log.Printf("Warning: Not tracking confirmations for %s: too many pending transactions", tx.TxID)
+		return
+	}
+	tracked := tx
+	trackedTxs[tx.TxID] = &tracked
+	trackedTxMutex.Unlock()
+
+	if seen {
+		return
+	}
+
+	DispatchWebhook(transactionWebhookEvent(tx), tx)
+
+	hash, err := chainhash.NewHashFromStr(tx.TxID)
+	if err != nil {
+		return
+	}
+	select {
+	case confirmationRequests <- [][]byte{hash[:]}:
+	default:
+		// The backlog is resent in full when the confirmation stream reconnects
+	}
+}
+
+// transactionWebhookEvent names the webhook event for a newly seen transaction
+func transactionWebhookEvent(tx types.Transaction) string {
+	switch tx.TxType {
+	case "vote":
+		return "wallet.vote"
+	case "ticket":
+		return "wallet.ticket"
+	case "revocation":
+		return "wallet.revocation"
+	}
+	if tx.Category == "send" {
+		return "wallet.transaction.sent"
+	}
+	return "wallet.transaction.received"
+}
+
+// streamConfirmationNotifications watches tracked transactions until they
+// reach notificationConfirmations or disappear from the wallet
+func streamConfirmationNotifications(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stream, err := rpc.WalletGrpcClient.ConfirmationNotifications(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to open stream: %w", err)
+	}
+	log.Println("✅ Confirmation notification stream connected")
+
+	// Re-register everything still pending from before a reconnect
+	trackedTxMutex.Lock()
+	pending := make([][]byte, 0, len(trackedTxs))
+	for txid := range trackedTxs {
+		if hash, err := chainhash.NewHashFromStr(txid); err == nil {
+			pending = append(pending, hash[:])
+		}
+	}
+	trackedTxMutex.Unlock()
+
+	send := func(hashes [][]byte) error {
+		return stream.Send(&pb.ConfirmationNotificationsRequest{
+			TxHashes:  hashes,
+			StopAfter: notificationConfirmations,
+		})
+	}
+
+	if err := send(pending); err != nil {
+		return fmt.Errorf("failed to register transactions: %w", err)
+	}
+
+	sendErr := make(chan error, 1)
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case hashes := <-confirmationRequests:
+				if err := send(hashes); err != nil {
+					sendErr <- err
+					return
+				}
+			}
+		}
+	}()
+
+	recvErr := make(chan error, 1)
+	go func() {
+		for {
+			resp, err := stream.Recv()
+			if err != nil {
+				recvErr <- err
+				return
+			}
+			for _, conf := range resp.Confirmations {
+				handleConfirmation(conf)
+			}
+		}
+	}()
+
+	select {
+	case err := <-sendErr:
+		return err
+	case err := <-recvErr:
+		return err
+	}
+}
+
+// handleConfirmation publishes a confirmation change for a tracked transaction
+func handleConfirmation(conf *pb.ConfirmationNotificationsResponse_TransactionConfirmations) {
+	txid := hashString(conf.TxHash)
+
+	trackedTxMutex.Lock()
+	tracked, ok := trackedTxs[txid]
+	if !ok {
+		trackedTxMutex.Unlock()
+		return
+	}
+
+	// A negative count means the wallet no longer knows the transaction
+	final := conf.Confirmations < 0 || conf.Confirmations >= notificationConfirmations
+	if final {
+		delete(trackedTxs, txid)
+	}
+	if conf.Confirmations >= 0 {
+		tracked.Confirmations = int64(conf.Confirmations)
+	}
+	if len(conf.BlockHash) > 0 {
+		tracked.BlockHash = hashString(conf.BlockHash)
+	}
+	tx := *tracked
+	trackedTxMutex.Unlock()
+
+	publishWalletEvent(types.WalletEvent{
+		Type:        types.WalletEventConfirmation,
+		Time:        time.Now(),
+		Transaction: &tx,
+		BlockHash:   tx.BlockHash,
+		BlockHeight: conf.BlockHeight,
+	})
+
+	if conf.Confirmations >= notificationConfirmations {
+		DispatchWebhook("wallet.transaction.confirmed", tx)
+	}
+}
+
+// streamAccountNotifications forwards account creation, renames and key count changes
+func streamAccountNotifications(ctx context.Context) error {
+	stream, err := rpc.WalletGrpcClient.AccountNotifications(ctx, &pb.AccountNotificationsRequest{})
+	if err != nil {
+		return fmt.Errorf("failed to open stream: %w", err)
+	}
+	log.Println("✅ Account notification stream connected")
+
+	for {
+		resp, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+
+		accountNamesMutex.Lock()
+		accountNames[resp.AccountNumber] = resp.AccountName
+		accountNamesMutex.Unlock()
+
+		account := &types.AccountNotification{
+			AccountNumber:    resp.AccountNumber,
+			AccountName:      resp.AccountName,
+			ExternalKeyCount: resp.ExternalKeyCount,
+			InternalKeyCount: resp.InternalKeyCount,
+			ImportedKeyCount: resp.ImportedKeyCount,
+		}
+		publishWalletEvent(types.WalletEvent{
+			Type:    types.WalletEventAccount,
+			Time:    time.Now(),
+			Account: account,
+		})
+		DispatchWebhook("wallet.account", account)
+	}
+}
+
+// refreshAccountNames reloads the account number to name mapping
+func refreshAccountNames(ctx context.Context) error {
+	resp, err := rpc.WalletGrpcClient.Accounts(ctx, &pb.AccountsRequest{})
+	if err != nil {
+		return err
+	}
+
+	accountNamesMutex.Lock()
+	defer accountNamesMutex.Unlock()
+	for _, account := range resp.Accounts {
+		accountNames[account.AccountNumber] = account.AccountName
+	}
+	return nil
+}
+
+// accountName returns the name of an account number, falling back to the number
+func accountName(number uint32) string {
+	accountNamesMutex.RLock()
+	name, ok := accountNames[number]
+	accountNamesMutex.RUnlock()
+
+	if ok {
+		return name
+	}
+	if number == importedAccountNumber {
+		return "imported"
+	}
+	return fmt.Sprintf("account-%d", number)
+}
+
+// convertTransactionDetails converts a gRPC transaction notification into the
+// API transaction type. The amount is the net effect on the wallet balance.
+func convertTransactionDetails(ctx context.Context, details *pb.TransactionDetails) types.Transaction {
+	var credited, debited int64
+	for _, debit := range details.Debits {
+		debited += debit.PreviousAmount
+	}
+	for _, credit := range details.Credits {
+		credited += credit.Amount
+	}
+	net := credited - debited
+
+	tx := types.Transaction{
+		TxID: hashString(details.Hash),
+		Time: time.Unix(details.Timestamp, 0),
+	}
+	if debited > 0 {
+		tx.Fee = float64(details.Fee) / 1e8
+	}
+
+	tx.TxType = transactionTypeName(details.TransactionType)
+	tx.Generated = details.TransactionType == pb.TransactionDetails_VOTE ||
+		details.TransactionType == pb.TransactionDetails_COINBASE
+
+	switch {
+	case details.TransactionType == pb.TransactionDetails_COINBASE:
+		tx.Category = "generate"
+	case net < 0:
+		tx.Category = "send"
+	default:
+		tx.Category = "receive"
+	}
+	tx.Amount = float64(net) / 1e8
+
+	// Prefer an external (receiving) credit for the displayed address and account
+	for _, credit := range details.Credits {
+		if !credit.Internal {
+			tx.Address = credit.Address
+			tx.Account = accountName(credit.Account)
+			tx.Vout = credit.Index
+			break
+		}
+	}
+	if tx.Account == "" {
+		if len(details.Credits) > 0 {
+			tx.Account = accountName(details.Credits[0].Account)
+			tx.Vout = details.Credits[0].Index
+		} else if len(details.Debits) > 0 {
+			tx.Account = accountName(details.Debits[0].PreviousAccount)
+		}
+	}
+
+	if details.TransactionType == pb.TransactionDetails_REGULAR {
+		var msgTx wire.MsgTx
+		if err := msgTx.FromBytes(details.Transaction); err == nil {
+			tx.IsMixed = classifyCoinJoinTx(tx.TxID, &msgTx)
+		} else {
+			tx.IsMixed = isCoinJoinTransaction(ctx, tx.TxID)
+		}
+	}
+
+	return tx
+}
+
+// transactionTypeName maps a gRPC transaction type to the listtransactions txtype names
+func transactionTypeName(t pb.TransactionDetails_TransactionType) string {
+	switch t {
+	case pb.TransactionDetails_TICKET_PURCHASE:
+		return "ticket"
+	case pb.TransactionDetails_VOTE:
+		return "vote"
+	case pb.TransactionDetails_REVOCATION:
+		return "revocation"
+	}
+	return "regular"
+}
+
+// hashString formats a serialized hash in the usual byte-reversed hex notation
+func hashString(b []byte) string {
+	hash, err := chainhash.NewHash(b)
+	if err != nil {
+		return ""
+	}
+	return hash.String()
+}
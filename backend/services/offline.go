@@ -0,0 +1,568 @@
+// Copyright (c) 2015-2025 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/decred/dcrd/chaincfg/v3"
+	"github.com/decred/dcrd/dcrutil/v4"
+	"github.com/decred/dcrd/txscript/v4/stdscript"
+	"github.com/decred/dcrd/wire"
+
+	"decred-pulse-backend/rpc"
+	"decred-pulse-backend/types"
+	"decred-pulse-backend/utils"
+)
+
+const (
+	// offlineTxTTL is how long an exported transaction waits for its
+	// signed version. Its inputs stay locked until then.
+	offlineTxTTL = 7 * 24 * time.Hour
+
+	// offlineTxRetention is how long broadcast transactions are kept
+	offlineTxRetention = 30 * 24 * time.Hour
+
+	// offlineTxCheckInterval is how often expired transactions are pruned
+	// and the inputs of prepared ones re-locked
+	offlineTxCheckInterval = time.Minute
+
+	offlineTxVersion = 1
+)
+
+var (
+	// ErrOfflineTxNotFound is returned for unknown or expired offline transactions
+	ErrOfflineTxNotFound = errors.New("offline transaction not found or expired")
+
+	// ErrOfflineTxBroadcast is returned when an offline transaction was
+	// already broadcast
+	ErrOfflineTxBroadcast = errors.New("offline transaction already broadcast")
+
+	// ErrSignedTxMismatch is returned when a signed transaction differs from
+	// the exported one
+	ErrSignedTxMismatch = errors.New("signed transaction does not match the prepared transaction")
+
+	// ErrOutputReserved is returned when an output is an input of a prepared
+	// offline transaction, which would fail to broadcast if it were spent
+	ErrOutputReserved = errors.New("output is reserved by a prepared offline transaction")
+
+	// ErrOfflineInputSpent is returned when an input of an offline
+	// transaction was spent by another transaction
+	ErrOfflineInputSpent = errors.New("offline transaction input already spent")
+)
+
+// offlineTxStore is the persisted offline transaction state
+type offlineTxStore struct {
+	Version      int                                  `json:"version"`
+	Transactions map[string]*types.OfflineTransaction `json:"transactions"`
+}
+
+var (
+	offlineTxMutex sync.Mutex
+	offlineTxs     = make(map[string]*types.OfflineTransaction)
+	offlineTxPath  string
+
+	offlineTxWatcherOnce sync.Once
+)
+
+// InitOfflineTransactions loads persisted offline transactions from path and
+// enables persistence
+func InitOfflineTransactions(path string) error {
+	offlineTxMutex.Lock()
+	defer offlineTxMutex.Unlock()
+
+	offlineTxPath = path
+
+	var stored offlineTxStore
+	found, err := utils.ReadJSONFile(path, &stored)
+	if err != nil {
+		return err
+	}
+	if !found || stored.Version != offlineTxVersion || stored.Transactions == nil {
+		return nil
+	}
+	offlineTxs = stored.Transactions
+	log.Printf("Loaded %d offline transactions", len(offlineTxs))
+	return nil
+}
+
+// StartOfflineTxWatcher releases the inputs of expired offline transactions
+// and re-locks those of prepared ones, which dcrwallet forgets when it
+// restarts. Checks run at startup and every offlineTxCheckInterval.
+func StartOfflineTxWatcher() {
+	offlineTxWatcherOnce.Do(func() {
+		go func() {
+			ticker := time.NewTicker(offlineTxCheckInterval)
+			defer ticker.Stop()
+			for {
+				if rpc.WalletClient != nil {
+					ctx, cancel := context.WithTimeout(context.Background(), offlineTxCheckInterval)
+					releaseExpiredOfflineTransactions(ctx)
+					relockOfflineInputs(ctx)
+					cancel()
+				}
+				<-ticker.C
+			}
+		}()
+	})
+}
+
+// PrepareOfflineTransaction builds an unsigned transaction, typically from a
+// watch-only account, and exports it with the previous output scripts and
+// derivation paths an offline signer needs. Its inputs are locked so other
+// sends do not spend them while it is signed.
+func PrepareOfflineTransaction(ctx context.Context, req types.SendPrepareRequest) (*types.OfflineTransaction, error) {
+	if rpc.WalletClient == nil {
+		return nil, fmt.Errorf("wallet RPC client not initialized")
+	}
+
+	resp, preview, err := constructSend(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	params, err := ActiveNetParams(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var tx wire.MsgTx
+	if err := tx.FromBytes(resp.UnsignedTransaction); err != nil {
+		return nil, fmt.Errorf("failed to decode unsigned transaction: %w", err)
+	}
+
+	inputs, err := offlineInputs(ctx, &tx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := newPreviewToken()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	offline := &types.OfflineTransaction{
+		ID:            id,
+		Status:        types.OfflineTxStatusPrepared,
+		Network:       preview.Network,
+		Account:       preview.Account,
+		UnsignedHex:   hex.EncodeToString(resp.UnsignedTransaction),
+		Inputs:        inputs,
+		Outputs:       preview.Outputs,
+		TotalInput:    preview.TotalInput,
+		TotalSent:     preview.TotalSent,
+		Fee:           preview.Fee,
+		FeeRate:       preview.FeeRate,
+		EstimatedSize: preview.EstimatedSize,
+		CreatedAt:     now,
+		ExpiresAt:     now.Add(offlineTxTTL),
+	}
+
+	if err := LockUTXOs(ctx, offlineOutpoints(offline), false); err != nil {
+		return nil, fmt.Errorf("failed to lock inputs: %w", err)
+	}
+
+	offlineTxMutex.Lock()
+	offlineTxs[id] = offline
+	exported := *offline
+	offlineTxMutex.Unlock()
+	persistOfflineTransactions()
+
+	log.Printf("Offline transaction %s prepared: %d inputs, %s DCR sent", id, len(inputs), formatDCR(offline.TotalSent))
+	return &exported, nil
+}
+
+// offlineInputs describes the previous outputs spent by tx
+func offlineInputs(ctx context.Context, tx *wire.MsgTx, params *chaincfg.Params) ([]types.OfflineInput, error) {
+	wallet, err := fetchWalletInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	inputs := make([]types.OfflineInput, 0, len(tx.TxIn))
+	for _, in := range tx.TxIn {
+		op := in.PreviousOutPoint
+		prevTx, err := fetchMsgTx(ctx, op.Hash.String())
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch input %s: %w", op, err)
+		}
+		if int(op.Index) >= len(prevTx.TxOut) {
+			return nil, fmt.Errorf("input %s does not exist", op)
+		}
+		prevOut := prevTx.TxOut[op.Index]
+
+		input := types.OfflineInput{
+			TxID:          op.Hash.String(),
+			Vout:          op.Index,
+			Tree:          op.Tree,
+			Amount:        dcrutil.Amount(prevOut.Value).ToCoin(),
+			PkScript:      hex.EncodeToString(prevOut.PkScript),
+			ScriptVersion: prevOut.Version,
+		}
+		if _, addrs := stdscript.ExtractAddrs(prevOut.Version, prevOut.PkScript, params); len(addrs) > 0 {
+			input.Address = addrs[0].String()
+			info, err := fetchAddressInfo(ctx, input.Address)
+			if err == nil && info.Branch != nil && info.Index != nil {
+				input.Path = bip44Path(wallet.CoinType, info.AccountN, *info.Branch, *info.Index)
+			}
+		}
+		inputs = append(inputs, input)
+	}
+	return inputs, nil
+}
+
+// GetOfflineTransaction returns an offline transaction by ID
+func GetOfflineTransaction(id string) (*types.OfflineTransaction, error) {
+	offlineTxMutex.Lock()
+	defer offlineTxMutex.Unlock()
+
+	offline, ok := offlineTxs[id]
+	if !ok || offlineTxExpired(offline, time.Now()) {
+		return nil, ErrOfflineTxNotFound
+	}
+	copied := *offline
+	return &copied, nil
+}
+
+// ListOfflineTransactions returns the offline transactions, newest first
+func ListOfflineTransactions() *types.OfflineTransactionListResponse {
+	offlineTxMutex.Lock()
+	now := time.Now()
+	list := make([]types.OfflineTransaction, 0, len(offlineTxs))
+	for _, offline := range offlineTxs {
+		if !offlineTxExpired(offline, now) {
+			list = append(list, *offline)
+		}
+	}
+	offlineTxMutex.Unlock()
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].CreatedAt.After(list[j].CreatedAt)
+	})
+	return &types.OfflineTransactionListResponse{Transactions: list}
+}
+
+// SubmitOfflineTransaction checks that a transaction signed offline matches
+// the exported one (inputs, outputs, and so the fee) and broadcasts it with
+// sendrawtransaction, through dcrd or, without dcrd (SPV), through the
+// wallet's peers. The node verifies the signatures.
+func SubmitOfflineTransaction(ctx context.Context, id, signedHex string) (*types.OfflineTransaction, error) {
+	if rpc.DcrdClient == nil && rpc.WalletClient == nil {
+		return nil, fmt.Errorf("no dcrd or wallet RPC client to broadcast with")
+	}
+
+	offline, err := GetOfflineTransaction(id)
+	if err != nil {
+		return nil, err
+	}
+	if offline.Status == types.OfflineTxStatusBroadcast {
+		return nil, ErrOfflineTxBroadcast
+	}
+
+	unsignedBytes, err := hex.DecodeString(offline.UnsignedHex)
+	if err != nil {
+		return nil, fmt.Errorf("stored transaction is corrupt: %w", err)
+	}
+	var unsigned wire.MsgTx
+	if err := unsigned.FromBytes(unsignedBytes); err != nil {
+		return nil, fmt.Errorf("stored transaction is corrupt: %w", err)
+	}
+	signedBytes, err := hex.DecodeString(signedHex)
+	if err != nil {
+		return nil, fmt.Errorf("%w: not hex encoded", ErrSignedTxMismatch)
+	}
+	var signed wire.MsgTx
+	if err := signed.FromBytes(signedBytes); err != nil {
+		return nil, fmt.Errorf("%w: not a transaction: %v", ErrSignedTxMismatch, err)
+	}
+	if err := compareSignedTransaction(&unsigned, &signed); err != nil {
+		return nil, err
+	}
+	if err := checkOfflineInputsUnspent(ctx, &unsigned); err != nil {
+		return nil, err
+	}
+
+	txid, err := broadcastTransaction(ctx, &signed)
+	if err != nil {
+		return nil, fmt.Errorf("failed to broadcast transaction: %w", err)
+	}
+
+	now := time.Now()
+	offlineTxMutex.Lock()
+	stored, ok := offlineTxs[id]
+	if ok {
+		stored.Status = types.OfflineTxStatusBroadcast
+		stored.TxID = txid
+		stored.BroadcastAt = &now
+		offline = stored
+	}
+	result := *offline
+	offlineTxMutex.Unlock()
+	persistOfflineTransactions()
+
+	log.Printf("Offline transaction %s broadcast as %s", id, txid)
+	return &result, nil
+}
+
+// compareSignedTransaction checks that signed spends the same inputs and pays
+// the same outputs as unsigned, and that every input carries a signature script
+func compareSignedTransaction(unsigned, signed *wire.MsgTx) error {
+	if signed.Version != unsigned.Version || signed.LockTime != unsigned.LockTime || signed.Expiry != unsigned.Expiry {
+		return fmt.Errorf("%w: version, lock time or expiry changed", ErrSignedTxMismatch)
+	}
+	if len(signed.TxIn) != len(unsigned.TxIn) {
+		return fmt.Errorf("%w: %d inputs, expected %d", ErrSignedTxMismatch, len(signed.TxIn), len(unsigned.TxIn))
+	}
+	for i, in := range signed.TxIn {
+		expected := unsigned.TxIn[i]
+		if in.PreviousOutPoint != expected.PreviousOutPoint || in.Sequence != expected.Sequence {
+			return fmt.Errorf("%w: input %d spends %s, expected %s", ErrSignedTxMismatch, i, in.PreviousOutPoint, expected.PreviousOutPoint)
+		}
+		if len(in.SignatureScript) == 0 {
+			return fmt.Errorf("%w: input %d is not signed", ErrSignedTxMismatch, i)
+		}
+	}
+	if len(signed.TxOut) != len(unsigned.TxOut) {
+		return fmt.Errorf("%w: %d outputs, expected %d", ErrSignedTxMismatch, len(signed.TxOut), len(unsigned.TxOut))
+	}
+	for i, out := range signed.TxOut {
+		expected := unsigned.TxOut[i]
+		if out.Value != expected.Value || out.Version != expected.Version || !bytes.Equal(out.PkScript, expected.PkScript) {
+			return fmt.Errorf("%w: output %d differs", ErrSignedTxMismatch, i)
+		}
+	}
+	return nil
+}
+
+// checkOfflineInputsUnspent checks that no input of tx was spent in the
+// meantime, for example while its lock was lost to a wallet restart
+func checkOfflineInputsUnspent(ctx context.Context, tx *wire.MsgTx) error {
+	for _, in := range tx.TxIn {
+		op := in.PreviousOutPoint
+		unspent, err := outputUnspent(ctx, op)
+		if err != nil {
+			return fmt.Errorf("failed to check input %s: %w", op, err)
+		}
+		if !unspent {
+			return fmt.Errorf("%w: %s", ErrOfflineInputSpent, op)
+		}
+	}
+	return nil
+}
+
+// outputUnspent reports whether an output is unspent, including by mempool
+// transactions. dcrwallet answers gettxout from its own UTXO set in SPV mode.
+func outputUnspent(ctx context.Context, op wire.OutPoint) (bool, error) {
+	if rpc.WalletClient == nil {
+		txOut, err := rpc.DcrdClient.GetTxOut(ctx, &op.Hash, op.Index, op.Tree, true)
+		if err != nil {
+			return false, err
+		}
+		return txOut != nil, nil
+	}
+
+	params, err := marshalParams(op.Hash.String(), op.Index, op.Tree, true)
+	if err != nil {
+		return false, err
+	}
+	result, err := rpc.WalletClient.RawRequest(ctx, "gettxout", params)
+	if err != nil {
+		return false, err
+	}
+	return len(result) > 0 && string(result) != "null", nil
+}
+
+// broadcastTransaction publishes a signed transaction through dcrd, or
+// through dcrwallet when Pulse has no dcrd connection
+func broadcastTransaction(ctx context.Context, tx *wire.MsgTx) (string, error) {
+	if rpc.DcrdClient != nil {
+		hash, err := rpc.DcrdClient.SendRawTransaction(ctx, tx, false)
+		if err != nil {
+			return "", err
+		}
+		return hash.String(), nil
+	}
+
+	txHex, err := tx.Bytes()
+	if err != nil {
+		return "", err
+	}
+	params, err := marshalParams(hex.EncodeToString(txHex))
+	if err != nil {
+		return "", err
+	}
+	result, err := rpc.WalletClient.RawRequest(ctx, "sendrawtransaction", params)
+	if err != nil {
+		return "", err
+	}
+	var txid string
+	if err := json.Unmarshal(result, &txid); err != nil {
+		return "", fmt.Errorf("invalid sendrawtransaction result: %w", err)
+	}
+	return txid, nil
+}
+
+// CancelOfflineTransaction discards a prepared offline transaction and
+// unlocks its inputs
+func CancelOfflineTransaction(ctx context.Context, id string) error {
+	offlineTxMutex.Lock()
+	offline, ok := offlineTxs[id]
+	if !ok || offlineTxExpired(offline, time.Now()) {
+		offlineTxMutex.Unlock()
+		return ErrOfflineTxNotFound
+	}
+	if offline.Status == types.OfflineTxStatusBroadcast {
+		offlineTxMutex.Unlock()
+		return ErrOfflineTxBroadcast
+	}
+	delete(offlineTxs, id)
+	offlineTxMutex.Unlock()
+	persistOfflineTransactions()
+
+	if rpc.WalletClient != nil {
+		if err := LockUTXOs(ctx, offlineOutpoints(offline), true); err != nil {
+			log.Printf("Warning: Failed to unlock inputs of offline transaction %s: %v", id, err)
+		}
+	}
+	return nil
+}
+
+// releaseExpiredOfflineTransactions drops expired offline transactions and
+// unlocks the inputs of those never broadcast
+func releaseExpiredOfflineTransactions(ctx context.Context) {
+	offlineTxMutex.Lock()
+	expired := pruneOfflineTransactions(time.Now())
+	offlineTxMutex.Unlock()
+	if len(expired) == 0 {
+		return
+	}
+	persistOfflineTransactions()
+
+	for _, e := range expired {
+		if err := LockUTXOs(ctx, offlineOutpoints(e), true); err != nil {
+			log.Printf("Warning: Failed to unlock inputs of expired offline transaction %s: %v", e.ID, err)
+		}
+	}
+}
+
+// relockOfflineInputs locks the inputs of prepared offline transactions that
+// dcrwallet no longer has locked, typically after it restarted. Coin selection
+// calls this first so the air-gapped signature stays valid.
+func relockOfflineInputs(ctx context.Context) {
+	now := time.Now()
+	var prepared []types.Outpoint
+	offlineTxMutex.Lock()
+	for _, offline := range offlineTxs {
+		if offline.Status == types.OfflineTxStatusPrepared && !offlineTxExpired(offline, now) {
+			prepared = append(prepared, offlineOutpoints(offline)...)
+		}
+	}
+	offlineTxMutex.Unlock()
+	if len(prepared) == 0 || rpc.WalletClient == nil {
+		return
+	}
+
+	locked, err := fetchLockedUnspent(ctx)
+	if err != nil {
+		log.Printf("Warning: Could not check offline transaction input locks: %v", err)
+		return
+	}
+	lockedSet := make(map[string]bool, len(locked))
+	for _, op := range locked {
+		lockedSet[fmt.Sprintf("%s:%d", op.TxID, op.Vout)] = true
+	}
+	var missing []types.Outpoint
+	for _, op := range prepared {
+		if !lockedSet[fmt.Sprintf("%s:%d", op.TxID, op.Vout)] {
+			missing = append(missing, op)
+		}
+	}
+	if len(missing) == 0 {
+		return
+	}
+
+	log.Printf("Re-locking %d input(s) of prepared offline transactions", len(missing))
+	if err := LockUTXOs(ctx, missing, false); err != nil {
+		log.Printf("Warning: Failed to re-lock offline transaction inputs: %v", err)
+	}
+}
+
+// checkOutputsNotReserved fails with ErrOutputReserved when an output is an
+// input of a prepared offline transaction
+func checkOutputsNotReserved(outputs []types.Outpoint) error {
+	now := time.Now()
+	offlineTxMutex.Lock()
+	defer offlineTxMutex.Unlock()
+
+	for _, offline := range offlineTxs {
+		if offline.Status != types.OfflineTxStatusPrepared || offlineTxExpired(offline, now) {
+			continue
+		}
+		for _, in := range offline.Inputs {
+			for _, op := range outputs {
+				if op.TxID == in.TxID && op.Vout == in.Vout {
+					return fmt.Errorf("%w: %s:%d belongs to %s", ErrOutputReserved, op.TxID, op.Vout, offline.ID)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// offlineTxExpired reports whether an offline transaction is past its expiry
+// or, once broadcast, past its retention
+func offlineTxExpired(offline *types.OfflineTransaction, now time.Time) bool {
+	if offline.BroadcastAt != nil {
+		return now.After(offline.BroadcastAt.Add(offlineTxRetention))
+	}
+	return now.After(offline.ExpiresAt)
+}
+
+// pruneOfflineTransactions drops expired offline transactions and returns
+// those never broadcast, whose inputs are still locked. Callers must hold
+// offlineTxMutex.
+func pruneOfflineTransactions(now time.Time) []*types.OfflineTransaction {
+	var expired []*types.OfflineTransaction
+	for id, offline := range offlineTxs {
+		if !offlineTxExpired(offline, now) {
+			continue
+		}
+		delete(offlineTxs, id)
+		if offline.Status == types.OfflineTxStatusPrepared {
+			expired = append(expired, offline)
+		}
+	}
+	return expired
+}
+
+// offlineOutpoints returns the inputs of an offline transaction
+func offlineOutpoints(offline *types.OfflineTransaction) []types.Outpoint {
+	outpoints := make([]types.Outpoint, len(offline.Inputs))
+	for i, in := range offline.Inputs {
+		outpoints[i] = types.Outpoint{TxID: in.TxID, Vout: in.Vout, Tree: in.Tree}
+	}
+	return outpoints
+}
+
+// persistOfflineTransactions writes the offline transactions to disk
+func persistOfflineTransactions() {
+	offlineTxMutex.Lock()
+	defer offlineTxMutex.Unlock()
+
+	if offlineTxPath == "" {
+		return
+	}
+	stored := offlineTxStore{Version: offlineTxVersion, Transactions: offlineTxs}
+	if err := utils.WriteJSONFile(offlineTxPath, stored); err != nil {
+		log.Printf("Warning: Failed to persist offline transactions: %v", err)
+	}
+}
@@ -0,0 +1,37 @@
+// Copyright (c) 2015-2025 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package services
+
+// pageWindow is one page of a list of results
+type pageWindow struct {
+	page, pageSize, totalPages int
+	start, end                 int // Bounds of the page in the list
+}
+
+// paginate returns the bounds of a page of n results. Pages start at 1 and
+// pageSize falls back to defaultPageSize when unset.
+func paginate(n, page, pageSize, defaultPageSize int) pageWindow {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = defaultPageSize
+	}
+
+	window := pageWindow{
+		page:       page,
+		pageSize:   pageSize,
+		totalPages: (n + pageSize - 1) / pageSize,
+		start:      (page - 1) * pageSize,
+	}
+	if window.start > n {
+		window.start = n
+	}
+	window.end = window.start + pageSize
+	if window.end > n {
+		window.end = n
+	}
+	return window
+}
@@ -0,0 +1,385 @@
+// Copyright (c) 2015-2025 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	pb "decred.org/dcrwallet/v4/rpc/walletrpc"
+
+	"decred-pulse-backend/jobs"
+	"decred-pulse-backend/rpc"
+	"decred-pulse-backend/types"
+	"decred-pulse-backend/utils"
+)
+
+// Rescan controller states
+const (
+	RescanStateIdle        = "idle"
+	RescanStateDiscovering = "discovering"
+	RescanStateRescanning  = "rescanning"
+	RescanStateCompleted   = "completed"
+	RescanStateFailed      = "failed"
+	RescanStateCancelled   = "cancelled"
+)
+
+// ErrRescanInProgress is returned when a rescan is requested while another
+// one is active and queueing was not requested
+var ErrRescanInProgress = errors.New("a rescan is already in progress")
+
+// The rescan controller owns the single active wallet rescan. Progress is
+// recorded from the authoritative RescannedThrough values of the gRPC Rescan
+// stream rather than inferred from wallet/chain height deltas.
+var (
+	rescanMutex  sync.Mutex
+	rescanStatus = types.RescanStatus{State: RescanStateIdle, Progress: 100}
+	rescanJobID  string // Job owning the active rescan
+	rescanQueued int    // Rescans started but not yet holding the rescan slot
+
+	// Holds a token while a rescan owns the wallet
+	rescanSlot = make(chan struct{}, 1)
+
+	rescanSubscribersMutex sync.Mutex
+	rescanSubscribers      []chan types.RescanStatus
+)
+
+// rescanSteps maps rescan phases to job step indexes
+type rescanSteps struct {
+	discover int // Address discovery step, -1 to skip discovery
+	rescan   int // Block rescan step
+}
+
+// StartRescan starts a wallet rescan from beginHeight as a tracked job.
+// If a rescan is already active, ErrRescanInProgress is returned unless queue
+// is set, in which case the new rescan waits for the active one to finish.
+func StartRescan(beginHeight int32, queue bool) (types.Job, error) {
+	if rpc.WalletGrpcClient == nil {
+		return types.Job{}, fmt.Errorf("wallet gRPC client not initialized")
+	}
+
+	if err := reserveRescan(queue); err != nil {
+		return types.Job{}, err
+	}
+
+	steps := []string{"Discover address usage", "Rescan blockchain"}
+	job := jobs.Start("wallet_rescan", steps, func(ctx context.Context, job *jobs.Handle) (interface{}, error) {
+		return runRescan(ctx, job, beginHeight, rescanSteps{discover: 0, rescan: 1})
+	})
+	return job, nil
+}
+
+// reserveRescan counts a new rescan as queued until it holds the rescan slot,
+// so concurrent requests see it before its job starts. Unless queue is set it
+// fails with ErrRescanInProgress while another rescan is active or queued.
+func reserveRescan(queue bool) error {
+	rescanMutex.Lock()
+	defer rescanMutex.Unlock()
+
+	if !queue && (rescanJobID != "" || rescanQueued > 0) {
+		return ErrRescanInProgress
+	}
+	rescanQueued++
+	return nil
+}
+
+// CancelRescan cancels the active rescan, if any
+func CancelRescan() error {
+	rescanMutex.Lock()
+	jobID := rescanJobID
+	rescanMutex.Unlock()
+
+	if jobID == "" {
+		return fmt.Errorf("no rescan in progress")
+	}
+	return jobs.Cancel(jobID)
+}
+
+// GetRescanStatus returns a snapshot of the rescan controller state
+func GetRescanStatus() types.RescanStatus {
+	rescanMutex.Lock()
+	defer rescanMutex.Unlock()
+
+	status := rescanStatus
+	status.Queued = rescanQueued
+	return status
+}
+
+// IsRescanActive reports whether a rescan currently owns the wallet
+func IsRescanActive() bool {
+	rescanMutex.Lock()
+	defer rescanMutex.Unlock()
+	return rescanJobID != ""
+}
+
+// SubscribeRescanStatus returns a channel receiving every rescan status change
+func SubscribeRescanStatus() chan types.RescanStatus {
+	ch := make(chan types.RescanStatus, 10)
+	rescanSubscribersMutex.Lock()
+	rescanSubscribers = append(rescanSubscribers, ch)
+	rescanSubscribersMutex.Unlock()
+	return ch
+}
+
+// UnsubscribeRescanStatus stops delivery of rescan status changes to ch
+func UnsubscribeRescanStatus(ch chan types.RescanStatus) {
+	rescanSubscribersMutex.Lock()
+	defer rescanSubscribersMutex.Unlock()
+
+	for i, c := range rescanSubscribers {
+		if c == ch {
+			rescanSubscribers = append(rescanSubscribers[:i], rescanSubscribers[i+1:]...)
+			break
+		}
+	}
+}
+
+// runRescan acquires the rescan slot and performs address discovery (if
+// requested) followed by a gRPC rescan, recording progress in the controller
+// and in the owning job. It blocks until the rescan finishes or ctx is done.
+// The caller must have reserved the rescan with reserveRescan.
+func runRescan(ctx context.Context, job *jobs.Handle, beginHeight int32, steps rescanSteps) (*types.RescanStatus, error) {
+	if err := acquireRescanSlot(ctx, job); err != nil {
+		return nil, err
+	}
+	defer func() { <-rescanSlot }()
+
+	now := time.Now()
+	updateRescanStatus(func(s *types.RescanStatus) {
+		*s = types.RescanStatus{
+			State:        RescanStateDiscovering,
+			IsRescanning: true,
+			JobID:        job.ID(),
+			BeginHeight:  int64(beginHeight),
+			ScanHeight:   int64(beginHeight),
+			StartedAt:    &now,
+			Message:      "Discovering address usage...",
+		}
+	})
+
+	rescanErr := performRescan(ctx, job, beginHeight, steps)
+
+	finished := time.Now()
+	final := updateRescanStatus(func(s *types.RescanStatus) {
+		s.IsRescanning = false
+		s.FinishedAt = &finished
+		s.BlocksPerSecond = 0
+		s.ETASeconds = 0
+		s.ETA = ""
+		switch {
+		case rescanErr != nil && ctx.Err() != nil:
+			s.State = RescanStateCancelled
+			s.Error = "cancelled"
+			s.Message = fmt.Sprintf("Rescan cancelled at block %d", s.ScanHeight)
+		case rescanErr != nil:
+			s.State = RescanStateFailed
+			s.Error = rescanErr.Error()
+			s.Message = "Rescan failed: " + rescanErr.Error()
+		default:
+			s.State = RescanStateCompleted
+			s.Progress = 100
+			s.Message = "Rescan completed, wallet fully synced"
+		}
+	})
+
+	rescanMutex.Lock()
+	rescanJobID = ""
+	rescanMutex.Unlock()
+
+	if rescanErr != nil {
+		return nil, rescanErr
+	}
+	return &final, nil
+}
+
+// acquireRescanSlot waits until no other rescan owns the wallet, then moves
+// the job's reservation from the queue to the active slot
+func acquireRescanSlot(ctx context.Context, job *jobs.Handle) error {
+	select {
+	case rescanSlot <- struct{}{}:
+	default:
+		broadcastRescanStatus(GetRescanStatus())
+
+		job.SetProgress(0, "Waiting for the active rescan to finish")
+		job.Logf("Another rescan is active - queued")
+
+		select {
+		case rescanSlot <- struct{}{}:
+		case <-ctx.Done():
+			rescanMutex.Lock()
+			rescanQueued--
+			rescanMutex.Unlock()
+			return ctx.Err()
+		}
+	}
+
+	rescanMutex.Lock()
+	rescanQueued--
+	rescanJobID = job.ID()
+	rescanMutex.Unlock()
+	return nil
+}
+
+// performRescan runs the discovery and rescan phases
+func performRescan(ctx context.Context, job *jobs.Handle, beginHeight int32, steps rescanSteps) error {
+	// Phase 1: Discover address usage via JSON-RPC
+	if steps.discover >= 0 {
+		job.BeginStep(steps.discover)
+		job.Logf("Discovering address usage across blockchain for all accounts...")
+		if _, err := rpc.WalletClient.RawRequest(ctx, "discoverusage", nil); err != nil {
+			return fmt.Errorf("failed to discover address usage: %w", err)
+		}
+		job.Logf("Address discovery completed - wallet database updated")
+
+		// Give the wallet time to load its transaction filter
+		job.Logf("Waiting 5 seconds for wallet to load transaction filter...")
+		select {
+		case <-time.After(5 * time.Second):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	// Phase 2: Rescan blocks via gRPC - this provides a progress stream
+	job.BeginStep(steps.rescan)
+
+	// The rescan runs up to the wallet's current tip
+	targetHeight := int64(0)
+	if _, height, err := rpc.WalletClient.GetBestBlock(ctx); err == nil {
+		targetHeight = height
+	}
+
+	stream, err := rpc.WalletGrpcClient.Rescan(ctx, &pb.RescanRequest{BeginHeight: beginHeight})
+	if err != nil {
+		return fmt.Errorf("failed to start gRPC rescan: %w", err)
+	}
+
+	rescanStart := time.Now()
+	updateRescanStatus(func(s *types.RescanStatus) {
+		s.State = RescanStateRescanning
+		s.ChainHeight = targetHeight
+		s.Progress = 0
+		s.Message = fmt.Sprintf("Rescanning blockchain from block %d...", beginHeight)
+	})
+	job.Logf("gRPC rescan stream started from block %d (target %d)", beginHeight, targetHeight)
+
+	for {
+		update, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return fmt.Errorf("gRPC rescan stream error: %w", err)
+		}
+
+		scanned := int64(update.RescannedThrough)
+		status := updateRescanStatus(func(s *types.RescanStatus) {
+			recordRescanProgress(s, scanned, rescanStart)
+		})
+		job.SetProgress(status.Progress, status.Message)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	job.Logf("Rescan completed - all transactions imported")
+	return nil
+}
+
+// recordRescanProgress computes progress, throughput and ETA from the latest
+// RescannedThrough height
+func recordRescanProgress(s *types.RescanStatus, scanned int64, rescanStart time.Time) {
+	s.ScanHeight = scanned
+	if scanned > s.ChainHeight {
+		s.ChainHeight = scanned
+	}
+
+	total := s.ChainHeight - s.BeginHeight
+	done := scanned - s.BeginHeight
+	if total > 0 {
+		s.Progress = float64(done) / float64(total) * 100
+	}
+
+	elapsed := time.Since(rescanStart).Seconds()
+	if elapsed > 0 && done > 0 {
+		s.BlocksPerSecond = float64(done) / elapsed
+		remaining := s.ChainHeight - scanned
+		s.ETASeconds = int64(float64(remaining) / s.BlocksPerSecond)
+		s.ETA = utils.FormatDuration(s.ETASeconds)
+	}
+
+	s.Message = fmt.Sprintf("Rescanning... %d/%d blocks (%.1f%%)", scanned, s.ChainHeight, s.Progress)
+}
+
+// updateRescanStatus applies fn to the controller state and broadcasts the result
+func updateRescanStatus(fn func(s *types.RescanStatus)) types.RescanStatus {
+	rescanMutex.Lock()
+	fn(&rescanStatus)
+	status := rescanStatus
+	status.Queued = rescanQueued
+	rescanMutex.Unlock()
+
+	broadcastRescanStatus(status)
+	return status
+}
+
+// broadcastRescanStatus sends the status to all subscribers without blocking
+func broadcastRescanStatus(status types.RescanStatus) {
+	rescanSubscribersMutex.Lock()
+	defer rescanSubscribersMutex.Unlock()
+
+	for _, ch := range rescanSubscribers {
+		select {
+		case ch <- status:
+		default:
+			// Channel full, skip
+		}
+	}
+}
+
+// StartXpubImport imports an xpub into a new watch-only account, discovers its
+// address usage and rescans the chain from genesis, as a single tracked job.
+// The rescan phase queues behind any rescan that is already running.
+func StartXpubImport(accountName, xpub string) types.Job {
+	steps := []string{"Import xpub", "Discover address usage", "Rescan blockchain"}
+	return jobs.Start("xpub_import", steps, func(ctx context.Context, job *jobs.Handle) (interface{}, error) {
+		// Step 1: Import xpub
+		job.BeginStep(0)
+		params, err := marshalParams(accountName, xpub)
+		if err != nil {
+			return nil, err
+		}
+
+		job.Logf("Importing xpub for account '%s'", accountName)
+		result, err := rpc.WalletClient.RawRequest(ctx, "importxpub", params)
+		if err != nil {
+			return nil, fmt.Errorf("failed to import xpub: %w", err)
+		}
+		job.Logf("Xpub import completed: %v", string(result))
+
+		// Steps 2-3: Discover address usage and rescan from genesis
+		if rpc.WalletGrpcClient == nil {
+			return nil, fmt.Errorf("wallet gRPC client not initialized, cannot rescan")
+		}
+		reserveRescan(true) // Queueing never fails
+		status, err := runRescan(ctx, job, 0, rescanSteps{discover: 1, rescan: 2})
+		if err != nil {
+			return nil, err
+		}
+
+		return map[string]interface{}{
+			"accountName": accountName,
+			"rescan":      status,
+		}, nil
+	})
+}
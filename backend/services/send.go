@@ -0,0 +1,462 @@
+// Copyright (c) 2015-2025 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	pb "decred.org/dcrwallet/v4/rpc/walletrpc"
+	"decred.org/dcrwallet/v4/wallet/txrules"
+	"decred.org/dcrwallet/v4/wallet/txsizes"
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/chaincfg/v3"
+	"github.com/decred/dcrd/dcrutil/v4"
+	"github.com/decred/dcrd/txscript/v4/stdscript"
+	"github.com/decred/dcrd/wire"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"decred-pulse-backend/rpc"
+	"decred-pulse-backend/types"
+)
+
+const (
+	// sendPreviewTTL is how long a prepared transaction can be confirmed
+	sendPreviewTTL = 5 * time.Minute
+
+	// maxSendOutputs bounds the destinations of a single send
+	maxSendOutputs = 100
+
+	// defaultSendConfirmations is the minimum confirmations of spent outputs
+	defaultSendConfirmations = 1
+
+	// maxSendInputs bounds the explicitly selected inputs of a single send
+	maxSendInputs = 500
+
+	// maxSendFeePerKb bounds the requested fee rate at 0.1 DCR/kB, a thousand
+	// times the default relay fee and well within the wallet's int32 field
+	maxSendFeePerKb = dcrutil.Amount(1e7)
+)
+
+var (
+	// ErrPreviewNotFound is returned for unknown, used or expired preview tokens
+	ErrPreviewNotFound = errors.New("preview token not found or expired")
+
+	// ErrWalletLocked is returned when signing needs a passphrase
+	ErrWalletLocked = errors.New("wallet is locked, a passphrase is required")
+)
+
+// pendingSend is a prepared but not yet published transaction
+type pendingSend struct {
+	unsigned []byte
+	preview  types.SendPreview
+}
+
+// Prepared transactions awaiting confirmation, keyed by preview token
+var (
+	pendingSendsMutex sync.Mutex
+	pendingSends      = make(map[string]*pendingSend)
+)
+
+// PrepareSend builds an unsigned transaction paying the requested outputs from
+// the source account and returns a preview with a single-use confirmation token.
+// Nothing is signed or broadcast.
+func PrepareSend(ctx context.Context, req types.SendPrepareRequest) (*types.SendPreview, error) {
+	resp, preview, err := constructSend(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := newPreviewToken()
+	if err != nil {
+		return nil, err
+	}
+	preview.Token = token
+	preview.ExpiresAt = time.Now().Add(sendPreviewTTL)
+
+	pendingSendsMutex.Lock()
+	pruneExpiredSends()
+	pendingSends[token] = &pendingSend{unsigned: resp.UnsignedTransaction, preview: *preview}
+	pendingSendsMutex.Unlock()
+
+	return preview, nil
+}
+
+// constructSend validates a send request and builds the unsigned transaction
+// with its preview, by automatic coin selection or from the selected inputs
+func constructSend(ctx context.Context, req types.SendPrepareRequest) (*pb.ConstructTransactionResponse, *types.SendPreview, error) {
+	if len(req.Outputs) == 0 {
+		return nil, nil, fmt.Errorf("at least one output is required")
+	}
+	if len(req.Outputs) > maxSendOutputs {
+		return nil, nil, fmt.Errorf("too many outputs (max %d)", maxSendOutputs)
+	}
+	if req.FeeRate < 0 {
+		return nil, nil, fmt.Errorf("fee rate must not be negative")
+	}
+
+	params, err := ActiveNetParams(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	account := req.Account
+	if account == "" {
+		account = "default"
+	}
+	accountResp, err := rpc.WalletGrpcClient.AccountNumber(ctx, &pb.AccountNumberRequest{AccountName: account})
+	if err != nil {
+		return nil, nil, fmt.Errorf("unknown account %q: %w", account, err)
+	}
+	// Keep the inputs of offline transactions out of coin selection
+	relockOfflineInputs(ctx)
+
+	outputs := make([]*pb.ConstructTransactionRequest_Output, 0, len(req.Outputs))
+	txOuts := make([]*wire.TxOut, 0, len(req.Outputs))
+	for _, out := range req.Outputs {
+		addr, err := DecodeAddress(ctx, out.Address)
+		if err != nil {
+			return nil, nil, err
+		}
+		amount, err := dcrutil.NewAmount(out.Amount)
+		if err != nil || amount <= 0 {
+			return nil, nil, fmt.Errorf("invalid amount %v for %s", out.Amount, out.Address)
+		}
+		outputs = append(outputs, &pb.ConstructTransactionRequest_Output{
+			Destination: &pb.ConstructTransactionRequest_OutputDestination{Address: out.Address},
+			Amount:      int64(amount),
+		})
+		scriptVersion, pkScript := addr.PaymentScript()
+		txOuts = append(txOuts, &wire.TxOut{Value: int64(amount), Version: scriptVersion, PkScript: pkScript})
+	}
+
+	feePerKb, err := dcrutil.NewAmount(req.FeeRate)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid fee rate: %w", err)
+	}
+	if feePerKb > maxSendFeePerKb {
+		return nil, nil, fmt.Errorf("fee rate must not exceed %s DCR/kB", formatDCR(maxSendFeePerKb.ToCoin()))
+	}
+
+	minConf := req.MinConf
+	if minConf <= 0 {
+		minConf = defaultSendConfirmations
+	}
+
+	var resp *pb.ConstructTransactionResponse
+	if len(req.Inputs) > 0 {
+		resp, err = constructWithInputs(ctx, account, int64(minConf), req.Inputs, txOuts, feePerKb)
+		if err != nil {
+			return nil, nil, err
+		}
+	} else {
+		resp, err = rpc.WalletGrpcClient.ConstructTransaction(ctx, &pb.ConstructTransactionRequest{
+			SourceAccount:         accountResp.AccountNumber,
+			RequiredConfirmations: minConf,
+			FeePerKb:              int32(feePerKb), // 0 uses the wallet's default relay fee
+			NonChangeOutputs:      outputs,
+		})
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to construct transaction: %w", err)
+		}
+	}
+
+	preview, err := buildSendPreview(resp, params)
+	if err != nil {
+		return nil, nil, err
+	}
+	preview.Account = account
+	return resp, preview, nil
+}
+
+// constructWithInputs builds an unsigned transaction spending exactly the
+// selected outputs of account, with change back to the account unless it would
+// be dust. Locked outputs may be selected: locks only exclude outputs from
+// automatic coin selection. Inputs of prepared offline transactions may not.
+func constructWithInputs(ctx context.Context, account string, minConf int64, inputs []types.Outpoint, txOuts []*wire.TxOut, feePerKb dcrutil.Amount) (*pb.ConstructTransactionResponse, error) {
+	if len(inputs) > maxSendInputs {
+		return nil, fmt.Errorf("%w: too many inputs (max %d)", ErrInvalidOutpoint, maxSendInputs)
+	}
+	if rpc.WalletClient == nil {
+		return nil, fmt.Errorf("wallet RPC client not initialized")
+	}
+	if err := checkOutputsNotReserved(inputs); err != nil {
+		return nil, err
+	}
+
+	unspent, err := fetchUnspent(ctx, minConf, account)
+	if err != nil {
+		return nil, err
+	}
+	available := make(map[types.Outpoint]unspentOutput, len(unspent))
+	for _, out := range unspent {
+		if out.Spendable {
+			available[types.Outpoint{TxID: out.TxID, Vout: out.Vout, Tree: out.Tree}] = out
+		}
+	}
+
+	var locked map[types.Outpoint]bool
+	selected := make(map[types.Outpoint]bool, len(inputs))
+	tx := wire.NewMsgTx()
+	var totalIn int64
+	for _, op := range inputs {
+		if selected[op] {
+			return nil, fmt.Errorf("%w: %s:%d is selected twice", ErrInvalidOutpoint, op.TxID, op.Vout)
+		}
+		selected[op] = true
+
+		out, ok := available[op]
+		if !ok {
+			if locked == nil {
+				lockedList, err := fetchLockedUnspent(ctx)
+				if err != nil {
+					return nil, err
+				}
+				locked = make(map[types.Outpoint]bool, len(lockedList))
+				for _, l := range lockedList {
+					locked[l] = true
+				}
+			}
+			if !locked[op] {
+				return nil, fmt.Errorf("%w: %s:%d is not a spendable output of account %q with %d confirmations",
+					ErrInvalidOutpoint, op.TxID, op.Vout, account, minConf)
+			}
+			lockedOut, err := fetchLockedOutput(ctx, op)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrInvalidOutpoint, err)
+			}
+			if lockedOut.Account != account || lockedOut.Confirmations < minConf {
+				return nil, fmt.Errorf("%w: %s:%d is not a spendable output of account %q with %d confirmations",
+					ErrInvalidOutpoint, op.TxID, op.Vout, account, minConf)
+			}
+			out = *lockedOut
+		}
+
+		hash, err := chainhash.NewHashFromStr(op.TxID)
+		if err != nil {
+			return nil, fmt.Errorf("%w: txid %q", ErrInvalidOutpoint, op.TxID)
+		}
+		atoms := toAtoms(out.Amount)
+		tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(hash, op.Vout, op.Tree), atoms, nil))
+		totalIn += atoms
+	}
+	if feePerKb == 0 {
+		if feePerKb, err = fetchRelayFee(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	var totalOut int64
+	for _, out := range txOuts {
+		tx.AddTxOut(out)
+		totalOut += out.Value
+	}
+
+	scriptSizes := make([]int, len(tx.TxIn))
+	for i := range scriptSizes {
+		scriptSizes[i] = txsizes.RedeemP2PKHSigScriptSize
+	}
+	sizeWithoutChange := txsizes.EstimateSerializeSize(scriptSizes, tx.TxOut, 0)
+	feeWithoutChange := int64(txrules.FeeForSerializeSize(feePerKb, sizeWithoutChange))
+	if totalIn < totalOut+feeWithoutChange {
+		return nil, fmt.Errorf("selected inputs (%s DCR) do not cover the outputs and fee (%s DCR)",
+			formatDCR(dcrutil.Amount(totalIn).ToCoin()), formatDCR(dcrutil.Amount(totalOut+feeWithoutChange).ToCoin()))
+	}
+
+	resp := &pb.ConstructTransactionResponse{
+		TotalPreviousOutputAmount: totalIn,
+		EstimatedSignedSize:       uint32(sizeWithoutChange),
+		ChangeIndex:               -1,
+	}
+
+	sizeWithChange := txsizes.EstimateSerializeSize(scriptSizes, tx.TxOut, txsizes.P2PKHPkScriptSize)
+	change := totalIn - totalOut - int64(txrules.FeeForSerializeSize(feePerKb, sizeWithChange))
+	if change > 0 && !txrules.IsDustAmount(dcrutil.Amount(change), txsizes.P2PKHPkScriptSize, feePerKb) {
+		changeAddress, err := GenerateAddress(ctx, types.NewAddressRequest{Account: account, Branch: branchInternal})
+		if err != nil {
+			return nil, err
+		}
+		addr, err := DecodeAddress(ctx, changeAddress.Address)
+		if err != nil {
+			return nil, err
+		}
+		scriptVersion, pkScript := addr.PaymentScript()
+		tx.AddTxOut(&wire.TxOut{Value: change, Version: scriptVersion, PkScript: pkScript})
+		totalOut += change
+		resp.ChangeIndex = int32(len(tx.TxOut) - 1)
+		resp.EstimatedSignedSize = uint32(sizeWithChange)
+	}
+	resp.TotalOutputAmount = totalOut
+
+	unsigned, err := tx.Bytes()
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize transaction: %w", err)
+	}
+	resp.UnsignedTransaction = unsigned
+	return resp, nil
+}
+
+// buildSendPreview decodes the unsigned transaction into a reviewable summary
+func buildSendPreview(resp *pb.ConstructTransactionResponse, params *chaincfg.Params) (*types.SendPreview, error) {
+	var tx wire.MsgTx
+	if err := tx.FromBytes(resp.UnsignedTransaction); err != nil {
+		return nil, fmt.Errorf("failed to decode unsigned transaction: %w", err)
+	}
+
+	preview := &types.SendPreview{
+		Network:       params.Name,
+		Inputs:        make([]types.SendInput, 0, len(tx.TxIn)),
+		Outputs:       make([]types.SendPreviewOutput, 0, len(tx.TxOut)),
+		TotalInput:    dcrutil.Amount(resp.TotalPreviousOutputAmount).ToCoin(),
+		EstimatedSize: resp.EstimatedSignedSize,
+	}
+
+	for _, in := range tx.TxIn {
+		preview.Inputs = append(preview.Inputs, types.SendInput{
+			TxID:   in.PreviousOutPoint.Hash.String(),
+			Vout:   in.PreviousOutPoint.Index,
+			Tree:   in.PreviousOutPoint.Tree,
+			Amount: dcrutil.Amount(in.ValueIn).ToCoin(),
+		})
+	}
+
+	var totalSent int64
+	for i, out := range tx.TxOut {
+		isChange := int32(i) == resp.ChangeIndex
+		address := ""
+		if _, addrs := stdscript.ExtractAddrs(out.Version, out.PkScript, params); len(addrs) > 0 {
+			address = addrs[0].String()
+		}
+		preview.Outputs = append(preview.Outputs, types.SendPreviewOutput{
+			Address:  address,
+			Amount:   dcrutil.Amount(out.Value).ToCoin(),
+			IsChange: isChange,
+		})
+		if !isChange {
+			totalSent += out.Value
+		}
+	}
+
+	fee := resp.TotalPreviousOutputAmount - resp.TotalOutputAmount
+	preview.TotalSent = dcrutil.Amount(totalSent).ToCoin()
+	preview.Fee = dcrutil.Amount(fee).ToCoin()
+	if resp.EstimatedSignedSize > 0 {
+		preview.FeeRate = dcrutil.Amount(fee * 1000 / int64(resp.EstimatedSignedSize)).ToCoin()
+	}
+
+	return preview, nil
+}
+
+// ConfirmSend signs and publishes a prepared transaction. With an empty
+// passphrase the wallet must already be unlocked.
+func ConfirmSend(ctx context.Context, token, passphrase string) (*types.SendResult, error) {
+	pending, err := takePendingSend(token)
+	if err != nil {
+		return nil, err
+	}
+
+	signed, err := signTransaction(ctx, pending.unsigned, passphrase)
+	if err != nil {
+		// Signing failures (wrong passphrase, locked wallet) can be retried
+		if errors.Is(err, ErrIncorrectPassphrase) || errors.Is(err, ErrWalletLocked) {
+			restorePendingSend(token, pending)
+		}
+		return nil, err
+	}
+
+	resp, err := rpc.WalletGrpcClient.PublishTransaction(ctx, &pb.PublishTransactionRequest{SignedTransaction: signed})
+	if err != nil {
+		return nil, fmt.Errorf("failed to publish transaction: %w", err)
+	}
+
+	txid := hashString(resp.TransactionHash)
+	log.Printf("Published transaction %s (%.8f DCR, fee %.8f DCR)", txid, pending.preview.TotalSent, pending.preview.Fee)
+
+	return &types.SendResult{
+		TxID:      txid,
+		TotalSent: pending.preview.TotalSent,
+		Fee:       pending.preview.Fee,
+	}, nil
+}
+
+// CancelSend discards a prepared transaction
+func CancelSend(token string) error {
+	_, err := takePendingSend(token)
+	return err
+}
+
+// signTransaction signs with gRPC SignTransaction. With an empty passphrase
+// the wallet signs only if it is already unlocked.
+func signTransaction(ctx context.Context, unsigned []byte, passphrase string) ([]byte, error) {
+	resp, err := rpc.WalletGrpcClient.SignTransaction(ctx, &pb.SignTransactionRequest{
+		Passphrase:            []byte(passphrase),
+		SerializedTransaction: unsigned,
+	})
+	if err != nil {
+		switch status.Code(err) {
+		case codes.InvalidArgument:
+			if passphrase != "" {
+				return nil, ErrIncorrectPassphrase
+			}
+		case codes.FailedPrecondition:
+			return nil, ErrWalletLocked
+		}
+		return nil, fmt.Errorf("failed to sign transaction: %w", err)
+	}
+	if len(resp.UnsignedInputIndexes) > 0 {
+		return nil, fmt.Errorf("failed to sign inputs %v", resp.UnsignedInputIndexes)
+	}
+	return resp.Transaction, nil
+}
+
+// takePendingSend removes and returns a prepared transaction so it cannot be
+// confirmed twice concurrently
+func takePendingSend(token string) (*pendingSend, error) {
+	pendingSendsMutex.Lock()
+	defer pendingSendsMutex.Unlock()
+
+	pruneExpiredSends()
+	pending, ok := pendingSends[token]
+	if !ok {
+		return nil, ErrPreviewNotFound
+	}
+	delete(pendingSends, token)
+	return pending, nil
+}
+
+// restorePendingSend puts a prepared transaction back after a retryable failure
+func restorePendingSend(token string, pending *pendingSend) {
+	pendingSendsMutex.Lock()
+	defer pendingSendsMutex.Unlock()
+
+	if time.Now().Before(pending.preview.ExpiresAt) {
+		pendingSends[token] = pending
+	}
+}
+
+// pruneExpiredSends drops expired previews. Callers must hold pendingSendsMutex.
+func pruneExpiredSends() {
+	now := time.Now()
+	for token, pending := range pendingSends {
+		if now.After(pending.preview.ExpiresAt) {
+			delete(pendingSends, token)
+		}
+	}
+}
+
+func newPreviewToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate preview token: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
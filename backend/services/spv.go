@@ -0,0 +1,221 @@
+// Copyright (c) 2015-2025 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"decred-pulse-backend/rpc"
+	"decred-pulse-backend/types"
+)
+
+// Sources of the chain height reported with the wallet sync state
+const (
+	chainHeightDcrd   = "dcrd"   // Pulse's own dcrd connection
+	chainHeightPeers  = "peers"  // Highest starting height of the SPV peers
+	chainHeightWallet = "wallet" // The wallet's tip, once it reports synced
+)
+
+// walletSyncStatusResult is a syncstatus result
+type walletSyncStatusResult struct {
+	Synced               bool    `json:"synced"`
+	InitialBlockDownload bool    `json:"initialblockdownload"`
+	HeadersFetchProgress float64 `json:"headersfetchprogress"` // 0-1
+}
+
+// walletPeerResult is a getpeerinfo result from dcrwallet
+type walletPeerResult struct {
+	ID             int32  `json:"id"`
+	Addr           string `json:"addr"`
+	AddrLocal      string `json:"addrlocal"`
+	Services       string `json:"services"`
+	Version        uint32 `json:"version"`
+	SubVer         string `json:"subver"`
+	StartingHeight int64  `json:"startingheight"`
+	BanScore       int32  `json:"banscore"`
+}
+
+// syncModeName names the wallet's network backend
+func syncModeName(spv bool) string {
+	if spv {
+		return syncModeSPV
+	}
+	return syncModeRPC
+}
+
+// LastKnownWalletSyncMode returns "spv" or "rpc" from the latest walletinfo
+// call, or an empty string before the wallet has answered
+func LastKnownWalletSyncMode() string {
+	walletLockMutex.Lock()
+	defer walletLockMutex.Unlock()
+	return lastWalletSyncMode
+}
+
+// fetchWalletSyncState calls syncstatus, which dcrwallet answers from its own
+// network backend in both SPV and RPC mode
+func fetchWalletSyncState(ctx context.Context) (*walletSyncStatusResult, error) {
+	result, err := rpc.WalletClient.RawRequest(ctx, "syncstatus", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sync status: %w", err)
+	}
+	var syncState walletSyncStatusResult
+	if err := json.Unmarshal(result, &syncState); err != nil {
+		return nil, fmt.Errorf("failed to parse sync status: %w", err)
+	}
+	return &syncState, nil
+}
+
+// fetchWalletPeers calls getpeerinfo on dcrwallet. In SPV mode these are the
+// wallet's own peers; in RPC mode dcrwallet forwards the call to its dcrd.
+func fetchWalletPeers(ctx context.Context) ([]walletPeerResult, error) {
+	result, err := rpc.WalletClient.RawRequest(ctx, "getpeerinfo", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get wallet peers: %w", err)
+	}
+	var peers []walletPeerResult
+	if err := json.Unmarshal(result, &peers); err != nil {
+		return nil, fmt.Errorf("failed to parse wallet peers: %w", err)
+	}
+	return peers, nil
+}
+
+// FetchWalletNetwork reports the wallet's sync mode and sync state. Without a
+// dcrd connection the chain height is estimated from the SPV peers, and from
+// the wallet's own tip once dcrwallet reports it is synced.
+func FetchWalletNetwork(ctx context.Context) (*types.WalletNetwork, error) {
+	info, err := fetchWalletInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	_, walletHeight, err := rpc.WalletClient.GetBestBlock(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get wallet best block: %w", err)
+	}
+	return walletNetwork(ctx, info, walletHeight), nil
+}
+
+// walletNetwork builds the sync state for a wallet at walletHeight. Failures
+// of the individual calls only leave their fields empty.
+func walletNetwork(ctx context.Context, info *walletInfo, walletHeight int64) *types.WalletNetwork {
+	network := &types.WalletNetwork{
+		Mode:          syncModeName(info.SPV),
+		Connected:     info.DaemonConnected,
+		WalletHeight:  walletHeight,
+		ChainHeight:   walletHeight,
+		DcrdAvailable: rpc.DcrdClient != nil,
+	}
+
+	syncState, err := fetchWalletSyncState(ctx)
+	if err == nil {
+		network.Synced = syncState.Synced
+		network.InitialBlockDownload = syncState.InitialBlockDownload
+		network.HeadersFetchProgress = syncState.HeadersFetchProgress * 100
+	} else {
+		log.Printf("Warning: Could not get wallet sync status: %v", err)
+	}
+
+	if info.SPV {
+		if peers, err := fetchWalletPeers(ctx); err == nil {
+			network.PeerCount = len(peers)
+			for _, p := range peers {
+				if p.StartingHeight > network.ChainHeight {
+					network.ChainHeight = p.StartingHeight
+				}
+			}
+		} else {
+			log.Printf("Warning: Could not get SPV peers: %v", err)
+		}
+	}
+
+	// Prefer the tip from Pulse's dcrd in RPC mode, where dcrwallet follows
+	// that same node
+	switch {
+	case !info.SPV && rpc.DcrdClient != nil:
+		if height, err := rpc.DcrdClient.GetBlockCount(ctx); err == nil {
+			network.ChainHeight = height
+			network.ChainHeightSource = chainHeightDcrd
+		}
+	case (syncState != nil && syncState.Synced) || network.ChainHeight == walletHeight:
+		// Peers' starting heights lag the tip once the wallet has caught up
+		network.ChainHeight = walletHeight
+		network.ChainHeightSource = chainHeightWallet
+	default:
+		network.ChainHeightSource = chainHeightPeers
+	}
+	if network.ChainHeightSource == "" {
+		network.ChainHeightSource = chainHeightWallet
+	}
+	if syncState == nil {
+		// Fall back to comparing heights when syncstatus is unavailable
+		network.Synced = network.ChainHeight-walletHeight <= 2
+	}
+
+	// Notifications from a sync started through the wallet loader
+	if loaderSync := fetchWalletSyncStatus(); loaderSync.Running && loaderSync.Mode == network.Mode {
+		network.SyncStage = loaderSync.Stage
+		if network.PeerCount == 0 {
+			network.PeerCount = int(loaderSync.PeerCount)
+		}
+	}
+	return network
+}
+
+// WalletChainHeight returns the chain tip for sync progress: dcrd's block
+// count when Pulse has a dcrd connection, otherwise the wallet's estimate
+func WalletChainHeight(ctx context.Context) int64 {
+	if rpc.DcrdClient != nil {
+		if height, err := rpc.DcrdClient.GetBlockCount(ctx); err == nil {
+			return height
+		}
+	}
+	if rpc.WalletClient == nil {
+		return 0
+	}
+	network, err := FetchWalletNetwork(ctx)
+	if err != nil {
+		return 0
+	}
+	return network.ChainHeight
+}
+
+// FetchWalletPeers lists the wallet's SPV peers. In RPC mode the wallet has
+// no peers of its own and the list is empty; dcrd's peers are under
+// /network/peers.
+func FetchWalletPeers(ctx context.Context) (*types.WalletPeersResponse, error) {
+	info, err := fetchWalletInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &types.WalletPeersResponse{
+		Mode:  syncModeName(info.SPV),
+		Peers: []types.WalletPeer{},
+	}
+	if !info.SPV {
+		return response, nil
+	}
+
+	peers, err := fetchWalletPeers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range peers {
+		response.Peers = append(response.Peers, types.WalletPeer{
+			ID:             p.ID,
+			Address:        p.Addr,
+			LocalAddress:   p.AddrLocal,
+			Services:       p.Services,
+			Version:        p.Version,
+			UserAgent:      p.SubVer,
+			StartingHeight: p.StartingHeight,
+			BanScore:       p.BanScore,
+		})
+	}
+	response.Count = len(response.Peers)
+	return response, nil
+}
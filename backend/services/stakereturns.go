@@ -0,0 +1,239 @@
+// Copyright (c) 2015-2025 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/decred/dcrd/dcrutil/v4"
+
+	"decred-pulse-backend/rpc"
+	"decred-pulse-backend/types"
+)
+
+const daysPerYear = 365.0
+
+// FetchStakeReturns computes the realized return of every voted or revoked
+// ticket, aggregates them by month and by account, and compares the realized
+// APY with the network-wide expected APY
+func FetchStakeReturns(ctx context.Context) (*types.StakeReturnsResponse, error) {
+	tickets, err := FetchWalletTickets(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &types.StakeReturnsResponse{
+		Tickets:   make([]types.TicketReturn, 0),
+		ByMonth:   make([]types.StakeReturnPeriod, 0),
+		ByAccount: make([]types.StakeReturnPeriod, 0),
+	}
+
+	months := make(map[string]*stakeReturnAccumulator)
+	accounts := make(map[string]*stakeReturnAccumulator)
+	total := &stakeReturnAccumulator{}
+
+	for _, t := range tickets {
+		ret, ok := ticketReturn(t)
+		if !ok {
+			continue
+		}
+		response.Tickets = append(response.Tickets, ret)
+
+		month := time.Unix(t.SpenderTime, 0).UTC().Format("2006-01")
+		if months[month] == nil {
+			months[month] = &stakeReturnAccumulator{}
+		}
+		if accounts[t.Account] == nil {
+			accounts[t.Account] = &stakeReturnAccumulator{}
+		}
+		months[month].add(ret)
+		accounts[t.Account].add(ret)
+		total.add(ret)
+	}
+
+	for key, acc := range months {
+		response.ByMonth = append(response.ByMonth, acc.period(key))
+	}
+	sort.Slice(response.ByMonth, func(i, j int) bool {
+		return response.ByMonth[i].Key < response.ByMonth[j].Key
+	})
+
+	for key, acc := range accounts {
+		response.ByAccount = append(response.ByAccount, acc.period(key))
+	}
+	sort.Slice(response.ByAccount, func(i, j int) bool {
+		return response.ByAccount[i].Key < response.ByAccount[j].Key
+	})
+
+	response.Summary = total.period("all")
+
+	// The network APY needs dcrd, which SPV setups may not have
+	if rpc.DcrdClient != nil {
+		network, err := FetchNetworkStakeAPY(ctx)
+		if err != nil {
+			log.Printf("Warning: Could not compute network stake APY: %v", err)
+		} else {
+			response.Network = network
+		}
+	}
+
+	return response, nil
+}
+
+// ticketReturn computes the realized return of a spent ticket.
+// Unspent tickets have no realized return yet.
+func ticketReturn(t types.WalletTicket) (types.TicketReturn, bool) {
+	if t.SpenderHash == "" || t.Price <= 0 || t.PurchaseTime == 0 {
+		return types.TicketReturn{}, false
+	}
+
+	// Work in atoms to avoid accumulating float rounding
+	price := toAtoms(t.Price)
+	netReward := toAtoms(t.Returned) - price - toAtoms(t.Fee)
+
+	ret := types.TicketReturn{
+		Hash:         t.Hash,
+		Account:      t.Account,
+		Status:       t.Status,
+		PurchaseTime: t.PurchaseTime,
+		SpenderTime:  t.SpenderTime,
+		Price:        t.Price,
+		Fee:          t.Fee,
+		Returned:     t.Returned,
+		NetReward:    dcrutil.Amount(netReward).ToCoin(),
+		DaysLocked:   float64(t.SpenderTime-t.PurchaseTime) / 86400,
+	}
+	if ret.DaysLocked > 0 {
+		ret.AnnualizedReturn = float64(netReward) / float64(price) * daysPerYear / ret.DaysLocked * 100
+	}
+	return ret, true
+}
+
+// toAtoms converts a DCR amount to atoms, rounding to the nearest atom
+func toAtoms(dcr float64) int64 {
+	amount, err := dcrutil.NewAmount(dcr)
+	if err != nil {
+		return 0
+	}
+	return int64(amount)
+}
+
+// stakeReturnAccumulator sums ticket returns for one aggregation bucket
+type stakeReturnAccumulator struct {
+	tickets   int
+	voted     int
+	revoked   int
+	invested  int64
+	netReward int64
+	days      float64
+	dcrDays   float64 // Sum of price * days locked, for capital-weighted APY
+}
+
+func (a *stakeReturnAccumulator) add(r types.TicketReturn) {
+	a.tickets++
+	switch r.Status {
+	case "voted":
+		a.voted++
+	case "revoked":
+		a.revoked++
+	}
+	a.invested += toAtoms(r.Price)
+	a.netReward += toAtoms(r.NetReward)
+	a.days += r.DaysLocked
+	a.dcrDays += r.Price * r.DaysLocked
+}
+
+func (a *stakeReturnAccumulator) period(key string) types.StakeReturnPeriod {
+	p := types.StakeReturnPeriod{
+		Key:            key,
+		Tickets:        a.tickets,
+		Voted:          a.voted,
+		Revoked:        a.revoked,
+		TotalInvested:  dcrutil.Amount(a.invested).ToCoin(),
+		TotalNetReward: dcrutil.Amount(a.netReward).ToCoin(),
+	}
+	if a.tickets > 0 {
+		p.AvgDaysLocked = a.days / float64(a.tickets)
+	}
+	if a.dcrDays > 0 {
+		p.RealizedAPY = p.TotalNetReward / a.dcrDays * daysPerYear * 100
+	}
+	return p
+}
+
+// FetchNetworkStakeAPY estimates the APY of a ticket bought now from the
+// ticket price, the ticket pool size and the current vote subsidy
+func FetchNetworkStakeAPY(ctx context.Context) (*types.NetworkStakeAPY, error) {
+	if rpc.DcrdClient == nil {
+		return nil, fmt.Errorf("dcrd RPC client not initialized")
+	}
+
+	params, err := ActiveNetParams(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	price, _, err := fetchStakeDifficulty(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := rpc.WalletClient.RawRequest(ctx, "getstakeinfo", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stake info: %w", err)
+	}
+	var stakeInfo struct {
+		PoolSize int64 `json:"poolsize"`
+	}
+	if err := json.Unmarshal(result, &stakeInfo); err != nil {
+		return nil, fmt.Errorf("failed to parse stake info: %w", err)
+	}
+
+	height, err := rpc.DcrdClient.GetBlockCount(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get block count: %w", err)
+	}
+
+	votersPerBlock := int64(params.TicketsPerBlock)
+	subsidyParams, err := marshalParams(height, votersPerBlock)
+	if err != nil {
+		return nil, err
+	}
+	result, err = rpc.DcrdClient.RawRequest(ctx, "getblocksubsidy", subsidyParams)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get block subsidy: %w", err)
+	}
+	var subsidy struct {
+		PoS int64 `json:"pos"` // Atoms paid to all voters of the block
+	}
+	if err := json.Unmarshal(result, &subsidy); err != nil {
+		return nil, fmt.Errorf("failed to parse block subsidy: %w", err)
+	}
+
+	if votersPerBlock <= 0 {
+		return nil, fmt.Errorf("network %s has no voters per block", params.Name)
+	}
+	voteReward := dcrutil.Amount(subsidy.PoS / votersPerBlock).ToCoin()
+
+	// A ticket matures, then waits on average poolsize / votes-per-block blocks
+	blocksToVote := float64(params.TicketMaturity) + float64(stakeInfo.PoolSize)/float64(votersPerBlock)
+	daysToVote := blocksToVote * params.TargetTimePerBlock.Seconds() / 86400
+
+	network := &types.NetworkStakeAPY{
+		TicketPrice:   price,
+		PoolSize:      stakeInfo.PoolSize,
+		VoteReward:    voteReward,
+		AvgDaysToVote: daysToVote,
+	}
+	if price > 0 && daysToVote > 0 {
+		network.ExpectedAPY = voteReward / price * daysPerYear / daysToVote * 100
+	}
+	return network, nil
+}
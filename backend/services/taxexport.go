@@ -0,0 +1,153 @@
+// Copyright (c) 2015-2025 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package services
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	"github.com/decred/dcrd/dcrutil/v4"
+
+	"decred-pulse-backend/pricing"
+	"decred-pulse-backend/types"
+)
+
+// Tax record kinds
+const (
+	TaxKindStakingReward    = "staking_reward"
+	TaxKindTicketPurchase   = "ticket_purchase"
+	TaxKindRevocationRefund = "revocation_refund"
+	TaxKindTransfer         = "transfer"
+	TaxKindFee              = "fee"
+	TaxKindPayment          = "payment"
+	TaxKindReceive          = "receive"
+	TaxKindMining           = "mining"
+)
+
+// TaxExportOptions selects the transactions of a tax export
+type TaxExportOptions struct {
+	Account string
+	Since   time.Time
+	Until   time.Time
+}
+
+// BuildTaxExport classifies every mined wallet transaction in the date range,
+// oldest first, and values it with prices. prices may be nil.
+func BuildTaxExport(ctx context.Context, prices *pricing.PriceHistory, opts TaxExportOptions) ([]types.TaxRecord, error) {
+	filter := HistoryFilter{
+		Limit:   MaxHistoryLimit,
+		Account: opts.Account,
+		Since:   opts.Since,
+		Until:   opts.Until,
+	}
+
+	records := make([]types.TaxRecord, 0)
+	missingPrices := 0
+	for {
+		page, err := FetchHistory(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range page.Transactions {
+			record := classifyTaxRecord(entry)
+			if prices != nil {
+				if price, ok := prices.Lookup(record.Time); ok {
+					record.Price = price
+					record.HasPrice = true
+					record.FiatAmount = record.Amount * price
+					record.FiatFee = record.Fee * price
+				} else {
+					missingPrices++
+				}
+			}
+			records = append(records, record)
+		}
+		if !page.HasMore {
+			break
+		}
+		filter.Cursor = page.NextCursor
+	}
+
+	if prices != nil && missingPrices > 0 {
+		log.Printf("Warning: Tax export has no price for %d of %d transactions", missingPrices, len(records))
+	}
+
+	// History is newest first, accounting exports read oldest first
+	for i, j := 0, len(records)-1; i < j; i, j = i+1, j-1 {
+		records[i], records[j] = records[j], records[i]
+	}
+	return records, nil
+}
+
+// classifyTaxRecord maps a history record to a tax kind, splitting the net
+// balance change into the amount and the fee
+func classifyTaxRecord(entry types.HistoryEntry) types.TaxRecord {
+	record := types.TaxRecord{
+		Time:        entry.Time,
+		TxID:        entry.TxID,
+		BlockHeight: entry.BlockHeight,
+		Accounts:    entry.Accounts,
+	}
+	if entry.BlockTime > 0 {
+		record.Time = time.Unix(entry.BlockTime, 0)
+	}
+	record.Time = record.Time.UTC()
+
+	net := toAtoms(entry.Amount)
+	fee := toAtoms(entry.Fee)
+	amount := net + fee
+
+	switch {
+	case entry.TxType == "vote":
+		record.Kind = TaxKindStakingReward
+		record.Description = "Vote reward"
+	case entry.TxType == "ticket":
+		record.Kind = TaxKindTicketPurchase
+		for _, credit := range entry.Credits {
+			if credit.Index == 0 {
+				record.Locked = credit.Amount
+			}
+		}
+		record.Description = fmt.Sprintf("Locked %s DCR in ticket", formatDCR(record.Locked))
+	case entry.TxType == "revocation":
+		record.Kind = TaxKindRevocationRefund
+		var refunded int64
+		for _, credit := range entry.Credits {
+			refunded += toAtoms(credit.Amount)
+		}
+		record.Description = fmt.Sprintf("Refunded %s DCR from revoked ticket", formatDCR(dcrutil.Amount(refunded).ToCoin()))
+	case entry.Category == "generate":
+		record.Kind = TaxKindMining
+		record.Description = "Block reward"
+	case entry.Category == "transfer":
+		record.Kind = TaxKindTransfer
+		record.Description = "Transfer between own accounts"
+	case amount == 0 && fee > 0:
+		// Nothing but the fee left the wallet, e.g. a mixing round
+		record.Kind = TaxKindFee
+		record.Description = "Transaction fee"
+		if entry.IsMixed {
+			record.Description = "Mixing fee"
+		}
+	case net < 0:
+		record.Kind = TaxKindPayment
+		record.Description = "Payment sent"
+	default:
+		record.Kind = TaxKindReceive
+		record.Description = "Payment received"
+	}
+
+	record.Amount = dcrutil.Amount(amount).ToCoin()
+	record.Fee = -dcrutil.Amount(fee).ToCoin()
+	return record
+}
+
+// formatDCR formats a DCR amount without trailing zeros
+func formatDCR(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', -1, 64)
+}
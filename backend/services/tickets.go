@@ -0,0 +1,546 @@
+// Copyright (c) 2015-2025 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	pb "decred.org/dcrwallet/v4/rpc/walletrpc"
+	"decred.org/dcrwallet/v4/wallet/txrules"
+	"decred.org/dcrwallet/v4/wallet/txsizes"
+	"github.com/decred/dcrd/dcrutil/v4"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"decred-pulse-backend/rpc"
+	"decred-pulse-backend/types"
+)
+
+const (
+	// maxTicketsPerPurchase bounds a single purchase request
+	maxTicketsPerPurchase = 100
+
+	// VSP fee payment states
+	VSPFeeStatusNone      = "none" // Not a VSP ticket or not yet processed
+	VSPFeeStatusStarted   = "started"
+	VSPFeeStatusPaid      = "paid"
+	VSPFeeStatusErrored   = "errored"
+	VSPFeeStatusConfirmed = "confirmed"
+)
+
+// ErrTicketPriceExceeded is returned when the ticket price rose above the
+// maximum the caller accepted in the preview
+var ErrTicketPriceExceeded = errors.New("ticket price exceeds the accepted maximum")
+
+var vspHTTPClient = &http.Client{Timeout: 10 * time.Second}
+
+// vspFeeStatuses maps the gRPC fee processing states to API values
+var vspFeeStatuses = []struct {
+	status pb.GetVSPTicketsByFeeStatusRequest_FeeStatus
+	name   string
+}{
+	{pb.GetVSPTicketsByFeeStatusRequest_VSP_FEE_PROCESS_STARTED, VSPFeeStatusStarted},
+	{pb.GetVSPTicketsByFeeStatusRequest_VSP_FEE_PROCESS_PAID, VSPFeeStatusPaid},
+	{pb.GetVSPTicketsByFeeStatusRequest_VSP_FEE_PROCESS_ERRORED, VSPFeeStatusErrored},
+	{pb.GetVSPTicketsByFeeStatusRequest_VSP_FEE_PROCESS_CONFIRMED, VSPFeeStatusConfirmed},
+}
+
+// PreviewTicketPurchase prices a ticket purchase against the current stake
+// difficulty and the source account balance, and checks the VSP
+func PreviewTicketPurchase(ctx context.Context, req types.TicketPurchaseRequest) (*types.TicketPurchasePreview, error) {
+	if err := validateTicketPurchase(req); err != nil {
+		return nil, err
+	}
+
+	price, next, err := fetchStakeDifficulty(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	preview := &types.TicketPurchasePreview{
+		Account:         ticketAccount(req),
+		Count:           req.Count,
+		TicketPrice:     price,
+		NextTicketPrice: next,
+		VSPHost:         req.VSPHost,
+	}
+
+	info, err := fetchVSPInfo(ctx, req.VSPHost)
+	if err != nil {
+		preview.Warnings = append(preview.Warnings, fmt.Sprintf("Could not reach VSP: %v", err))
+	} else {
+		preview.VSPFeePercent = info.FeePercentage
+		if info.PubKey != req.VSPPubKey {
+			preview.Warnings = append(preview.Warnings, "VSP pubkey does not match the key published by the VSP")
+		}
+		if info.VspClosed {
+			preview.Warnings = append(preview.Warnings, "VSP is closed to new tickets")
+		}
+		if params, err := ActiveNetParams(ctx); err == nil && info.Network != "" && info.Network != params.Name {
+			preview.Warnings = append(preview.Warnings, fmt.Sprintf("VSP serves %s, wallet is on %s", info.Network, params.Name))
+		}
+	}
+
+	// Without the VSP fee rate only the transaction fees can be estimated
+	fees, err := estimateTicketFees(ctx, price, req.Count, preview.VSPFeePercent)
+	if err != nil {
+		return nil, err
+	}
+	preview.Fees = fees
+	preview.TotalCost = price*float64(req.Count) + fees
+
+	balance, err := fetchSpendableBalance(ctx, preview.Account)
+	if err != nil {
+		return nil, err
+	}
+	preview.SpendableBalance = balance
+	preview.CanAfford = balance >= preview.TotalCost
+
+	return preview, nil
+}
+
+// PurchaseTickets buys tickets through a VSP with gRPC PurchaseTickets
+func PurchaseTickets(ctx context.Context, req types.TicketPurchaseRequest) (*types.TicketPurchaseResult, error) {
+	if err := validateTicketPurchase(req); err != nil {
+		return nil, err
+	}
+
+	price, _, err := fetchStakeDifficulty(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if req.MaxPrice > 0 && price > req.MaxPrice {
+		return nil, fmt.Errorf("%w: %.8f > %.8f DCR", ErrTicketPriceExceeded, price, req.MaxPrice)
+	}
+
+	account := ticketAccount(req)
+	accountResp, err := rpc.WalletGrpcClient.AccountNumber(ctx, &pb.AccountNumberRequest{AccountName: account})
+	if err != nil {
+		return nil, fmt.Errorf("unknown account %q: %w", account, err)
+	}
+
+	// Expiry is given relative to the current height, dcrwallet expects a height
+	var expiry uint32
+	if req.Expiry > 0 {
+		_, height, err := rpc.WalletClient.GetBestBlock(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get best block: %w", err)
+		}
+		expiry = uint32(height) + req.Expiry
+	}
+
+	resp, err := rpc.WalletGrpcClient.PurchaseTickets(ctx, &pb.PurchaseTicketsRequest{
+		Passphrase:            []byte(req.Passphrase),
+		Account:               accountResp.AccountNumber,
+		RequiredConfirmations: defaultSendConfirmations,
+		NumTickets:            req.Count,
+		Expiry:                expiry,
+		VspHost:               req.VSPHost,
+		VspPubkey:             req.VSPPubKey,
+		ChangeAccount:         accountResp.AccountNumber,
+	})
+	if err != nil {
+		switch status.Code(err) {
+		case codes.InvalidArgument:
+			if req.Passphrase != "" && strings.Contains(strings.ToLower(err.Error()), "passphrase") {
+				return nil, ErrIncorrectPassphrase
+			}
+		case codes.FailedPrecondition:
+			return nil, ErrWalletLocked
+		}
+		return nil, fmt.Errorf("failed to purchase tickets: %w", err)
+	}
+
+	result := &types.TicketPurchaseResult{
+		Account:      account,
+		TicketPrice:  price,
+		TicketHashes: make([]string, 0, len(resp.TicketHashes)),
+		VSPHost:      req.VSPHost,
+	}
+	for _, hash := range resp.TicketHashes {
+		result.TicketHashes = append(result.TicketHashes, hashString(hash))
+	}
+	result.TotalCost = price * float64(len(result.TicketHashes))
+	var feePercent float64
+	if info, err := fetchVSPInfo(ctx, req.VSPHost); err != nil {
+		log.Printf("Warning: Could not get VSP fee rate: %v", err)
+	} else {
+		feePercent = info.FeePercentage
+	}
+	if fees, err := estimateTicketFees(ctx, price, uint32(len(result.TicketHashes)), feePercent); err != nil {
+		log.Printf("Warning: Could not estimate ticket fees: %v", err)
+	} else {
+		result.Fees = fees
+		result.TotalCost += fees
+	}
+	log.Printf("Purchased %d ticket(s) at %.8f DCR via %s", len(result.TicketHashes), price, req.VSPHost)
+
+	feeStatus, err := FetchVSPFeeStatuses(ctx)
+	if err != nil {
+		log.Printf("Warning: Could not get VSP fee status: %v", err)
+	}
+	result.VSPFeeStatus = make(map[string]string, len(result.TicketHashes))
+	for _, hash := range result.TicketHashes {
+		if s, ok := feeStatus[hash]; ok {
+			result.VSPFeeStatus[hash] = s
+		} else {
+			result.VSPFeeStatus[hash] = VSPFeeStatusNone
+		}
+	}
+
+	return result, nil
+}
+
+// FetchVSPFeeStatuses returns the VSP fee payment state of every VSP ticket,
+// keyed by ticket hash
+func FetchVSPFeeStatuses(ctx context.Context) (map[string]string, error) {
+	statuses := make(map[string]string)
+	for _, fs := range vspFeeStatuses {
+		resp, err := rpc.WalletGrpcClient.GetVSPTicketsByFeeStatus(ctx, &pb.GetVSPTicketsByFeeStatusRequest{FeeStatus: fs.status})
+		if err != nil {
+			return statuses, fmt.Errorf("failed to get %s VSP tickets: %w", fs.name, err)
+		}
+		for _, hash := range resp.TicketsHashes {
+			statuses[hashString(hash)] = fs.name
+		}
+	}
+	return statuses, nil
+}
+
+func validateTicketPurchase(req types.TicketPurchaseRequest) error {
+	if req.Count < 1 || req.Count > maxTicketsPerPurchase {
+		return fmt.Errorf("ticket count must be between 1 and %d", maxTicketsPerPurchase)
+	}
+	if req.VSPHost == "" || req.VSPPubKey == "" {
+		return fmt.Errorf("VSP host and pubkey are required")
+	}
+	u, err := url.Parse(req.VSPHost)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return fmt.Errorf("VSP host must be an http(s) URL")
+	}
+	return nil
+}
+
+func ticketAccount(req types.TicketPurchaseRequest) string {
+	if req.Account == "" {
+		return "default"
+	}
+	return req.Account
+}
+
+// fetchStakeDifficulty returns the current and next ticket price in DCR
+func fetchStakeDifficulty(ctx context.Context) (float64, float64, error) {
+	result, err := rpc.WalletClient.RawRequest(ctx, "getstakedifficulty", nil)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to get stake difficulty: %w", err)
+	}
+
+	var difficulty struct {
+		Current float64 `json:"current"`
+		Next    float64 `json:"next"`
+	}
+	if err := json.Unmarshal(result, &difficulty); err != nil {
+		return 0, 0, fmt.Errorf("failed to parse stake difficulty: %w", err)
+	}
+	return difficulty.Current, difficulty.Next, nil
+}
+
+// fetchSpendableBalance returns the spendable balance of an account in DCR
+func fetchSpendableBalance(ctx context.Context, account string) (float64, error) {
+	params, err := marshalParams(account)
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := rpc.WalletClient.RawRequest(ctx, "getbalance", params)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get balance of %s: %w", account, err)
+	}
+
+	var balance struct {
+		Balances []struct {
+			AccountName string  `json:"accountname"`
+			Spendable   float64 `json:"spendable"`
+		} `json:"balances"`
+	}
+	if err := json.Unmarshal(result, &balance); err != nil {
+		return 0, fmt.Errorf("failed to parse balance: %w", err)
+	}
+
+	for _, b := range balance.Balances {
+		if b.AccountName == account {
+			return b.Spendable, nil
+		}
+	}
+	return 0, fmt.Errorf("account %q not found", account)
+}
+
+// vspInfo holds the vspd /api/v3/vspinfo fields used for the preview
+type vspInfo struct {
+	PubKey        string  `json:"pubkey"`
+	FeePercentage float64 `json:"feepercentage"`
+	Network       string  `json:"network"`
+	VspClosed     bool    `json:"vspclosed"`
+}
+
+// fetchVSPInfo queries the VSP's public info endpoint
+func fetchVSPInfo(ctx context.Context, host string) (*vspInfo, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimRight(host, "/")+"/api/v3/vspinfo", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := vspHTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	var info vspInfo
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		return nil, fmt.Errorf("invalid vspinfo response: %w", err)
+	}
+	return &info, nil
+}
+
+// estimateTicketFees estimates the fees, in DCR, of buying count tickets
+// through a VSP: the split transaction, the tickets and the VSP fee
+// transactions at the wallet relay fee, plus the VSP fee itself
+func estimateTicketFees(ctx context.Context, price float64, count uint32, vspFeePercent float64) (float64, error) {
+	if count == 0 {
+		return 0, nil
+	}
+	relayFee, err := fetchRelayFee(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	// The split transaction pays one VSP fee output and one ticket output per ticket
+	splitOutputs := make([]int, 2*count)
+	for i := range splitOutputs {
+		splitOutputs[i] = txsizes.P2PKHPkScriptSize
+	}
+	splitSize := txsizes.EstimateSerializeSizeFromScriptSizes(
+		[]int{txsizes.RedeemP2PKHSigScriptSize}, splitOutputs, txsizes.P2PKHPkScriptSize)
+	// A ticket has a stake submission, a commitment and a stake change output
+	ticketSize := txsizes.EstimateSerializeSizeFromScriptSizes([]int{txsizes.RedeemP2PKHSigScriptSize},
+		[]int{txsizes.P2PKHPkScriptSize + 1, txsizes.TicketCommitmentScriptSize, txsizes.P2PKHPkScriptSize + 1}, 0)
+	feeTxSize := txsizes.EstimateSerializeSizeFromScriptSizes(
+		[]int{txsizes.RedeemP2PKHSigScriptSize}, []int{txsizes.P2PKHPkScriptSize}, 0)
+
+	ticketFee := txrules.FeeForSerializeSize(relayFee, ticketSize)
+	perTicket := ticketFee + txrules.FeeForSerializeSize(relayFee, feeTxSize)
+
+	if vspFeePercent > 0 {
+		params, err := ActiveNetParams(ctx)
+		if err != nil {
+			return 0, err
+		}
+		_, height, err := rpc.WalletClient.GetBestBlock(ctx)
+		if err != nil {
+			return 0, fmt.Errorf("failed to get best block: %w", err)
+		}
+		ticketPrice, err := dcrutil.NewAmount(price)
+		if err != nil {
+			return 0, err
+		}
+		// DCP0010 and DCP0012 are active on every supported network
+		perTicket += txrules.StakePoolTicketFee(ticketPrice, ticketFee, int32(height), vspFeePercent, params, true, true)
+	}
+
+	total := txrules.FeeForSerializeSize(relayFee, splitSize) + perTicket*dcrutil.Amount(count)
+	return total.ToCoin(), nil
+}
+
+// Ticket lifecycle states reported by the ticket listing
+var ticketStatusNames = map[pb.GetTicketsResponse_TicketDetails_TicketStatus]string{
+	pb.GetTicketsResponse_TicketDetails_UNKNOWN:  "unknown",
+	pb.GetTicketsResponse_TicketDetails_UNMINED:  "unmined",
+	pb.GetTicketsResponse_TicketDetails_IMMATURE: "immature",
+	pb.GetTicketsResponse_TicketDetails_LIVE:     "live",
+	pb.GetTicketsResponse_TicketDetails_VOTED:    "voted",
+	pb.GetTicketsResponse_TicketDetails_MISSED:   "missed",
+	pb.GetTicketsResponse_TicketDetails_EXPIRED:  "expired",
+	pb.GetTicketsResponse_TicketDetails_REVOKED:  "revoked",
+}
+
+// TicketFilter selects tickets for ListWalletTickets. Empty fields match everything.
+type TicketFilter struct {
+	Statuses []string
+	Account  string
+	VSPHost  string
+	Page     int
+	PageSize int
+}
+
+// FetchWalletTickets returns every ticket owned by the wallet, newest first,
+// using the gRPC GetTickets stream
+func FetchWalletTickets(ctx context.Context) ([]types.WalletTicket, error) {
+	if err := refreshAccountNames(ctx); err != nil {
+		log.Printf("Warning: Could not load account names: %v", err)
+	}
+
+	feeStatus, err := FetchVSPFeeStatuses(ctx)
+	if err != nil {
+		log.Printf("Warning: Could not get VSP fee status: %v", err)
+	}
+
+	// No block range streams every ticket including unmined ones
+	stream, err := rpc.WalletGrpcClient.GetTickets(ctx, &pb.GetTicketsRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tickets: %w", err)
+	}
+
+	var tickets []types.WalletTicket
+	for {
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to receive tickets: %w", err)
+		}
+		if resp.Ticket == nil || resp.Ticket.Ticket == nil {
+			continue
+		}
+
+		ticket := convertTicketDetails(resp)
+		if ticket.VSPHost != "" {
+			ticket.VSPFeeStatus = VSPFeeStatusNone
+			if s, ok := feeStatus[ticket.Hash]; ok {
+				ticket.VSPFeeStatus = s
+			}
+		}
+		tickets = append(tickets, ticket)
+	}
+
+	sort.SliceStable(tickets, func(i, j int) bool {
+		hi, hj := tickets[i].PurchaseHeight, tickets[j].PurchaseHeight
+		if hi == 0 || hj == 0 {
+			return hi == 0 && hj != 0 // Unmined first
+		}
+		return hi > hj
+	})
+
+	return tickets, nil
+}
+
+// ListWalletTickets returns a filtered page of wallet tickets
+func ListWalletTickets(ctx context.Context, filter TicketFilter) (*types.TicketListResponse, error) {
+	tickets, err := FetchWalletTickets(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make(map[string]bool, len(filter.Statuses))
+	for _, s := range filter.Statuses {
+		statuses[s] = true
+	}
+
+	matched := make([]types.WalletTicket, 0, len(tickets))
+	statusCount := make(map[string]int)
+	for _, t := range tickets {
+		if filter.Account != "" && t.Account != filter.Account {
+			continue
+		}
+		if filter.VSPHost != "" && t.VSPHost != filter.VSPHost {
+			continue
+		}
+		statusCount[t.Status]++
+		if len(statuses) > 0 && !statuses[t.Status] {
+			continue
+		}
+		matched = append(matched, t)
+	}
+
+	window := paginate(len(matched), filter.Page, filter.PageSize, 25)
+
+	return &types.TicketListResponse{
+		Tickets:     matched[window.start:window.end],
+		StatusCount: statusCount,
+		Total:       len(matched),
+		CurrentPage: window.page,
+		PageSize:    window.pageSize,
+		TotalPages:  window.totalPages,
+	}, nil
+}
+
+// convertTicketDetails converts a GetTickets response into an API ticket
+func convertTicketDetails(resp *pb.GetTicketsResponse) types.WalletTicket {
+	details := resp.Ticket.Ticket
+
+	ticket := types.WalletTicket{
+		Hash:         hashString(details.Hash),
+		Status:       ticketStatusNames[resp.Ticket.TicketStatus],
+		PurchaseTime: details.Timestamp,
+		Fee:          dcrutil.Amount(details.Fee).ToCoin(),
+		VSPHost:      resp.VspHost,
+	}
+	if ticket.Status == "" {
+		ticket.Status = "unknown"
+	}
+	if resp.Block != nil && resp.Block.Height > 0 {
+		ticket.PurchaseHeight = resp.Block.Height
+		ticket.PurchaseTime = resp.Block.Timestamp
+	}
+
+	// Output 0 of a ticket holds the locked ticket value
+	var price int64
+	for _, credit := range details.Credits {
+		if credit.Index == 0 {
+			price = credit.Amount
+			ticket.Price = dcrutil.Amount(price).ToCoin()
+			ticket.Account = accountName(credit.Account)
+			break
+		}
+	}
+	if ticket.Account == "" && len(details.Debits) > 0 {
+		ticket.Account = accountName(details.Debits[0].PreviousAccount)
+	}
+
+	// The gRPC server sends an empty spender for unspent tickets
+	spender := resp.Ticket.Spender
+	if spender != nil && len(spender.Hash) > 0 {
+		ticket.SpenderHash = hashString(spender.Hash)
+		ticket.SpenderTime = spender.Timestamp
+		switch spender.TransactionType {
+		case pb.TransactionDetails_VOTE:
+			ticket.SpenderType = "vote"
+		case pb.TransactionDetails_REVOCATION:
+			ticket.SpenderType = "revocation"
+		}
+
+		var returned int64
+		for _, credit := range spender.Credits {
+			returned += credit.Amount
+		}
+		ticket.Returned = dcrutil.Amount(returned).ToCoin()
+		// Same as the stake returns net reward, the purchase fee is part of the cost
+		if price > 0 {
+			ticket.Reward = dcrutil.Amount(returned - price - details.Fee).ToCoin()
+		}
+
+		if ticket.SpenderType == "vote" && ticket.PurchaseTime > 0 && spender.Timestamp > ticket.PurchaseTime {
+			days := float64(spender.Timestamp-ticket.PurchaseTime) / 86400
+			ticket.DaysToVote = &days
+		}
+	}
+
+	return ticket
+}
@@ -13,6 +13,8 @@ import (
 	"sync"
 	"time"
 
+	"decred-pulse-backend/jobs"
+	"decred-pulse-backend/pricing"
 	"decred-pulse-backend/rpc"
 	"decred-pulse-backend/types"
 )
@@ -54,9 +56,19 @@ func FetchTreasuryInfo(ctx context.Context) (*types.TreasuryInfo, error) {
 		activeTSpends = []types.TSpend{}
 	}
 
+	// Our wallet's stance on each spend being voted on
+	attachWalletVotes(ctx, activeTSpends)
+
+	balanceFiat := pricing.Convert(balance)
+	var balanceUSD float64
+	if balanceFiat != nil && balanceFiat.Currency == "USD" {
+		balanceUSD = balanceFiat.Value
+	}
+
 	return &types.TreasuryInfo{
 		Balance:       balance,
-		BalanceUSD:    0, // TODO: Add USD conversion if needed
+		BalanceUSD:    balanceUSD,
+		BalanceFiat:   balanceFiat,
 		TotalAdded:    0, // Tracked in frontend localStorage
 		TotalSpent:    0, // Tracked in frontend localStorage
 		ActiveTSpends: activeTSpends,
@@ -228,6 +240,15 @@ func extractTSpendInfo(tx map[string]interface{}, currentHeight int64) *types.TS
 	expiryHeight := int64(expiry)
 	blocksRemaining := expiryHeight - currentHeight
 
+	// The Pi key is in the treasury spend input's signature script
+	piKey := ""
+	if vin, ok := tx["vin"].([]interface{}); ok && len(vin) > 0 {
+		if vinMap, ok := vin[0].(map[string]interface{}); ok {
+			sigScript, _ := vinMap["treasuryspend"].(string)
+			piKey = tspendPiKey(sigScript)
+		}
+	}
+
 	return &types.TSpend{
 		TxHash:          txid,
 		Amount:          amount,
@@ -237,6 +258,7 @@ func extractTSpendInfo(tx map[string]interface{}, currentHeight int64) *types.TS
 		BlocksRemaining: blocksRemaining,
 		Status:          "voting",
 		DetectedAt:      time.Now(),
+		PiKey:           piKey,
 	}
 }
 
@@ -274,12 +296,13 @@ func extractTSpendHistory(tx map[string]interface{}, blockHeight int64, blockHas
 	}
 }
 
-// TriggerHistoricalScan starts a background scan of the blockchain for all TSpends
-func TriggerHistoricalScan(startHeight int64) error {
+// TriggerHistoricalScan starts a background scan of the blockchain for all TSpends.
+// The scan runs as a tracked job whose ID is returned.
+func TriggerHistoricalScan(startHeight int64) (string, error) {
 	scanMutex.Lock()
 	if isScanRunning {
 		scanMutex.Unlock()
-		return fmt.Errorf("scan already in progress")
+		return "", fmt.Errorf("scan already in progress")
 	}
 	isScanRunning = true
 
@@ -294,35 +317,49 @@ func TriggerHistoricalScan(startHeight int64) error {
 	newTSpendBuffer = []types.TSpendHistory{}
 	scanMutex.Unlock()
 
-	go scanHistoricalTSpendsBackground(startHeight)
-	return nil
+	job := jobs.Start("tspend_scan", []string{"Scan blocks for treasury spends"}, func(ctx context.Context, job *jobs.Handle) (interface{}, error) {
+		defer func() {
+			scanMutex.Lock()
+			isScanRunning = false
+			scanMutex.Unlock()
+		}()
+		return scanHistoricalTSpendsBackground(ctx, job, startHeight)
+	})
+	return job.ID, nil
 }
 
 // scanHistoricalTSpendsBackground performs the historical scan in the background
-func scanHistoricalTSpendsBackground(startHeight int64) {
-	ctx := context.Background()
+func scanHistoricalTSpendsBackground(ctx context.Context, job *jobs.Handle, startHeight int64) (interface{}, error) {
+	job.BeginStep(0)
 
 	currentHeight, err := rpc.DcrdClient.GetBlockCount(ctx)
 	if err != nil {
-		log.Printf("Error getting block count for scan: %v", err)
-		scanMutex.Lock()
-		isScanRunning = false
-		scanMutex.Unlock()
-		return
+		return nil, fmt.Errorf("failed to get block count for scan: %w", err)
 	}
 
 	scanMutex.Lock()
 	totalScanHeight = currentHeight
 	scanMutex.Unlock()
 
-	log.Printf("Starting historical TSpend scan from block %d to %d", startHeight, currentHeight)
+	job.Logf("Starting historical TSpend scan from block %d to %d", startHeight, currentHeight)
 
 	for h := startHeight; h <= currentHeight; h++ {
+		if err := ctx.Err(); err != nil {
+			job.Logf("Scan cancelled at block %d", h)
+			return nil, err
+		}
+
 		// Update progress
 		scanMutex.Lock()
 		currentScanHeight = h
+		found := tspendFoundCount
 		scanMutex.Unlock()
 
+		if currentHeight > startHeight {
+			progress := float64(h-startHeight) / float64(currentHeight-startHeight) * 100
+			job.SetProgress(progress, fmt.Sprintf("Scanning block %d/%d (%d treasury spends found)", h, currentHeight, found))
+		}
+
 		blockHash, err := rpc.DcrdClient.GetBlockHash(ctx, h)
 		if err != nil {
 			log.Printf("Warning: Failed to get block hash at height %d: %v", h, err)
@@ -364,18 +401,23 @@ func scanHistoricalTSpendsBackground(startHeight int64) {
 					scanResults = append(scanResults, *history)
 					newTSpendBuffer = append(newTSpendBuffer, *history)
 					tspendFoundCount++
-					log.Printf("TSpend found at height %d: %s (amount: %.2f DCR)", block.Height, history.TxHash, history.Amount)
 					scanMutex.Unlock()
+					job.Logf("TSpend found at height %d: %s (amount: %.2f DCR)", block.Height, history.TxHash, history.Amount)
 				}
 			}
 		}
 	}
 
-	scanMutex.Lock()
-	isScanRunning = false
-	scanMutex.Unlock()
-
-	log.Printf("Historical TSpend scan complete. Found %d TSpends", tspendFoundCount)
+	scanMutex.RLock()
+	found := tspendFoundCount
+	scanMutex.RUnlock()
+
+	job.Logf("Historical TSpend scan complete. Found %d TSpends", found)
+	return map[string]interface{}{
+		"startHeight": startHeight,
+		"endHeight":   currentHeight,
+		"tspendFound": found,
+	}, nil
 }
 
 // GetScanProgress returns the current scan progress
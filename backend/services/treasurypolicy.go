@@ -0,0 +1,286 @@
+// Copyright (c) 2015-2025 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package services
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/decred/dcrd/chaincfg/chainhash"
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"github.com/decred/dcrd/dcrjson/v4"
+
+	"decred-pulse-backend/rpc"
+	"decred-pulse-backend/types"
+)
+
+// Treasury vote policies as dcrwallet names them
+const (
+	treasuryPolicyYes     = "yes"
+	treasuryPolicyNo      = "no"
+	treasuryPolicyAbstain = "abstain"
+)
+
+// ErrInvalidTreasuryPolicy is returned for invalid keys, hashes or policies
+var ErrInvalidTreasuryPolicy = errors.New("invalid treasury policy")
+
+// treasuryPolicyResult is a treasurypolicy or tspendpolicy result
+type treasuryPolicyResult struct {
+	Key    string `json:"key"`
+	Hash   string `json:"hash"`
+	Policy string `json:"policy"`
+	Ticket string `json:"ticket"`
+}
+
+// normalizeTreasuryPolicy names the abstain policy, which dcrwallet lists as
+// an empty string
+func normalizeTreasuryPolicy(policy string) string {
+	if policy == "" || policy == "invalid" {
+		return treasuryPolicyAbstain
+	}
+	return policy
+}
+
+// validateTreasuryPolicy checks a requested policy
+func validateTreasuryPolicy(policy string) error {
+	switch policy {
+	case treasuryPolicyYes, treasuryPolicyNo, treasuryPolicyAbstain:
+		return nil
+	}
+	return fmt.Errorf("%w: policy must be yes, no or abstain", ErrInvalidTreasuryPolicy)
+}
+
+// validatePolicyHash checks a tspend or optional ticket hash
+func validatePolicyHash(hash, name string, optional bool) error {
+	if hash == "" && optional {
+		return nil
+	}
+	if _, err := chainhash.NewHashFromStr(hash); err != nil || len(hash) != chainhash.MaxHashStringSize {
+		return fmt.Errorf("%w: invalid %s hash", ErrInvalidTreasuryPolicy, name)
+	}
+	return nil
+}
+
+// treasuryPolicyRequest calls a treasury policy method with its arguments
+// followed by an optional ticket hash
+func treasuryPolicyRequest(ctx context.Context, method, ticket string, values ...interface{}) (json.RawMessage, error) {
+	if ticket != "" {
+		values = append(values, ticket)
+	}
+	params, err := marshalParams(values...)
+	if err != nil {
+		return nil, err
+	}
+	result, err := rpc.WalletClient.RawRequest(ctx, method, params)
+	if err != nil {
+		var rpcErr *dcrjson.RPCError
+		if errors.As(err, &rpcErr) {
+			switch rpcErr.Code {
+			case dcrjson.ErrRPCInvalidParameter, dcrjson.ErrRPCDecodeHexString:
+				return nil, fmt.Errorf("%w: %s", ErrInvalidTreasuryPolicy, rpcErr.Message)
+			}
+		}
+		return nil, fmt.Errorf("%s failed: %w", method, err)
+	}
+	return result, nil
+}
+
+// ListTreasuryPolicies returns the wallet's vote for every sanctioned Pi key,
+// abstain unless set, followed by any per-ticket policies
+func ListTreasuryPolicies(ctx context.Context) (*types.TreasuryPoliciesResponse, error) {
+	result, err := treasuryPolicyRequest(ctx, "treasurypolicy", "")
+	if err != nil {
+		return nil, err
+	}
+	var policies []treasuryPolicyResult
+	if err := json.Unmarshal(result, &policies); err != nil {
+		return nil, fmt.Errorf("failed to parse treasury policies: %w", err)
+	}
+
+	response := &types.TreasuryPoliciesResponse{Policies: []types.TreasuryKeyPolicy{}}
+	listed := make(map[string]bool)
+	if params, err := ActiveNetParams(ctx); err == nil {
+		set := make(map[string]string)
+		for _, p := range policies {
+			if p.Ticket == "" {
+				set[p.Key] = normalizeTreasuryPolicy(p.Policy)
+			}
+		}
+		for _, key := range params.PiKeys {
+			keyHex := hex.EncodeToString(key)
+			policy, ok := set[keyHex]
+			if !ok {
+				policy = treasuryPolicyAbstain
+			}
+			response.Policies = append(response.Policies, types.TreasuryKeyPolicy{Key: keyHex, Policy: policy})
+			listed[keyHex] = true
+		}
+	} else {
+		log.Printf("Warning: Could not list Pi keys: %v", err)
+	}
+
+	for _, p := range policies {
+		if p.Ticket == "" && listed[p.Key] {
+			continue
+		}
+		response.Policies = append(response.Policies, types.TreasuryKeyPolicy{
+			Key:    p.Key,
+			Policy: normalizeTreasuryPolicy(p.Policy),
+			Ticket: p.Ticket,
+		})
+	}
+	return response, nil
+}
+
+// SetTreasuryPolicy sets the wallet's vote on spends signed by a Pi key, for
+// all tickets or, with a ticket hash, only that ticket
+func SetTreasuryPolicy(ctx context.Context, req types.TreasuryPolicyRequest) (*types.TreasuryKeyPolicy, error) {
+	key := strings.ToLower(strings.TrimSpace(req.Key))
+	policy := strings.ToLower(strings.TrimSpace(req.Policy))
+	if keyBytes, err := hex.DecodeString(key); err != nil || len(keyBytes) != secp256k1.PubKeyBytesLenCompressed {
+		return nil, fmt.Errorf("%w: key must be a 33-byte hex public key", ErrInvalidTreasuryPolicy)
+	}
+	if err := validateTreasuryPolicy(policy); err != nil {
+		return nil, err
+	}
+	if err := validatePolicyHash(req.Ticket, "ticket", true); err != nil {
+		return nil, err
+	}
+
+	if _, err := treasuryPolicyRequest(ctx, "settreasurypolicy", req.Ticket, key, policy); err != nil {
+		return nil, err
+	}
+	log.Printf("Set treasury policy %s for Pi key %s", policy, key)
+
+	return &types.TreasuryKeyPolicy{Key: key, Policy: policy, Ticket: req.Ticket}, nil
+}
+
+// ListTSpendPolicies returns the wallet's vote for every treasury spend it
+// knows about. Spends without their own policy report their Pi key's.
+func ListTSpendPolicies(ctx context.Context, ticket string) (*types.TSpendPoliciesResponse, error) {
+	if err := validatePolicyHash(ticket, "ticket", true); err != nil {
+		return nil, err
+	}
+	// An empty tspend hash lists every known spend
+	result, err := treasuryPolicyRequest(ctx, "tspendpolicy", ticket, "")
+	if err != nil {
+		return nil, err
+	}
+	var policies []treasuryPolicyResult
+	if err := json.Unmarshal(result, &policies); err != nil {
+		return nil, fmt.Errorf("failed to parse tspend policies: %w", err)
+	}
+
+	response := &types.TSpendPoliciesResponse{Policies: make([]types.TSpendPolicy, 0, len(policies))}
+	for _, p := range policies {
+		response.Policies = append(response.Policies, types.TSpendPolicy{
+			Hash:   p.Hash,
+			Policy: normalizeTreasuryPolicy(p.Policy),
+			Ticket: p.Ticket,
+		})
+	}
+	return response, nil
+}
+
+// FetchTSpendPolicy returns the wallet's vote on one treasury spend
+func FetchTSpendPolicy(ctx context.Context, hash, ticket string) (*types.TSpendPolicy, error) {
+	if err := validatePolicyHash(hash, "tspend", false); err != nil {
+		return nil, err
+	}
+	if err := validatePolicyHash(ticket, "ticket", true); err != nil {
+		return nil, err
+	}
+
+	result, err := treasuryPolicyRequest(ctx, "tspendpolicy", ticket, hash)
+	if err != nil {
+		return nil, err
+	}
+	var policy treasuryPolicyResult
+	if err := json.Unmarshal(result, &policy); err != nil {
+		return nil, fmt.Errorf("failed to parse tspend policy: %w", err)
+	}
+	return &types.TSpendPolicy{
+		Hash:   hash,
+		Policy: normalizeTreasuryPolicy(policy.Policy),
+		Ticket: policy.Ticket,
+	}, nil
+}
+
+// SetTSpendPolicy sets the wallet's vote on a treasury spend, for all tickets
+// or, with a ticket hash, only that ticket. Yes and no override the Pi key
+// policy; dcrwallet stores abstain by clearing the override, so the spend
+// then follows its key's policy again.
+func SetTSpendPolicy(ctx context.Context, req types.TSpendPolicyRequest) (*types.TSpendPolicy, error) {
+	hash := strings.ToLower(strings.TrimSpace(req.Hash))
+	policy := strings.ToLower(strings.TrimSpace(req.Policy))
+	if err := validatePolicyHash(hash, "tspend", false); err != nil {
+		return nil, err
+	}
+	if err := validateTreasuryPolicy(policy); err != nil {
+		return nil, err
+	}
+	if err := validatePolicyHash(req.Ticket, "ticket", true); err != nil {
+		return nil, err
+	}
+
+	if _, err := treasuryPolicyRequest(ctx, "settspendpolicy", req.Ticket, hash, policy); err != nil {
+		return nil, err
+	}
+	log.Printf("Set tspend policy %s for %s", policy, hash)
+
+	return &types.TSpendPolicy{Hash: hash, Policy: policy, Ticket: req.Ticket}, nil
+}
+
+// attachWalletVotes records the wallet's stance on each active treasury
+// spend. dcrwallet falls back to the Pi key policy only for spends it has
+// seen, so the key policy is looked up here for the others.
+func attachWalletVotes(ctx context.Context, tspends []types.TSpend) {
+	if rpc.WalletClient == nil || len(tspends) == 0 {
+		return
+	}
+
+	keyPolicies := make(map[string]string)
+	if result, err := treasuryPolicyRequest(ctx, "treasurypolicy", ""); err == nil {
+		var policies []treasuryPolicyResult
+		if err := json.Unmarshal(result, &policies); err == nil {
+			for _, p := range policies {
+				if p.Ticket == "" {
+					keyPolicies[p.Key] = normalizeTreasuryPolicy(p.Policy)
+				}
+			}
+		}
+	} else {
+		log.Printf("Warning: Could not get treasury policies: %v", err)
+		return
+	}
+
+	for i := range tspends {
+		policy, err := FetchTSpendPolicy(ctx, tspends[i].TxHash, "")
+		if err != nil {
+			log.Printf("Warning: Could not get tspend policy for %s: %v", tspends[i].TxHash, err)
+			continue
+		}
+		vote := policy.Policy
+		if keyPolicy, ok := keyPolicies[tspends[i].PiKey]; ok && vote == treasuryPolicyAbstain {
+			vote = keyPolicy
+		}
+		tspends[i].WalletVote = vote
+	}
+}
+
+// tspendPiKey extracts the Pi key from a treasury spend's signature script:
+// a 64-byte signature push followed by a 33-byte public key push
+func tspendPiKey(sigScriptHex string) string {
+	script, err := hex.DecodeString(sigScriptHex)
+	if err != nil || len(script) < 66+secp256k1.PubKeyBytesLenCompressed {
+		return ""
+	}
+	return hex.EncodeToString(script[66 : 66+secp256k1.PubKeyBytesLenCompressed])
+}
@@ -0,0 +1,311 @@
+// Copyright (c) 2015-2025 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+
+	"decred.org/dcrwallet/v4/wallet/txsizes"
+	"github.com/decred/dcrd/blockchain/stake/v5"
+	"github.com/decred/dcrd/dcrutil/v4"
+
+	"decred-pulse-backend/rpc"
+	"decred-pulse-backend/types"
+)
+
+// ErrInvalidOutpoint is returned for malformed or unknown outpoints
+var ErrInvalidOutpoint = errors.New("invalid outpoint")
+
+// UTXOFilter selects and paginates unspent outputs
+type UTXOFilter struct {
+	Account  string
+	MinConf  int64
+	Mixed    *bool
+	Locked   *bool
+	DustOnly bool
+	Page     int
+	PageSize int
+}
+
+// unspentOutput is a listunspent result
+type unspentOutput struct {
+	TxID          string  `json:"txid"`
+	Vout          uint32  `json:"vout"`
+	Tree          int8    `json:"tree"`
+	TxType        int     `json:"txtype"`
+	Address       string  `json:"address"`
+	Account       string  `json:"account"`
+	Amount        float64 `json:"amount"`
+	Confirmations int64   `json:"confirmations"`
+	Spendable     bool    `json:"spendable"`
+}
+
+// stakeTxTypeName names the stake transaction type reported by listunspent
+func stakeTxTypeName(txType int) string {
+	switch stake.TxType(txType) {
+	case stake.TxTypeSStx:
+		return "ticket"
+	case stake.TxTypeSSGen:
+		return "vote"
+	case stake.TxTypeSSRtx:
+		return "revocation"
+	case stake.TxTypeTAdd:
+		return "treasuryadd"
+	case stake.TxTypeTSpend:
+		return "tspend"
+	case stake.TxTypeTreasuryBase:
+		return "treasurybase"
+	}
+	return "regular"
+}
+
+// isMixedOutput reports whether an output was created by a mix: its
+// transaction carries the IsMixed flag and it has a mix denomination, so mix
+// change is not mixed
+func isMixedOutput(ctx context.Context, txid string, tree int8, atoms int64) bool {
+	return tree == 0 && mixDenominations[atoms] && isCoinJoinTransaction(ctx, txid)
+}
+
+// fetchUnspent lists the wallet's unlocked unspent outputs with listunspent.
+// An empty account lists every account.
+func fetchUnspent(ctx context.Context, minConf int64, account string) ([]unspentOutput, error) {
+	values := []interface{}{minConf, 9999999, []string{}}
+	if account != "" {
+		values = append(values, account)
+	}
+	params, err := marshalParams(values...)
+	if err != nil {
+		return nil, err
+	}
+	result, err := rpc.WalletClient.RawRequest(ctx, "listunspent", params)
+	if err != nil {
+		return nil, accountError("list unspent outputs", err)
+	}
+
+	var unspent []unspentOutput
+	if err := json.Unmarshal(result, &unspent); err != nil {
+		return nil, fmt.Errorf("failed to parse unspent outputs: %w", err)
+	}
+	return unspent, nil
+}
+
+// fetchLockedUnspent returns the outputs locked with lockunspent
+func fetchLockedUnspent(ctx context.Context) ([]types.Outpoint, error) {
+	result, err := rpc.WalletClient.RawRequest(ctx, "listlockunspent", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list locked outputs: %w", err)
+	}
+	var locked []types.Outpoint
+	if err := json.Unmarshal(result, &locked); err != nil {
+		return nil, fmt.Errorf("failed to parse locked outputs: %w", err)
+	}
+	return locked, nil
+}
+
+// fetchLockedOutput describes a locked output from its wallet transaction,
+// since listunspent leaves locked outputs out
+func fetchLockedOutput(ctx context.Context, op types.Outpoint) (*unspentOutput, error) {
+	params, err := marshalParams(op.TxID)
+	if err != nil {
+		return nil, err
+	}
+	result, err := rpc.WalletClient.RawRequest(ctx, "gettransaction", params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transaction %s: %w", op.TxID, err)
+	}
+
+	var walletTx struct {
+		Confirmations int64 `json:"confirmations"`
+		Details       []struct {
+			Account  string  `json:"account"`
+			Address  string  `json:"address"`
+			Amount   float64 `json:"amount"`
+			Category string  `json:"category"`
+			Vout     uint32  `json:"vout"`
+		} `json:"details"`
+	}
+	if err := json.Unmarshal(result, &walletTx); err != nil {
+		return nil, fmt.Errorf("failed to parse transaction %s: %w", op.TxID, err)
+	}
+	for _, d := range walletTx.Details {
+		if d.Vout != op.Vout || d.Amount <= 0 {
+			continue
+		}
+		return &unspentOutput{
+			TxID:          op.TxID,
+			Vout:          op.Vout,
+			Tree:          op.Tree,
+			Address:       d.Address,
+			Account:       d.Account,
+			Amount:        d.Amount,
+			Confirmations: walletTx.Confirmations,
+		}, nil
+	}
+	return nil, fmt.Errorf("output %s:%d is not a wallet credit", op.TxID, op.Vout)
+}
+
+// fetchRelayFee returns the wallet's relay fee per kB with getwalletfee
+func fetchRelayFee(ctx context.Context) (dcrutil.Amount, error) {
+	result, err := rpc.WalletClient.RawRequest(ctx, "getwalletfee", nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get wallet fee: %w", err)
+	}
+	var fee float64
+	if err := json.Unmarshal(result, &fee); err != nil {
+		return 0, fmt.Errorf("invalid getwalletfee result: %w", err)
+	}
+	return dcrutil.NewAmount(fee)
+}
+
+// inputSpendCost is the fee to spend a P2PKH output at feePerKb
+func inputSpendCost(feePerKb dcrutil.Amount) int64 {
+	return int64(txsizes.EstimateInputSize(txsizes.RedeemP2PKHSigScriptSize)) * int64(feePerKb) / 1000
+}
+
+// ListUTXOs returns the wallet's unspent outputs, including locked ones, with
+// their mixed and dust classification
+func ListUTXOs(ctx context.Context, filter UTXOFilter) (*types.UTXOListResponse, error) {
+	unspent, err := fetchUnspent(ctx, filter.MinConf, filter.Account)
+	if err != nil {
+		return nil, err
+	}
+	locked, err := fetchLockedUnspent(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	relayFee, err := fetchRelayFee(ctx)
+	if err != nil {
+		log.Printf("Warning: Using the default relay fee for dust: %v", err)
+		relayFee = 1e4
+	}
+	spendCost := inputSpendCost(relayFee)
+
+	utxos := make([]types.UTXO, 0, len(unspent)+len(locked))
+	for _, out := range unspent {
+		utxos = append(utxos, convertUnspent(ctx, out, false, spendCost))
+	}
+	for _, op := range locked {
+		out, err := fetchLockedOutput(ctx, op)
+		if err != nil {
+			log.Printf("Warning: Skipping locked output: %v", err)
+			continue
+		}
+		if filter.Account != "" && out.Account != filter.Account {
+			continue
+		}
+		if out.Confirmations < filter.MinConf {
+			continue
+		}
+		utxos = append(utxos, convertUnspent(ctx, *out, true, spendCost))
+	}
+
+	response := &types.UTXOListResponse{}
+	var total, mixed int64
+	matched := make([]types.UTXO, 0, len(utxos))
+	for _, u := range utxos {
+		if filter.Mixed != nil && u.IsMixed != *filter.Mixed {
+			continue
+		}
+		if filter.Locked != nil && u.Locked != *filter.Locked {
+			continue
+		}
+		if filter.DustOnly && !u.IsDust {
+			continue
+		}
+		matched = append(matched, u)
+
+		atoms := toAtoms(u.Amount)
+		total += atoms
+		if u.IsMixed {
+			mixed += atoms
+		}
+		if u.Locked {
+			response.LockedCount++
+		}
+		if u.IsDust {
+			response.DustCount++
+		}
+	}
+
+	sort.SliceStable(matched, func(i, j int) bool {
+		return matched[i].Amount > matched[j].Amount
+	})
+
+	window := paginate(len(matched), filter.Page, filter.PageSize, 100)
+
+	response.UTXOs = matched[window.start:window.end]
+	response.TotalAmount = dcrutil.Amount(total).ToCoin()
+	response.MixedAmount = dcrutil.Amount(mixed).ToCoin()
+	response.UnmixedAmount = dcrutil.Amount(total - mixed).ToCoin()
+	response.Total = len(matched)
+	response.CurrentPage = window.page
+	response.PageSize = window.pageSize
+	response.TotalPages = window.totalPages
+	return response, nil
+}
+
+// convertUnspent converts a listunspent result into the API type
+func convertUnspent(ctx context.Context, out unspentOutput, locked bool, spendCost int64) types.UTXO {
+	atoms := toAtoms(out.Amount)
+	return types.UTXO{
+		TxID:          out.TxID,
+		Vout:          out.Vout,
+		Tree:          out.Tree,
+		TxType:        stakeTxTypeName(out.TxType),
+		Account:       out.Account,
+		Address:       out.Address,
+		Amount:        out.Amount,
+		Confirmations: out.Confirmations,
+		Spendable:     out.Spendable && !locked,
+		Locked:        locked,
+		IsMixed:       isMixedOutput(ctx, out.TxID, out.Tree, atoms),
+		IsDust:        atoms <= spendCost,
+	}
+}
+
+// LockUTXOs locks (or with unlock, unlocks) outputs for automatic coin
+// selection with lockunspent. Locks are kept in memory by dcrwallet and are
+// cleared when it restarts. Inputs of prepared offline transactions cannot
+// be unlocked here; cancel the offline transaction instead.
+func LockUTXOs(ctx context.Context, outputs []types.Outpoint, unlock bool) error {
+	if len(outputs) == 0 {
+		return fmt.Errorf("%w: at least one output is required", ErrInvalidOutpoint)
+	}
+	for _, op := range outputs {
+		if len(op.TxID) != 64 {
+			return fmt.Errorf("%w: txid %q", ErrInvalidOutpoint, op.TxID)
+		}
+		if op.Tree != 0 && op.Tree != 1 {
+			return fmt.Errorf("%w: tree must be 0 (regular) or 1 (stake)", ErrInvalidOutpoint)
+		}
+	}
+
+	if unlock {
+		if err := checkOutputsNotReserved(outputs); err != nil {
+			return err
+		}
+	}
+
+	params, err := marshalParams(unlock, outputs)
+	if err != nil {
+		return err
+	}
+	if _, err := rpc.WalletClient.RawRequest(ctx, "lockunspent", params); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidOutpoint, err)
+	}
+
+	action := "Locked"
+	if unlock {
+		action = "Unlocked"
+	}
+	log.Printf("%s %d output(s)", action, len(outputs))
+	return nil
+}
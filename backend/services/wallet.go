@@ -13,6 +13,7 @@ import (
 	"sync"
 	"time"
 
+	"decred-pulse-backend/pricing"
 	"decred-pulse-backend/rpc"
 	"decred-pulse-backend/types"
 	"decred-pulse-backend/utils"
@@ -22,154 +23,37 @@ var (
 	// Track wallet sync
 	prevWalletHeight int64
 	walletSyncMutex  sync.Mutex
-
-	// Track pending rescan requests
-	pendingRescanMutex sync.RWMutex
-	pendingRescanTime  *time.Time
 )
 
-// SetPendingRescan marks that a rescan has been requested
-func SetPendingRescan() {
-	pendingRescanMutex.Lock()
-	defer pendingRescanMutex.Unlock()
-	now := time.Now()
-	pendingRescanTime = &now
-	log.Printf("Rescan request marked as pending at %v", now)
-}
-
-// ClearPendingRescan clears the pending rescan flag
-func ClearPendingRescan() {
-	pendingRescanMutex.Lock()
-	defer pendingRescanMutex.Unlock()
-	if pendingRescanTime != nil {
-		log.Printf("Clearing pending rescan flag (was pending since %v)", *pendingRescanTime)
-	}
-	pendingRescanTime = nil
-}
-
-// IsPendingRescan checks if a rescan is pending and returns how long to wait
-func IsPendingRescan() (bool, int) {
-	pendingRescanMutex.RLock()
-	defer pendingRescanMutex.RUnlock()
-
-	if pendingRescanTime == nil {
-		return false, 0
-	}
-
-	// Check if pending rescan is recent (within last 5 minutes)
-	elapsed := time.Since(*pendingRescanTime)
-	if elapsed > 5*time.Minute {
-		return false, 0
-	}
-
-	// Wait up to 120 seconds (2 minutes) for rescan to start when pending
-	return true, 120
-}
-
-// CheckRescanProgress checks if a rescan is active using ONLY the pending flag and RPC
-// No log parsing whatsoever - clean RPC-based solution
-func CheckRescanProgress() (bool, int64, error) {
-	if rpc.WalletClient == nil {
-		return false, 0, fmt.Errorf("wallet RPC client not initialized")
-	}
-
-	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
-	defer cancel()
-
-	// Always get wallet and chain height
-	_, walletHeight, err := rpc.WalletClient.GetBestBlock(ctx)
-	if err != nil {
-		// Can't get wallet height - might be busy
-		isPending, _ := IsPendingRescan()
-		return isPending, 0, nil
-	}
-
-	chainHeight := int64(0)
-	if rpc.DcrdClient != nil {
-		height, err := rpc.DcrdClient.GetBlockCount(ctx)
-		if err == nil {
-			chainHeight = height
-		}
-	}
-
-	// Check if we have a pending rescan
-	isPending, _ := IsPendingRescan()
-
-	if isPending {
-		// Pending rescan - could be discovering addresses OR rescanning blocks
-		// Check how far behind we are
-		blocksBehind := chainHeight - walletHeight
-
-		if blocksBehind > 100 {
-			// Significantly behind - actively rescanning!
-			// Clear the pending flag since rescan is confirmed active
-			ClearPendingRescan()
-			return true, walletHeight, nil
-		}
-
-		// At chain tip - probably in address discovery phase
-		// Return as rescanning but with current height
-		return true, walletHeight, nil
-	}
-
-	// No pending flag - check for unexpected rescan activity
-	blocksBehind := chainHeight - walletHeight
-
-	// Track height changes for progress detection
-	walletSyncMutex.Lock()
-	deltaHeight := walletHeight - prevWalletHeight
-	prevWalletHeight = walletHeight
-	walletSyncMutex.Unlock()
-
-	// Detect rescans without pending flag (e.g., from page reload)
-	isRescanning := false
-	if blocksBehind > 100 {
-		// Significantly behind - rescanning
-		isRescanning = true
-	} else if blocksBehind > 2 && deltaHeight > 50 {
-		// Slightly behind + rapid progress = rescanning
-		isRescanning = true
-	}
-
-	return isRescanning, walletHeight, nil
-}
-
-// Deprecated: Use CheckRescanProgress instead
-// ParseWalletLogsForRescan is kept for backward compatibility but should not be used
-func ParseWalletLogsForRescan() (bool, int64, error) {
-	// Redirect to RPC-based check
-	return CheckRescanProgress()
-}
-
 func FetchWalletStatus() (*types.WalletStatus, error) {
 	// Use a longer timeout for wallet status to handle rescan scenarios
 	// During rescan, RPC calls can be slow but should still respond
 	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
 	defer cancel()
 
+	// Rescan progress comes from the rescan controller, which records the
+	// gRPC stream's RescannedThrough heights
+	rescan := GetRescanStatus()
+	var activeRescan *types.RescanStatus
+	if rescan.IsRescanning {
+		activeRescan = &rescan
+	}
+
 	// Get wallet info using getinfo
 	walletInfo, err := rpc.WalletClient.GetInfo(ctx)
 	if err != nil {
-		// If RPC fails, check if it's because of an active rescan
-		isRescanning, logScanHeight, logErr := ParseWalletLogsForRescan()
-		if logErr == nil && isRescanning {
-			// Wallet is busy rescanning, use log data
-			if rpc.DcrdClient != nil {
-				chainHeight, err := rpc.DcrdClient.GetBlockCount(ctx)
-				if err == nil {
-					syncProgress := (float64(logScanHeight) / float64(chainHeight)) * 100
-					return &types.WalletStatus{
-						Status:           "syncing",
-						SyncProgress:     syncProgress,
-						SyncHeight:       logScanHeight,
-						BestBlockHash:    "",
-						Version:          "unknown",
-						Unlocked:         true,
-						RescanInProgress: true,
-						SyncMessage:      fmt.Sprintf("Rescanning... %d/%d blocks (%.1f%%)", logScanHeight, chainHeight, syncProgress),
-					}, nil
-				}
-			}
+		// The wallet can be too busy to answer during an active rescan
+		if activeRescan != nil {
+			return &types.WalletStatus{
+				Status:           "syncing",
+				SyncProgress:     rescan.Progress,
+				SyncHeight:       rescan.ScanHeight,
+				Version:          "unknown",
+				Unlocked:         lastKnownWalletUnlocked(),
+				RescanInProgress: true,
+				SyncMessage:      rescan.Message,
+				Rescan:           activeRescan,
+			}, nil
 		}
 
 		return &types.WalletStatus{
@@ -178,13 +62,22 @@ func FetchWalletStatus() (*types.WalletStatus, error) {
 		}, nil
 	}
 
+	// Lock state and sync mode come from walletinfo
+	unlocked := lastKnownWalletUnlocked()
+	info, err := fetchWalletInfo(ctx)
+	if err == nil {
+		unlocked = info.Unlocked
+	} else {
+		log.Printf("Warning: Could not get wallet lock state: %v", err)
+	}
+
 	// Determine wallet status
 	status := "synced"
 	syncProgress := 100.0
 	syncMessage := "Fully synced"
-	rescanInProgress := false
 	var syncHeight int64 = 0
 	bestBlockHash := ""
+	var network *types.WalletNetwork
 
 	// Get best block from wallet
 	bestHash, bestHeight, err := rpc.WalletClient.GetBestBlock(ctx)
@@ -192,79 +85,66 @@ func FetchWalletStatus() (*types.WalletStatus, error) {
 		syncHeight = bestHeight
 		bestBlockHash = bestHash.String()
 
-		// Get current block count from dcrd for comparison
-		if rpc.DcrdClient != nil {
-			chainHeight, err := rpc.DcrdClient.GetBlockCount(ctx)
-			if err == nil {
-				walletHeight := bestHeight
-
-				// Calculate sync progress
-				// During rescan, wallet height changes rapidly through already-synced blocks
-				// Allow a buffer of 2 blocks to account for chain growth during sync
-				blocksBehind := chainHeight - walletHeight
-				if blocksBehind > 2 {
-					status = "syncing"
-					syncProgress = (float64(walletHeight) / float64(chainHeight)) * 100
-
-					// Calculate delta for sync message
-					walletSyncMutex.Lock()
-					deltaHeight := walletHeight - prevWalletHeight
-					prevWalletHeight = walletHeight
-					walletSyncMutex.Unlock()
-
-					if deltaHeight > 0 {
-						// If scanning more than 100 blocks per check (30s), likely a rescan
-						if deltaHeight > 100 {
-							syncMessage = fmt.Sprintf("Rescanning... %d/%d blocks (%.1f%%)", walletHeight, chainHeight, syncProgress)
-							rescanInProgress = true
-						} else {
-							syncMessage = fmt.Sprintf("Syncing... scanned %s blocks recently", utils.FormatNumber(deltaHeight))
-						}
-					} else {
-						syncMessage = fmt.Sprintf("Syncing... %d/%d blocks", walletHeight, chainHeight)
-					}
-					rescanInProgress = true
+		// The chain height comes from dcrd when Pulse has it, otherwise from
+		// the wallet's own view of the network (SPV peers, syncstatus)
+		if info != nil {
+			network = walletNetwork(ctx, info, bestHeight)
+		} else if rpc.DcrdClient != nil {
+			if chainHeight, err := rpc.DcrdClient.GetBlockCount(ctx); err == nil {
+				network = &types.WalletNetwork{Mode: syncModeRPC, Synced: true, ChainHeight: chainHeight, ChainHeightSource: chainHeightDcrd}
+			}
+		}
+
+		if network != nil {
+			walletHeight := bestHeight
+			chainHeight := network.ChainHeight
+
+			// Allow a buffer of 2 blocks to account for chain growth during sync
+			blocksBehind := chainHeight - walletHeight
+
+			walletSyncMutex.Lock()
+			deltaHeight := walletHeight - prevWalletHeight
+			prevWalletHeight = walletHeight
+			walletSyncMutex.Unlock()
+
+			if blocksBehind > 2 {
+				status = "syncing"
+				syncProgress = (float64(walletHeight) / float64(chainHeight)) * 100
+				if deltaHeight > 0 {
+					syncMessage = fmt.Sprintf("Syncing... scanned %s blocks recently", utils.FormatNumber(deltaHeight))
 				} else {
-					// Even if heights match, check if we were recently rescanning
-					walletSyncMutex.Lock()
-					deltaHeight := walletHeight - prevWalletHeight
-					if deltaHeight > 100 {
-						// Just finished a fast rescan
-						syncMessage = "Rescan completed, wallet fully synced"
-					}
-					prevWalletHeight = walletHeight
-					walletSyncMutex.Unlock()
+					syncMessage = fmt.Sprintf("Syncing... %d/%d blocks", walletHeight, chainHeight)
 				}
+			} else if !network.Synced && network.ChainHeightSource != chainHeightDcrd {
+				// Without dcrd the peers' heights can lag, so trust syncstatus
+				status = "syncing"
+				syncProgress = network.HeadersFetchProgress
+				syncMessage = "Syncing..."
+				if network.Mode == syncModeSPV {
+					syncMessage = "Syncing with SPV peers..."
+				}
+			}
+			if network.Mode == syncModeSPV && !network.Connected {
+				status = "disconnected"
+				syncMessage = "Wallet has no SPV peers"
 			}
 		}
 	} else {
 		status = "disconnected"
 		syncMessage = "Wallet not connected to dcrd"
+		if LastKnownWalletSyncMode() == syncModeSPV {
+			syncMessage = "Wallet not connected to the network"
+		}
 	}
 
-	// Check Docker logs for active rescan (especially for manual rescans via rescan button or xpub import)
-	// This is more reliable than RPC during intensive rescans
-	isRescanning, logScanHeight, logErr := ParseWalletLogsForRescan()
-	if logErr == nil && isRescanning {
-		// Get chain height for progress calculation
-		if rpc.DcrdClient != nil {
-			chainHeight, err := rpc.DcrdClient.GetBlockCount(ctx)
-			if err == nil {
-				// Only override status if the wallet is actually behind
-				// Allow a small buffer of 2 blocks to account for chain growth during sync
-				blocksBehind := chainHeight - logScanHeight
-				if blocksBehind > 2 {
-					status = "syncing"
-					rescanInProgress = true
-					syncHeight = logScanHeight
-					syncProgress = (float64(logScanHeight) / float64(chainHeight)) * 100
-					syncMessage = fmt.Sprintf("Rescanning... %d/%d blocks (%.1f%%)", logScanHeight, chainHeight, syncProgress)
-					log.Printf("Rescan detected from logs: block %d / %d (%.1f%%)", logScanHeight, chainHeight, syncProgress)
-				} else {
-					log.Printf("Rescan message found in logs but wallet is at chain tip (%d/%d, %d blocks behind) - rescan complete", logScanHeight, chainHeight, blocksBehind)
-				}
-			}
-		}
+	// An active rescan takes precedence over the chain sync state
+	if activeRescan != nil {
+		status = "syncing"
+		syncProgress = rescan.Progress
+		syncHeight = rescan.ScanHeight
+		syncMessage = rescan.Message
+	} else if rescan.State == RescanStateCompleted && rescan.FinishedAt != nil && time.Since(*rescan.FinishedAt) < time.Minute {
+		syncMessage = "Rescan completed, wallet fully synced"
 	}
 
 	// Parse version number from single integer
@@ -273,16 +153,23 @@ func FetchWalletStatus() (*types.WalletStatus, error) {
 	minor := (walletInfo.Version / 10000) % 100
 	patch := (walletInfo.Version / 100) % 100
 
-	return &types.WalletStatus{
+	walletStatus := &types.WalletStatus{
 		Status:           status,
 		SyncProgress:     syncProgress,
 		SyncHeight:       syncHeight,
 		BestBlockHash:    bestBlockHash,
 		Version:          fmt.Sprintf("v%d.%d.%d", major, minor, patch),
-		Unlocked:         true, // Assume unlocked since we auto-unlock
-		RescanInProgress: rescanInProgress,
+		Unlocked:         unlocked,
+		RescanInProgress: activeRescan != nil,
 		SyncMessage:      syncMessage,
-	}, nil
+		Rescan:           activeRescan,
+	}
+	if network != nil {
+		walletStatus.SyncMode = network.Mode
+		walletStatus.ChainHeight = network.ChainHeight
+		walletStatus.PeerCount = network.PeerCount
+	}
+	return walletStatus, nil
 }
 
 func FetchWalletDashboardData() (*types.WalletDashboardData, error) {
@@ -367,11 +254,22 @@ func FetchWalletDashboardDataWithContext(ctx context.Context) (*types.WalletDash
 		log.Printf("Warning: Staking info fetch cancelled: %v", ctx.Err())
 	}
 
+	// Wallet-wide total for the primary account info, per account otherwise
+	if accountInfo.CumulativeTotal > 0 {
+		accountInfo.TotalBalanceFiat = pricing.Convert(accountInfo.CumulativeTotal)
+	} else {
+		accountInfo.TotalBalanceFiat = pricing.Convert(accountInfo.TotalBalance)
+	}
+	for i := range accounts {
+		accounts[i].TotalBalanceFiat = pricing.Convert(accounts[i].TotalBalance)
+	}
+
 	return &types.WalletDashboardData{
 		WalletStatus: *walletStatus,
 		AccountInfo:  *accountInfo,
 		Accounts:     accounts,
 		StakingInfo:  stakingInfo,
+		ExchangeRate: pricing.CurrentRate(),
 		LastUpdate:   time.Now(),
 	}, nil
 }
@@ -518,54 +416,6 @@ func FetchAllAccounts(ctx context.Context) ([]types.AccountInfo, error) {
 
 // Old FetchTransactions functions removed - replaced by ListTransactions
 
-func FetchAddresses() ([]types.Address, error) {
-	return FetchAddressesWithContext(context.Background())
-}
-
-func FetchAddressesWithContext(ctx context.Context) ([]types.Address, error) {
-	// List addresses via raw RPC - only return addresses with funds (not empty)
-	// This prevents returning 40k+ empty addresses
-	result, err := rpc.WalletClient.RawRequest(ctx, "listreceivedbyaddress", []json.RawMessage{
-		json.RawMessage(`0`),     // minconf
-		json.RawMessage(`false`), // include empty = false (only show addresses with funds)
-	})
-	if err != nil {
-		log.Printf("Warning: Failed to list addresses: %v", err)
-		return []types.Address{}, nil
-	}
-
-	// Parse the result
-	var rawAddrList []map[string]interface{}
-	if err := json.Unmarshal(result, &rawAddrList); err != nil {
-		log.Printf("Warning: Failed to unmarshal addresses: %v", err)
-		return []types.Address{}, nil
-	}
-
-	// Limit to 100 addresses max to prevent huge payloads
-	maxAddresses := 100
-	if len(rawAddrList) > maxAddresses {
-		log.Printf("Warning: Wallet has %d addresses with funds, limiting to %d", len(rawAddrList), maxAddresses)
-		rawAddrList = rawAddrList[:maxAddresses]
-	}
-
-	addresses := make([]types.Address, 0, len(rawAddrList))
-	for _, addr := range rawAddrList {
-		address, _ := addr["address"].(string)
-		account, _ := addr["account"].(string)
-		amount, _ := addr["amount"].(float64)
-
-		addresses = append(addresses, types.Address{
-			Address: address,
-			Account: account,
-			Used:    amount > 0, // Has received funds
-			Path:    "",         // Would need to query separately
-		})
-	}
-
-	log.Printf("Returning %d addresses with funds", len(addresses))
-	return addresses, nil
-}
-
 func FetchWalletStakingInfo(ctx context.Context) (*types.WalletStakingInfo, error) {
 	stakingInfo := &types.WalletStakingInfo{}
 
@@ -702,6 +552,7 @@ func ListTransactions(ctx context.Context, count, from int) (*types.TransactionL
 	for _, rpcTx := range rpcTransactions {
 		// Check if this transaction is from a CoinJoin/StakeShuffle
 		// Check both send and receive transactions since CoinJoin involves both
+		// Classifications are cached by txid, so repeated rows cost no RPC round-trip
 		isMixed := false
 		if rpcTx.TxType == "regular" && (rpcTx.Category == "receive" || rpcTx.Category == "send") {
 			isMixed = isCoinJoinTransaction(ctx, rpcTx.TxID)
@@ -779,81 +630,16 @@ func ListTransactions(ctx context.Context, count, from int) (*types.TransactionL
 		return timeI > timeJ // Descending order (newest first)
 	})
 
+	for i := range transactions {
+		t := transactions[i].Time
+		if transactions[i].BlockTime > 0 {
+			t = time.Unix(transactions[i].BlockTime, 0)
+		}
+		transactions[i].Fiat = pricing.ConvertAt(transactions[i].Amount, t)
+	}
+
 	return &types.TransactionListResponse{
 		Transactions: transactions,
 		Total:        len(transactions),
 	}, nil
 }
-
-// isCoinJoinTransaction checks if a transaction is a CoinJoin/StakeShuffle by analyzing its structure
-// CoinJoin transactions typically have:
-// 1. Multiple inputs (usually 5+)
-// 2. Multiple outputs with equal or very similar amounts
-func isCoinJoinTransaction(ctx context.Context, txHash string) bool {
-	if rpc.DcrdClient == nil {
-		log.Printf("CoinJoin check skipped for %s: no dcrd connection", txHash)
-		return false // Can't check without node connection
-	}
-
-	// Get raw transaction
-	rawTxResult, err := rpc.DcrdClient.RawRequest(ctx, "getrawtransaction", []json.RawMessage{
-		json.RawMessage(fmt.Sprintf(`"%s"`, txHash)),
-		json.RawMessage("1"), // verbose=1 to get decoded transaction (must be int, not bool)
-	})
-	if err != nil {
-		log.Printf("CoinJoin check failed for %s: getrawtransaction error: %v", txHash, err)
-		return false
-	}
-
-	// Parse the verbose transaction response
-	var tx struct {
-		Vin []struct {
-			Txid string `json:"txid,omitempty"`
-		} `json:"vin"`
-		Vout []struct {
-			Value float64 `json:"value"`
-		} `json:"vout"`
-	}
-
-	if err := json.Unmarshal(rawTxResult, &tx); err != nil {
-		log.Printf("CoinJoin check failed for %s: unmarshal error: %v", txHash, err)
-		return false
-	}
-
-	log.Printf("Analyzing tx %s: %d inputs, %d outputs", txHash, len(tx.Vin), len(tx.Vout))
-
-	// CoinJoin heuristics:
-	// 1. Must have multiple inputs (typically 5+ participants)
-	if len(tx.Vin) < 3 {
-		log.Printf("TX %s: not enough inputs (%d < 3)", txHash, len(tx.Vin))
-		return false
-	}
-
-	// 2. Must have multiple outputs
-	if len(tx.Vout) < 3 {
-		log.Printf("TX %s: not enough outputs (%d < 3)", txHash, len(tx.Vout))
-		return false
-	}
-
-	// 3. Check if outputs have similar amounts (CoinJoin signature)
-	// Count outputs with the same value
-	outputValues := make(map[float64]int)
-	for _, vout := range tx.Vout {
-		// Round to 8 decimal places to account for floating point precision
-		rounded := float64(int64(vout.Value*100000000)) / 100000000
-		outputValues[rounded]++
-	}
-
-	log.Printf("TX %s: output value distribution: %v", txHash, outputValues)
-
-	// If we have 3+ outputs with the same value, it's likely a CoinJoin
-	for value, count := range outputValues {
-		if count >= 3 {
-			log.Printf("TX %s: IDENTIFIED AS COINJOIN - %d outputs with value %.8f", txHash, count, value)
-			return true
-		}
-	}
-
-	log.Printf("TX %s: not a CoinJoin (no 3+ matching output values)", txHash)
-	return false
-}
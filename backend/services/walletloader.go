@@ -0,0 +1,484 @@
+// Copyright (c) 2015-2025 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	pb "decred.org/dcrwallet/v4/rpc/walletrpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"decred-pulse-backend/rpc"
+	"decred-pulse-backend/types"
+)
+
+const (
+	// walletSyncStartupGrace is how long a sync start waits for dcrwallet to
+	// reject it (wrong passphrase, already syncing) before reporting success
+	walletSyncStartupGrace = 3 * time.Second
+
+	// walletSeedLength is the seed size in bytes, giving 33 seed words
+	walletSeedLength = 32
+
+	syncModeRPC = "rpc"
+	syncModeSPV = "spv"
+)
+
+var (
+	// ErrWalletExists is returned when creating a wallet over an existing one
+	ErrWalletExists = errors.New("a wallet already exists")
+
+	// ErrWalletNotFound is returned when opening a wallet that does not exist
+	ErrWalletNotFound = errors.New("no wallet exists, create or restore one first")
+
+	// ErrInvalidWalletRequest is returned for invalid seeds, xpubs,
+	// passphrases and sync modes
+	ErrInvalidWalletRequest = errors.New("invalid wallet request")
+
+	// ErrWalletSyncing is returned when the wallet is already syncing
+	ErrWalletSyncing = errors.New("wallet is already syncing")
+)
+
+// WalletLoaderConfig configures how wallets created or opened through the
+// loader sync with the network
+type WalletLoaderConfig struct {
+	SyncMode     string   // "rpc" (through dcrd) or "spv"
+	DcrdAddress  string   // dcrd host:port as dcrwallet reaches it
+	DcrdUser     string   // dcrd RPC credentials for RPC sync
+	DcrdPassword string   //
+	DcrdCertPath string   // dcrd TLS certificate for RPC sync
+	SpvConnect   []string // Peers for SPV sync, all peers if empty
+}
+
+var (
+	walletLoaderMutex  sync.Mutex
+	walletLoaderConfig = WalletLoaderConfig{SyncMode: syncModeRPC}
+	walletSyncStatus   types.WalletSyncStatus
+	walletSyncCancel   context.CancelFunc
+)
+
+// InitWalletLoader sets the sync configuration. An empty mode keeps RPC sync.
+func InitWalletLoader(cfg WalletLoaderConfig) {
+	walletLoaderMutex.Lock()
+	defer walletLoaderMutex.Unlock()
+
+	if cfg.SyncMode == "" {
+		cfg.SyncMode = syncModeRPC
+	}
+	walletLoaderConfig = cfg
+}
+
+// FetchWalletLoaderStatus reports whether a wallet exists and is loaded, and
+// the state of a sync started by this backend
+func FetchWalletLoaderStatus(ctx context.Context) (*types.WalletLoaderStatus, error) {
+	if rpc.WalletLoaderClient == nil {
+		return nil, fmt.Errorf("wallet gRPC client not initialized")
+	}
+
+	resp, err := rpc.WalletLoaderClient.WalletExists(ctx, &pb.WalletExistsRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to check for a wallet: %w", err)
+	}
+	loaderStatus := &types.WalletLoaderStatus{
+		Exists: resp.Exists,
+		Sync:   fetchWalletSyncStatus(),
+	}
+	if rpc.WalletClient != nil {
+		_, err := fetchWalletInfo(ctx)
+		loaderStatus.Loaded = err == nil
+	}
+	return loaderStatus, nil
+}
+
+// CreateWallet creates a wallet from a new random seed and starts syncing.
+// The seed is returned to the caller once and never stored or logged.
+func CreateWallet(ctx context.Context, req types.WalletCreateRequest) (*types.WalletSeedResponse, error) {
+	if err := checkNoWallet(ctx); err != nil {
+		return nil, err
+	}
+	if req.PrivatePassphrase == "" {
+		return nil, fmt.Errorf("%w: a private passphrase is required", ErrInvalidWalletRequest)
+	}
+
+	seed, err := rpc.SeedClient.GenerateRandomSeed(ctx, &pb.GenerateRandomSeedRequest{SeedLength: walletSeedLength})
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate seed: %w", err)
+	}
+	_, err = rpc.WalletLoaderClient.CreateWallet(ctx, &pb.CreateWalletRequest{
+		PublicPassphrase:  []byte(req.PublicPassphrase),
+		PrivatePassphrase: []byte(req.PrivatePassphrase),
+		Seed:              seed.SeedBytes,
+	})
+	if err != nil {
+		return nil, walletLoaderError("create wallet", err)
+	}
+	log.Println("Created a new wallet")
+
+	return &types.WalletSeedResponse{
+		SeedMnemonic: seed.SeedMnemonic,
+		SeedHex:      seed.SeedHex,
+		Message:      "Write down the seed and keep it offline. It will not be shown again.",
+		Sync:         startSyncAfterLoad(types.WalletSyncRequest{}),
+	}, nil
+}
+
+// RestoreWallet creates a wallet from seed words or a hex seed and starts
+// syncing with account discovery
+func RestoreWallet(ctx context.Context, req types.WalletRestoreRequest) (*types.WalletLoaderResponse, error) {
+	if err := checkNoWallet(ctx); err != nil {
+		return nil, err
+	}
+	if req.PrivatePassphrase == "" {
+		return nil, fmt.Errorf("%w: a private passphrase is required", ErrInvalidWalletRequest)
+	}
+
+	decoded, err := rpc.SeedClient.DecodeSeed(ctx, &pb.DecodeSeedRequest{UserInput: strings.TrimSpace(req.Seed)})
+	if err != nil {
+		// The error describes the seed problem without echoing the seed
+		return nil, fmt.Errorf("%w: %s", ErrInvalidWalletRequest, status.Convert(err).Message())
+	}
+	_, err = rpc.WalletLoaderClient.CreateWallet(ctx, &pb.CreateWalletRequest{
+		PublicPassphrase:  []byte(req.PublicPassphrase),
+		PrivatePassphrase: []byte(req.PrivatePassphrase),
+		Seed:              decoded.DecodedSeed,
+	})
+	if err != nil {
+		return nil, walletLoaderError("restore wallet", err)
+	}
+	log.Println("Restored a wallet from seed")
+
+	// Restored wallets find their used accounts and addresses while syncing
+	syncStatus := startSyncAfterLoad(types.WalletSyncRequest{
+		DiscoverAccounts:  true,
+		PrivatePassphrase: req.PrivatePassphrase,
+	})
+	return &types.WalletLoaderResponse{
+		Success: true,
+		Message: "Wallet restored, syncing and discovering accounts",
+		Sync:    syncStatus,
+	}, nil
+}
+
+// CreateWatchOnlyWallet creates a wallet that tracks an account xpub without
+// private keys, and starts syncing
+func CreateWatchOnlyWallet(ctx context.Context, req types.WalletWatchOnlyRequest) (*types.WalletLoaderResponse, error) {
+	if err := checkNoWallet(ctx); err != nil {
+		return nil, err
+	}
+	xpub := strings.TrimSpace(req.Xpub)
+	if xpub == "" {
+		return nil, fmt.Errorf("%w: an xpub is required", ErrInvalidWalletRequest)
+	}
+
+	_, err := rpc.WalletLoaderClient.CreateWatchingOnlyWallet(ctx, &pb.CreateWatchingOnlyWalletRequest{
+		ExtendedPubKey:   xpub,
+		PublicPassphrase: []byte(req.PublicPassphrase),
+	})
+	if err != nil {
+		return nil, walletLoaderError("create watch-only wallet", err)
+	}
+	log.Println("Created a watch-only wallet")
+
+	return &types.WalletLoaderResponse{
+		Success: true,
+		Message: "Watch-only wallet created, syncing",
+		Sync:    startSyncAfterLoad(types.WalletSyncRequest{}),
+	}, nil
+}
+
+// OpenWallet opens an existing wallet and starts syncing
+func OpenWallet(ctx context.Context, req types.WalletOpenRequest) (*types.WalletLoaderResponse, error) {
+	if rpc.WalletLoaderClient == nil {
+		return nil, fmt.Errorf("wallet gRPC client not initialized")
+	}
+
+	resp, err := rpc.WalletLoaderClient.OpenWallet(ctx, &pb.OpenWalletRequest{PublicPassphrase: []byte(req.PublicPassphrase)})
+	if err != nil {
+		return nil, walletLoaderError("open wallet", err)
+	}
+	message := "Wallet opened, syncing"
+	if resp.WatchingOnly {
+		message = "Watch-only wallet opened, syncing"
+	}
+	log.Println("Opened the wallet")
+
+	return &types.WalletLoaderResponse{
+		Success: true,
+		Message: message,
+		Sync:    startSyncAfterLoad(types.WalletSyncRequest{}),
+	}, nil
+}
+
+// startSyncAfterLoad starts syncing a freshly loaded wallet. A failure is
+// reported in the sync status rather than failing the load.
+func startSyncAfterLoad(req types.WalletSyncRequest) types.WalletSyncStatus {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*walletSyncStartupGrace)
+	defer cancel()
+
+	syncStatus, err := StartWalletSync(ctx, req)
+	if err != nil {
+		log.Printf("Warning: Wallet sync did not start: %v", err)
+		s := fetchWalletSyncStatus()
+		s.LastError = err.Error()
+		return s
+	}
+	return *syncStatus
+}
+
+// ResumeWalletSync restarts the sync of a wallet set up through the loader
+// after Pulse restarts, since the sync only runs while Pulse holds its
+// stream. A dcrwallet that already syncs from its own configuration is left
+// alone. It runs in the background so startup does not wait for dcrwallet.
+func ResumeWalletSync() {
+	if rpc.WalletLoaderClient == nil {
+		return
+	}
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		resp, err := rpc.WalletLoaderClient.WalletExists(ctx, &pb.WalletExistsRequest{})
+		if err != nil {
+			log.Printf("Warning: Could not check for a wallet to sync: %v", err)
+			return
+		}
+		if !resp.Exists || fetchWalletSyncStatus().Running {
+			return
+		}
+		if rpc.WalletClient != nil {
+			info, err := fetchWalletInfo(ctx)
+			if err != nil {
+				log.Printf("Warning: Wallet is not loaded, open it to sync: %v", err)
+				return
+			}
+			if info.SPV || info.DaemonConnected {
+				return
+			}
+		}
+
+		log.Println("Resuming wallet sync")
+		startSyncAfterLoad(types.WalletSyncRequest{})
+	}()
+}
+
+// StartWalletSync syncs the loaded wallet through dcrd (RPC) or peer to peer
+// (SPV). The sync runs for as long as the stream stays open, so the stream
+// outlives the request.
+func StartWalletSync(ctx context.Context, req types.WalletSyncRequest) (*types.WalletSyncStatus, error) {
+	if rpc.WalletLoaderClient == nil {
+		return nil, fmt.Errorf("wallet gRPC client not initialized")
+	}
+	if req.DiscoverAccounts && req.PrivatePassphrase == "" {
+		return nil, fmt.Errorf("%w: discovering accounts requires the private passphrase", ErrInvalidWalletRequest)
+	}
+
+	walletLoaderMutex.Lock()
+	cfg := walletLoaderConfig
+	running := walletSyncStatus.Running
+	walletLoaderMutex.Unlock()
+	if running {
+		return nil, ErrWalletSyncing
+	}
+
+	mode := cfg.SyncMode
+	if req.Mode != "" {
+		mode = req.Mode
+	}
+
+	var certificate []byte
+	if mode == syncModeRPC && cfg.DcrdCertPath != "" {
+		var err error
+		if certificate, err = os.ReadFile(cfg.DcrdCertPath); err != nil {
+			return nil, fmt.Errorf("failed to read dcrd certificate: %w", err)
+		}
+	}
+
+	walletLoaderMutex.Lock()
+	if walletSyncStatus.Running {
+		walletLoaderMutex.Unlock()
+		return nil, ErrWalletSyncing
+	}
+	runCtx, cancel := context.WithCancel(context.Background())
+
+	var recv func() (*syncNotification, error)
+	switch mode {
+	case syncModeRPC:
+		stream, err := rpc.WalletLoaderClient.RpcSync(runCtx, &pb.RpcSyncRequest{
+			NetworkAddress:    cfg.DcrdAddress,
+			Username:          cfg.DcrdUser,
+			Password:          []byte(cfg.DcrdPassword),
+			Certificate:       certificate,
+			DiscoverAccounts:  req.DiscoverAccounts,
+			PrivatePassphrase: []byte(req.PrivatePassphrase),
+		})
+		if err != nil {
+			walletLoaderMutex.Unlock()
+			cancel()
+			return nil, fmt.Errorf("failed to start RPC sync: %w", err)
+		}
+		recv = func() (*syncNotification, error) {
+			resp, err := stream.Recv()
+			if err != nil {
+				return nil, err
+			}
+			return &syncNotification{resp.Synced, resp.NotificationType, resp.PeerInformation, resp.RescanProgress}, nil
+		}
+	case syncModeSPV:
+		stream, err := rpc.WalletLoaderClient.SpvSync(runCtx, &pb.SpvSyncRequest{
+			DiscoverAccounts:  req.DiscoverAccounts,
+			PrivatePassphrase: []byte(req.PrivatePassphrase),
+			SpvConnect:        cfg.SpvConnect,
+		})
+		if err != nil {
+			walletLoaderMutex.Unlock()
+			cancel()
+			return nil, fmt.Errorf("failed to start SPV sync: %w", err)
+		}
+		recv = func() (*syncNotification, error) {
+			resp, err := stream.Recv()
+			if err != nil {
+				return nil, err
+			}
+			return &syncNotification{resp.Synced, resp.NotificationType, resp.PeerInformation, resp.RescanProgress}, nil
+		}
+	default:
+		walletLoaderMutex.Unlock()
+		cancel()
+		return nil, fmt.Errorf("%w: sync mode must be %q or %q", ErrInvalidWalletRequest, syncModeRPC, syncModeSPV)
+	}
+
+	now := time.Now()
+	walletSyncStatus = types.WalletSyncStatus{Mode: mode, Running: true, StartedAt: &now}
+	walletSyncCancel = cancel
+	walletLoaderMutex.Unlock()
+
+	done := make(chan error, 1)
+	go func() {
+		for {
+			n, err := recv()
+			if err != nil {
+				done <- err
+				finishWalletSync(runCtx, err)
+				return
+			}
+			updateWalletSync(n)
+		}
+	}()
+
+	select {
+	case err := <-done:
+		return nil, walletLoaderError("sync wallet", err)
+	case <-time.After(walletSyncStartupGrace):
+	}
+
+	log.Printf("Wallet %s sync started", mode)
+	s := fetchWalletSyncStatus()
+	return &s, nil
+}
+
+// syncNotification holds the fields shared by RPC and SPV sync responses
+type syncNotification struct {
+	synced bool
+	kind   pb.SyncNotificationType
+	peers  *pb.PeerNotification
+	rescan *pb.RescanProgressNotification
+}
+
+// updateWalletSync records a sync notification
+func updateWalletSync(n *syncNotification) {
+	walletLoaderMutex.Lock()
+	defer walletLoaderMutex.Unlock()
+
+	walletSyncStatus.Stage = strings.ToLower(n.kind.String())
+	switch n.kind {
+	case pb.SyncNotificationType_SYNCED, pb.SyncNotificationType_UNSYNCED:
+		walletSyncStatus.Synced = n.synced
+	case pb.SyncNotificationType_PEER_CONNECTED, pb.SyncNotificationType_PEER_DISCONNECTED:
+		if n.peers != nil {
+			walletSyncStatus.PeerCount = n.peers.PeerCount
+		}
+	case pb.SyncNotificationType_RESCAN_PROGRESS:
+		if n.rescan != nil {
+			walletSyncStatus.RescannedThrough = n.rescan.RescannedThrough
+		}
+	}
+}
+
+// finishWalletSync records the end of a sync
+func finishWalletSync(runCtx context.Context, err error) {
+	walletLoaderMutex.Lock()
+	defer walletLoaderMutex.Unlock()
+
+	walletSyncStatus.Running = false
+	walletSyncStatus.Synced = false
+	walletSyncStatus.LastError = ""
+	if runCtx.Err() == nil && err != nil {
+		walletSyncStatus.LastError = err.Error()
+		log.Printf("Warning: Wallet sync stopped: %v", err)
+	} else {
+		log.Println("Wallet sync stopped")
+	}
+	if walletSyncCancel != nil {
+		walletSyncCancel()
+		walletSyncCancel = nil
+	}
+}
+
+// fetchWalletSyncStatus returns the state of the sync started by this backend.
+// A sync dcrwallet runs from its own configuration is not visible here.
+func fetchWalletSyncStatus() types.WalletSyncStatus {
+	walletLoaderMutex.Lock()
+	defer walletLoaderMutex.Unlock()
+
+	s := walletSyncStatus
+	if s.Mode == "" {
+		s.Mode = walletLoaderConfig.SyncMode
+	}
+	return s
+}
+
+// checkNoWallet fails when a wallet already exists
+func checkNoWallet(ctx context.Context) error {
+	if rpc.WalletLoaderClient == nil || rpc.SeedClient == nil {
+		return fmt.Errorf("wallet gRPC client not initialized")
+	}
+	resp, err := rpc.WalletLoaderClient.WalletExists(ctx, &pb.WalletExistsRequest{})
+	if err != nil {
+		return fmt.Errorf("failed to check for a wallet: %w", err)
+	}
+	if resp.Exists {
+		return ErrWalletExists
+	}
+	return nil
+}
+
+// walletLoaderError maps WalletLoaderService errors
+func walletLoaderError(action string, err error) error {
+	s := status.Convert(err)
+	switch s.Code() {
+	case codes.AlreadyExists:
+		return ErrWalletExists
+	case codes.NotFound:
+		return ErrWalletNotFound
+	case codes.InvalidArgument:
+		if strings.Contains(strings.ToLower(s.Message()), "passphrase") {
+			return ErrIncorrectPassphrase
+		}
+		return fmt.Errorf("%w: %s", ErrInvalidWalletRequest, s.Message())
+	case codes.FailedPrecondition:
+		if strings.Contains(s.Message(), "synchroniz") {
+			return ErrWalletSyncing
+		}
+	}
+	return fmt.Errorf("failed to %s: %w", action, err)
+}
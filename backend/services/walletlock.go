@@ -0,0 +1,223 @@
+// Copyright (c) 2015-2025 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+
+	"github.com/decred/dcrd/dcrjson/v4"
+
+	"decred-pulse-backend/rpc"
+	"decred-pulse-backend/types"
+)
+
+// ErrIncorrectPassphrase is returned when dcrwallet rejects a passphrase
+var ErrIncorrectPassphrase = errors.New("incorrect passphrase")
+
+// Last lock state and sync mode reported by walletinfo, used while the wallet
+// is too busy to answer (e.g. during a rescan)
+var (
+	walletLockMutex    sync.Mutex
+	lastWalletUnlocked bool
+	lastWalletSyncMode string // Empty until walletinfo first answers
+)
+
+// walletInfo holds the walletinfo fields used by the dashboard
+type walletInfo struct {
+	DaemonConnected bool   `json:"daemonconnected"`
+	SPV             bool   `json:"spv"`
+	Unlocked        bool   `json:"unlocked"`
+	CoinType        uint32 `json:"cointype"`
+	Voting          bool   `json:"voting"`
+}
+
+// fetchWalletInfo calls walletinfo and records the reported lock state and
+// sync mode
+func fetchWalletInfo(ctx context.Context) (*walletInfo, error) {
+	result, err := rpc.WalletClient.RawRequest(ctx, "walletinfo", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get wallet info: %w", err)
+	}
+
+	var info walletInfo
+	if err := json.Unmarshal(result, &info); err != nil {
+		return nil, fmt.Errorf("failed to parse wallet info: %w", err)
+	}
+
+	walletLockMutex.Lock()
+	lastWalletUnlocked = info.Unlocked
+	lastWalletSyncMode = syncModeName(info.SPV)
+	walletLockMutex.Unlock()
+
+	return &info, nil
+}
+
+// lastKnownWalletUnlocked returns the lock state from the latest walletinfo call
+func lastKnownWalletUnlocked() bool {
+	walletLockMutex.Lock()
+	defer walletLockMutex.Unlock()
+	return lastWalletUnlocked
+}
+
+// UnlockWallet unlocks the wallet with walletpassphrase for timeout seconds.
+// A timeout of 0 keeps the wallet unlocked until it is locked explicitly.
+func UnlockWallet(ctx context.Context, passphrase string, timeout int64) error {
+	params, err := marshalParams(passphrase, timeout)
+	if err != nil {
+		return err
+	}
+
+	if _, err := rpc.WalletClient.RawRequest(ctx, "walletpassphrase", params); err != nil {
+		return passphraseError("unlock wallet", err)
+	}
+
+	log.Printf("Wallet unlocked (timeout: %ds)", timeout)
+	_, _ = fetchWalletInfo(ctx)
+	return nil
+}
+
+// LockWallet locks the wallet with walletlock
+func LockWallet(ctx context.Context) error {
+	if _, err := rpc.WalletClient.RawRequest(ctx, "walletlock", nil); err != nil {
+		return fmt.Errorf("failed to lock wallet: %w", err)
+	}
+
+	log.Println("Wallet locked")
+	_, _ = fetchWalletInfo(ctx)
+	return nil
+}
+
+// UnlockAccount unlocks an individually encrypted account with unlockaccount
+func UnlockAccount(ctx context.Context, account, passphrase string) error {
+	params, err := marshalParams(account, passphrase)
+	if err != nil {
+		return err
+	}
+
+	if _, err := rpc.WalletClient.RawRequest(ctx, "unlockaccount", params); err != nil {
+		return passphraseError("unlock account "+account, err)
+	}
+
+	log.Printf("Account %q unlocked", account)
+	return nil
+}
+
+// LockAccount locks an individually encrypted account with lockaccount
+func LockAccount(ctx context.Context, account string) error {
+	params, err := marshalParams(account)
+	if err != nil {
+		return err
+	}
+
+	if _, err := rpc.WalletClient.RawRequest(ctx, "lockaccount", params); err != nil {
+		return fmt.Errorf("failed to lock account %s: %w", account, err)
+	}
+
+	log.Printf("Account %q locked", account)
+	return nil
+}
+
+// FetchWalletLockStatus returns the wallet lock state and the encryption and
+// lock state of every account
+func FetchWalletLockStatus(ctx context.Context) (*types.WalletLockStatus, error) {
+	info, err := fetchWalletInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := rpc.WalletClient.RawRequest(ctx, "listaccounts", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list accounts: %w", err)
+	}
+
+	var balances map[string]float64
+	if err := json.Unmarshal(result, &balances); err != nil {
+		return nil, fmt.Errorf("failed to parse accounts: %w", err)
+	}
+
+	status := &types.WalletLockStatus{
+		Unlocked: info.Unlocked,
+		Voting:   info.Voting,
+		Accounts: make([]types.AccountLockState, 0, len(balances)),
+	}
+
+	for name := range balances {
+		state, err := fetchAccountLockState(ctx, name)
+		if err != nil {
+			log.Printf("Warning: Could not get lock state for account %s: %v", name, err)
+			continue
+		}
+		status.Accounts = append(status.Accounts, *state)
+	}
+
+	// listaccounts is a map, keep "default" first and the rest by name
+	sort.Slice(status.Accounts, func(i, j int) bool {
+		a, b := status.Accounts[i].AccountName, status.Accounts[j].AccountName
+		if a == "default" || b == "default" {
+			return a == "default" && b != "default"
+		}
+		return a < b
+	})
+	return status, nil
+}
+
+// fetchAccountLockState calls accountunlocked for a single account
+func fetchAccountLockState(ctx context.Context, account string) (*types.AccountLockState, error) {
+	params, err := marshalParams(account)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := rpc.WalletClient.RawRequest(ctx, "accountunlocked", params)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Encrypted bool  `json:"encrypted"`
+		Unlocked  *bool `json:"unlocked"`
+	}
+	if err := json.Unmarshal(result, &resp); err != nil {
+		return nil, err
+	}
+
+	state := &types.AccountLockState{
+		AccountName: account,
+		Encrypted:   resp.Encrypted,
+	}
+	if resp.Unlocked != nil {
+		state.Unlocked = *resp.Unlocked
+	}
+	return state, nil
+}
+
+// marshalParams encodes JSON-RPC positional parameters, escaping strings safely
+func marshalParams(values ...interface{}) ([]json.RawMessage, error) {
+	params := make([]json.RawMessage, 0, len(values))
+	for _, v := range values {
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode parameter: %w", err)
+		}
+		params = append(params, b)
+	}
+	return params, nil
+}
+
+// passphraseError maps dcrwallet's incorrect passphrase error to
+// ErrIncorrectPassphrase so handlers can report it without echoing details
+func passphraseError(action string, err error) error {
+	var rpcErr *dcrjson.RPCError
+	if errors.As(err, &rpcErr) && rpcErr.Code == dcrjson.ErrRPCWalletPassphraseIncorrect {
+		return ErrIncorrectPassphrase
+	}
+	return fmt.Errorf("failed to %s: %w", action, err)
+}
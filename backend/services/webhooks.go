@@ -0,0 +1,139 @@
+// Copyright (c) 2015-2025 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package services
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// webhookAttempts is the number of delivery attempts per event and endpoint
+const webhookAttempts = 3
+
+// WebhookConfig configures outgoing event delivery
+type WebhookConfig struct {
+	URLs   []string // Endpoints receiving a POST per event
+	Secret string   // Optional HMAC-SHA256 key for the X-Pulse-Signature header
+	Events []string // Event name prefixes to deliver, empty for all
+}
+
+// webhookPayload is the JSON body posted to webhook endpoints
+type webhookPayload struct {
+	Event     string      `json:"event"`
+	Timestamp time.Time   `json:"timestamp"`
+	Data      interface{} `json:"data"`
+}
+
+var (
+	webhookMutex  sync.RWMutex
+	webhookConfig WebhookConfig
+	webhookClient = &http.Client{Timeout: 10 * time.Second}
+)
+
+// InitWebhooks sets the webhook endpoints that receive backend events
+func InitWebhooks(config WebhookConfig) {
+	webhookMutex.Lock()
+	webhookConfig = config
+	webhookMutex.Unlock()
+
+	if len(config.URLs) > 0 {
+		log.Printf("Webhooks enabled for %d endpoint(s)", len(config.URLs))
+	}
+}
+
+// DispatchWebhook delivers an event to every configured endpoint in the
+// background. Delivery failures are logged and never block the caller.
+func DispatchWebhook(event string, data interface{}) {
+	webhookMutex.RLock()
+	config := webhookConfig
+	webhookMutex.RUnlock()
+
+	if len(config.URLs) == 0 || !webhookEventEnabled(config.Events, event) {
+		return
+	}
+
+	body, err := json.Marshal(webhookPayload{
+		Event:     event,
+		Timestamp: time.Now(),
+		Data:      data,
+	})
+	if err != nil {
+		log.Printf("Warning: Failed to encode webhook event %s: %v", event, err)
+		return
+	}
+
+	signature := ""
+	if config.Secret != "" {
+		mac := hmac.New(sha256.New, []byte(config.Secret))
+		mac.Write(body)
+		signature = hex.EncodeToString(mac.Sum(nil))
+	}
+
+	for _, url := range config.URLs {
+		go deliverWebhook(url, event, body, signature)
+	}
+}
+
+// webhookEventEnabled reports whether event matches one of the configured prefixes
+func webhookEventEnabled(filters []string, event string) bool {
+	if len(filters) == 0 {
+		return true
+	}
+	for _, prefix := range filters {
+		if strings.HasPrefix(event, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// deliverWebhook posts body to url, retrying with backoff on failure
+func deliverWebhook(url, event string, body []byte, signature string) {
+	var lastErr error
+	for attempt := 1; attempt <= webhookAttempts; attempt++ {
+		if attempt > 1 {
+			time.Sleep(time.Duration(attempt*attempt) * time.Second)
+		}
+
+		lastErr = postWebhook(url, event, body, signature)
+		if lastErr == nil {
+			return
+		}
+	}
+	log.Printf("Warning: Webhook delivery of %s to %s failed after %d attempts: %v",
+		event, url, webhookAttempts, lastErr)
+}
+
+func postWebhook(url, event string, body []byte, signature string) error {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Pulse-Event", event)
+	if signature != "" {
+		req.Header.Set("X-Pulse-Signature", "sha256="+signature)
+	}
+
+	resp, err := webhookClient.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
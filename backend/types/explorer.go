@@ -114,3 +114,18 @@ type PaginatedBlocksResponse struct {
 	TotalBlocks int64          `json:"totalBlocks"`
 	TotalPages  int            `json:"totalPages"`
 }
+
+// VerifyMessageRequest is a signed message to check against an address
+type VerifyMessageRequest struct {
+	Address   string `json:"address"`
+	Message   string `json:"message"`
+	Signature string `json:"signature"` // Base64 compact signature
+}
+
+// VerifyMessageResponse reports whether a signature matches an address
+type VerifyMessageResponse struct {
+	Valid   bool   `json:"valid"`
+	Address string `json:"address"`
+	Message string `json:"message"`
+	Network string `json:"network"`
+}
@@ -0,0 +1,89 @@
+// Copyright (c) 2015-2025 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package types
+
+import "time"
+
+// AgendaChoice is a vote choice of a consensus agenda with its tally in the
+// current rule change interval
+type AgendaChoice struct {
+	ID          string  `json:"id"`
+	Description string  `json:"description"`
+	Bits        uint16  `json:"bits"`
+	IsAbstain   bool    `json:"isAbstain"`
+	IsNo        bool    `json:"isNo"`
+	Count       uint32  `json:"count"`
+	Progress    float64 `json:"progress"` // Share of the interval's votes, 0-100%
+}
+
+// ConsensusAgenda is a consensus rule change voted on by stakeholders
+type ConsensusAgenda struct {
+	ID                string         `json:"id"`
+	Description       string         `json:"description"`
+	Status            string         `json:"status"` // "defined", "started", "lockedin", "active", "failed"
+	Mask              uint16         `json:"mask"`
+	StartTime         time.Time      `json:"startTime"`
+	ExpireTime        time.Time      `json:"expireTime"`
+	Choices           []AgendaChoice `json:"choices"`
+	YesVotes          uint32         `json:"yesVotes"`
+	NoVotes           uint32         `json:"noVotes"`
+	AbstainVotes      uint32         `json:"abstainVotes"`
+	QuorumProgress    float64        `json:"quorumProgress"` // Non-abstain votes against the quorum, 0-100%
+	QuorumMet         bool           `json:"quorumMet"`
+	Approval          float64        `json:"approval"`               // Yes share of non-abstain votes, 0-100%
+	ApprovalThreshold float64        `json:"approvalThreshold"`      // Yes share needed to lock in
+	WalletChoice      string         `json:"walletChoice,omitempty"` // The wallet's default choice
+}
+
+// StakeVersionProgress is the share of votes cast with the agendas' vote
+// version. Voting only starts once enough voters have upgraded.
+type StakeVersionProgress struct {
+	Version          uint32  `json:"version"`
+	IntervalStart    int64   `json:"intervalStart"`
+	IntervalEnd      int64   `json:"intervalEnd"`
+	UpgradedVotes    uint32  `json:"upgradedVotes"`
+	TotalVotes       uint32  `json:"totalVotes"`
+	Progress         float64 `json:"progress"`  // 0-100%
+	Threshold        float64 `json:"threshold"` // Share needed, 0-100%
+	ThresholdReached bool    `json:"thresholdReached"`
+}
+
+// AgendasResponse is the consensus agenda voting dashboard
+type AgendasResponse struct {
+	VoteVersion         uint32                `json:"voteVersion"`
+	CurrentHeight       int64                 `json:"currentHeight"`
+	IntervalStartHeight int64                 `json:"intervalStartHeight"` // Current rule change interval
+	IntervalEndHeight   int64                 `json:"intervalEndHeight"`
+	BlocksRemaining     int64                 `json:"blocksRemaining"`
+	Quorum              uint32                `json:"quorum"`
+	TotalVotes          uint32                `json:"totalVotes"`
+	Agendas             []ConsensusAgenda     `json:"agendas"`
+	StakeVersion        *StakeVersionProgress `json:"stakeVersion,omitempty"`
+	VoteReward          float64               `json:"voteReward,omitempty"` // DCR earned per vote at the current height
+}
+
+// WalletVoteChoice is the wallet's choice for an agenda
+type WalletVoteChoice struct {
+	AgendaID          string `json:"agendaId"`
+	AgendaDescription string `json:"agendaDescription"`
+	ChoiceID          string `json:"choiceId"`
+	ChoiceDescription string `json:"choiceDescription"`
+}
+
+// WalletVoteChoicesResponse lists the wallet's vote choices, either the
+// defaults or those of one ticket
+type WalletVoteChoicesResponse struct {
+	Version    uint32             `json:"version"`
+	TicketHash string             `json:"ticketHash,omitempty"` // Set for a ticket's choices
+	Choices    []WalletVoteChoice `json:"choices"`
+}
+
+// SetVoteChoiceRequest sets the wallet's choice for an agenda, for all
+// tickets or only one
+type SetVoteChoiceRequest struct {
+	AgendaID   string `json:"agendaId"`
+	ChoiceID   string `json:"choiceId"`
+	TicketHash string `json:"ticketHash,omitempty"` // Per-ticket override if set
+}
@@ -0,0 +1,57 @@
+// Copyright (c) 2015-2025 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package types
+
+import "time"
+
+// Invoice status values
+const (
+	InvoiceStatusPending   = "pending"   // Nothing received yet
+	InvoiceStatusSeen      = "seen"      // Payment in the mempool or below the required confirmations
+	InvoiceStatusConfirmed = "confirmed" // Exact amount received with the required confirmations
+	InvoiceStatusOverpaid  = "overpaid"  // More than the amount received with the required confirmations
+	InvoiceStatusUnderpaid = "underpaid" // Less than the amount received with the required confirmations
+	InvoiceStatusExpired   = "expired"   // Nothing received before the expiry
+)
+
+// Invoice is a payment request tracked against a dedicated wallet address
+type Invoice struct {
+	ID                    string     `json:"id"`
+	Status                string     `json:"status"`
+	Final                 bool       `json:"final"` // No longer tracked
+	Address               string     `json:"address"`
+	Account               string     `json:"account"`
+	URI                   string     `json:"uri"`
+	Amount                float64    `json:"amount"`
+	Memo                  string     `json:"memo,omitempty"`
+	Received              float64    `json:"received"`          // Including unconfirmed payments
+	ConfirmedReceived     float64    `json:"confirmedReceived"` // With the required confirmations
+	Confirmations         int64      `json:"confirmations"`     // Of the latest payment
+	RequiredConfirmations int64      `json:"requiredConfirmations"`
+	CreatedAt             time.Time  `json:"createdAt"`
+	ExpiresAt             time.Time  `json:"expiresAt"`
+	UpdatedAt             time.Time  `json:"updatedAt"`
+	PaidAt                *time.Time `json:"paidAt,omitempty"` // When the first payment was seen
+	Fiat                  *FiatValue `json:"fiat,omitempty"`   // Fiat equivalent of Amount at creation
+}
+
+// InvoiceCreateRequest creates an invoice. Expiry is in seconds.
+type InvoiceCreateRequest struct {
+	Amount                float64 `json:"amount"`
+	Memo                  string  `json:"memo,omitempty"`
+	Account               string  `json:"account,omitempty"`
+	Expiry                int64   `json:"expiry,omitempty"`
+	RequiredConfirmations int64   `json:"requiredConfirmations,omitempty"`
+}
+
+// InvoiceListResponse is a filtered, paginated page of invoices, newest first
+type InvoiceListResponse struct {
+	Invoices    []Invoice      `json:"invoices"`
+	StatusCount map[string]int `json:"statusCount"`
+	Total       int            `json:"total"`
+	CurrentPage int            `json:"currentPage"`
+	PageSize    int            `json:"pageSize"`
+	TotalPages  int            `json:"totalPages"`
+}
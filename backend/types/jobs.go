@@ -0,0 +1,72 @@
+// Copyright (c) 2015-2025 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package types
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Job status values
+const (
+	JobStatusQueued      = "queued"
+	JobStatusRunning     = "running"
+	JobStatusSucceeded   = "succeeded"
+	JobStatusFailed      = "failed"
+	JobStatusCancelled   = "cancelled"
+	JobStatusInterrupted = "interrupted" // Backend restarted while the job was running
+)
+
+// Job step status values
+const (
+	JobStepPending   = "pending"
+	JobStepRunning   = "running"
+	JobStepCompleted = "completed"
+	JobStepFailed    = "failed"
+	JobStepSkipped   = "skipped"
+)
+
+// Job represents a tracked long-running background operation
+type Job struct {
+	ID          string          `json:"id"`
+	Type        string          `json:"type"`   // "xpub_import", "wallet_rescan", "tspend_scan", ...
+	Status      string          `json:"status"` // "queued", "running", "succeeded", "failed", "cancelled", "interrupted"
+	Steps       []JobStep       `json:"steps"`
+	CurrentStep int             `json:"currentStep"` // Index into Steps, -1 before the first step starts
+	Progress    float64         `json:"progress"`    // 0-100% within the current step
+	Message     string          `json:"message"`
+	Logs        []JobLogEntry   `json:"logs"`
+	Result      json.RawMessage `json:"result,omitempty"`
+	Error       string          `json:"error,omitempty"`
+	CreatedAt   time.Time       `json:"createdAt"`
+	StartedAt   *time.Time      `json:"startedAt,omitempty"`
+	FinishedAt  *time.Time      `json:"finishedAt,omitempty"`
+}
+
+// JobStep is a single named phase of a job
+type JobStep struct {
+	Name       string     `json:"name"`
+	Status     string     `json:"status"` // "pending", "running", "completed", "failed", "skipped"
+	StartedAt  *time.Time `json:"startedAt,omitempty"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+}
+
+// JobLogEntry is a single log line recorded by a job
+type JobLogEntry struct {
+	Time    time.Time `json:"time"`
+	Message string    `json:"message"`
+}
+
+// JobEvent is sent over the jobs WebSocket feed whenever a job changes
+type JobEvent struct {
+	Event string `json:"event"` // "created", "updated", "finished"
+	Job   Job    `json:"job"`
+}
+
+// JobListResponse is returned by the jobs list endpoint
+type JobListResponse struct {
+	Jobs  []Job `json:"jobs"`
+	Total int   `json:"total"`
+}
@@ -65,6 +65,10 @@ type SupplyInfo struct {
 	ExchangeRate      string  `json:"exchangeRate"`
 	TreasurySize      string  `json:"treasurySize"`
 	MixedPercent      string  `json:"mixedPercent"`
+	// Rate carries the exchange rate with its source and age (if available)
+	Rate *ExchangeRate `json:"rate,omitempty"`
+	// Mixing holds the chain mix statistics once a scan has run
+	Mixing *MixStats `json:"mixing,omitempty"`
 }
 
 type StakingInfo struct {
@@ -106,3 +110,22 @@ type RPCConnectionResponse struct {
 	Success bool   `json:"success"`
 	Message string `json:"message"`
 }
+
+// MixDay is the StakeShuffle activity of one UTC day
+type MixDay struct {
+	Date         string  `json:"date"` // YYYY-MM-DD
+	Transactions int     `json:"transactions"`
+	MixedOutputs int     `json:"mixedOutputs"`
+	Volume       float64 `json:"volume"` // DCR in newly mixed outputs
+}
+
+// MixStats summarizes StakeShuffle mixing on chain, from the mix statistics scan
+type MixStats struct {
+	MixedSupply   float64   `json:"mixedSupply"` // DCR held in unspent mixed outputs
+	MixedPercent  float64   `json:"mixedPercent"`
+	MixedUTXOs    int       `json:"mixedUtxos"`
+	ScannedHeight int64     `json:"scannedHeight"`
+	Scanning      bool      `json:"scanning"`
+	Daily         []MixDay  `json:"daily"` // Most recent days, newest first
+	LastUpdate    time.Time `json:"lastUpdate"`
+}
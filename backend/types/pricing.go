@@ -0,0 +1,27 @@
+// Copyright (c) 2015-2025 The Decred developers
+// Use of this source code is governed by an ISC
+// license that can be found in the LICENSE file.
+
+package types
+
+import "time"
+
+// ExchangeRate is the cached DCR price in a fiat currency
+type ExchangeRate struct {
+	Currency   string    `json:"currency"`
+	Rate       float64   `json:"rate"` // Fiat per DCR
+	Source     string    `json:"source"`
+	FetchedAt  time.Time `json:"fetchedAt"`
+	AgeSeconds int64     `json:"ageSeconds"`
+	Stale      bool      `json:"stale"` // Older than the configured maximum age
+	LastError  string    `json:"lastError,omitempty"`
+}
+
+// FiatValue is a DCR amount converted to fiat
+type FiatValue struct {
+	Currency   string  `json:"currency"`
+	Value      float64 `json:"value"`
+	Rate       float64 `json:"rate"`
+	Historical bool    `json:"historical"` // Rate of the transaction day rather than the current rate
+	Stale      bool    `json:"stale,omitempty"`
+}
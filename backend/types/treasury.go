@@ -8,12 +8,13 @@ import "time"
 
 // TreasuryInfo represents the complete treasury status
 type TreasuryInfo struct {
-	Balance       float64         `json:"balance"`       // Current treasury balance in DCR
-	BalanceUSD    float64         `json:"balanceUsd"`    // USD equivalent (if available)
-	TotalAdded    float64         `json:"totalAdded"`    // Lifetime treasury additions
-	TotalSpent    float64         `json:"totalSpent"`    // Lifetime treasury expenditures
-	ActiveTSpends []TSpend        `json:"activeTSpends"` // TSpends currently in mempool
-	RecentTSpends []TSpendHistory `json:"recentTSpends"` // Recently approved TSpends
+	Balance       float64         `json:"balance"`               // Current treasury balance in DCR
+	BalanceUSD    float64         `json:"balanceUsd"`            // USD equivalent (if available)
+	BalanceFiat   *FiatValue      `json:"balanceFiat,omitempty"` // Equivalent in the configured currency
+	TotalAdded    float64         `json:"totalAdded"`            // Lifetime treasury additions
+	TotalSpent    float64         `json:"totalSpent"`            // Lifetime treasury expenditures
+	ActiveTSpends []TSpend        `json:"activeTSpends"`         // TSpends currently in mempool
+	RecentTSpends []TSpendHistory `json:"recentTSpends"`         // Recently approved TSpends
 	LastUpdate    time.Time       `json:"lastUpdate"`
 }
 
@@ -27,6 +28,8 @@ type TSpend struct {
 	BlocksRemaining int64     `json:"blocksRemaining"` // Blocks until expiry
 	Status          string    `json:"status"`          // "voting", "approved", "rejected"
 	DetectedAt      time.Time `json:"detectedAt"`
+	PiKey           string    `json:"piKey,omitempty"`      // Politeia key that signed the spend
+	WalletVote      string    `json:"walletVote,omitempty"` // Our wallet's stance: "yes", "no" or "abstain"
 }
 
 // TSpendHistory represents a historical approved treasury spend
@@ -50,3 +53,41 @@ type TSpendScanProgress struct {
 	NewTSpends    []TSpendHistory `json:"newTSpends"`  // TSpends found since last progress check
 	Message       string          `json:"message"`
 }
+
+// TreasuryKeyPolicy is the wallet's vote on treasury spends signed by a Pi key
+type TreasuryKeyPolicy struct {
+	Key    string `json:"key"`
+	Policy string `json:"policy"`           // "yes", "no" or "abstain"
+	Ticket string `json:"ticket,omitempty"` // Set for a per-ticket policy
+}
+
+// TSpendPolicy is the wallet's vote on one treasury spend
+type TSpendPolicy struct {
+	Hash   string `json:"hash"`
+	Policy string `json:"policy"`           // "yes", "no" or "abstain"
+	Ticket string `json:"ticket,omitempty"` // Set for a per-ticket policy
+}
+
+// TreasuryPolicyRequest sets the wallet's vote for a Pi key
+type TreasuryPolicyRequest struct {
+	Key    string `json:"key"`
+	Policy string `json:"policy"`
+	Ticket string `json:"ticket,omitempty"`
+}
+
+// TSpendPolicyRequest sets the wallet's vote for a treasury spend
+type TSpendPolicyRequest struct {
+	Hash   string `json:"hash"`
+	Policy string `json:"policy"`
+	Ticket string `json:"ticket,omitempty"`
+}
+
+// TreasuryPoliciesResponse lists the wallet's Pi key policies
+type TreasuryPoliciesResponse struct {
+	Policies []TreasuryKeyPolicy `json:"policies"`
+}
+
+// TSpendPoliciesResponse lists the wallet's treasury spend policies
+type TSpendPoliciesResponse struct {
+	Policies []TSpendPolicy `json:"policies"`
+}
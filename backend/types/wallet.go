@@ -12,18 +12,50 @@ type WalletDashboardData struct {
 	AccountInfo  AccountInfo        `json:"accountInfo"`
 	Accounts     []AccountInfo      `json:"accounts"`
 	StakingInfo  *WalletStakingInfo `json:"stakingInfo,omitempty"`
+	ExchangeRate *ExchangeRate      `json:"exchangeRate,omitempty"`
 	LastUpdate   time.Time          `json:"lastUpdate"`
 }
 
 type WalletStatus struct {
-	Status           string  `json:"status"` // "locked", "unlocked", "syncing", "synced", "no_wallet"
-	SyncProgress     float64 `json:"syncProgress"`
-	SyncHeight       int64   `json:"syncHeight"`
-	BestBlockHash    string  `json:"bestBlockHash"`
-	Version          string  `json:"version"`
-	Unlocked         bool    `json:"unlocked"`
-	RescanInProgress bool    `json:"rescanInProgress"`
-	SyncMessage      string  `json:"syncMessage"`
+	Status           string        `json:"status"` // "locked", "unlocked", "syncing", "synced", "no_wallet"
+	SyncProgress     float64       `json:"syncProgress"`
+	SyncHeight       int64         `json:"syncHeight"`
+	BestBlockHash    string        `json:"bestBlockHash"`
+	Version          string        `json:"version"`
+	Unlocked         bool          `json:"unlocked"`
+	RescanInProgress bool          `json:"rescanInProgress"`
+	SyncMessage      string        `json:"syncMessage"`
+	Rescan           *RescanStatus `json:"rescan,omitempty"`      // Present while a rescan is active
+	SyncMode         string        `json:"syncMode,omitempty"`    // "spv" or "rpc"
+	ChainHeight      int64         `json:"chainHeight,omitempty"` // Network tip as known to the wallet
+	PeerCount        int           `json:"peerCount,omitempty"`   // SPV peers
+}
+
+// WalletUnlockRequest unlocks the wallet for Timeout seconds (0 = until
+// locked). Passphrases in this and every other request are never logged.
+type WalletUnlockRequest struct {
+	Passphrase string `json:"passphrase"`
+	Timeout    int64  `json:"timeout"`
+}
+
+// AccountUnlockRequest unlocks an individually encrypted account
+type AccountUnlockRequest struct {
+	Passphrase string `json:"passphrase"`
+}
+
+// WalletLockStatus reports the wallet and per-account lock state
+type WalletLockStatus struct {
+	Unlocked bool               `json:"unlocked"`
+	Voting   bool               `json:"voting"` // Wallet is configured to vote
+	Accounts []AccountLockState `json:"accounts"`
+}
+
+// AccountLockState is the encryption state of a single account.
+// Unlocked is only meaningful for individually encrypted accounts.
+type AccountLockState struct {
+	AccountName string `json:"accountName"`
+	Encrypted   bool   `json:"encrypted"`
+	Unlocked    bool   `json:"unlocked"`
 }
 
 type AccountInfo struct {
@@ -41,23 +73,26 @@ type AccountInfo struct {
 	CumulativeTotal      float64 `json:"cumulativeTotal,omitempty"`
 	TotalSpendable       float64 `json:"totalSpendable,omitempty"`
 	TotalLockedByTickets float64 `json:"totalLockedByTickets,omitempty"`
+	// Fiat equivalent of TotalBalance (if an exchange rate is available)
+	TotalBalanceFiat *FiatValue `json:"totalBalanceFiat,omitempty"`
 }
 
 type Transaction struct {
-	TxID          string    `json:"txid"`
-	Amount        float64   `json:"amount"`
-	Fee           float64   `json:"fee,omitempty"`
-	Confirmations int64     `json:"confirmations"`
-	BlockHash     string    `json:"blockHash,omitempty"`
-	BlockTime     int64     `json:"blockTime,omitempty"`
-	Time          time.Time `json:"time"`
-	Category      string    `json:"category"` // "send", "receive", "immature", "generate"
-	TxType        string    `json:"txType"`   // "regular", "ticket", "vote", "revocation"
-	Address       string    `json:"address,omitempty"`
-	Account       string    `json:"account,omitempty"`
-	Vout          uint32    `json:"vout"`
-	Generated     bool      `json:"generated,omitempty"`
-	IsMixed       bool      `json:"isMixed,omitempty"` // true if from CoinJoin/StakeShuffle
+	TxID          string     `json:"txid"`
+	Amount        float64    `json:"amount"`
+	Fee           float64    `json:"fee,omitempty"`
+	Confirmations int64      `json:"confirmations"`
+	BlockHash     string     `json:"blockHash,omitempty"`
+	BlockTime     int64      `json:"blockTime,omitempty"`
+	Time          time.Time  `json:"time"`
+	Category      string     `json:"category"` // "send", "receive", "immature", "generate"
+	TxType        string     `json:"txType"`   // "regular", "ticket", "vote", "revocation"
+	Address       string     `json:"address,omitempty"`
+	Account       string     `json:"account,omitempty"`
+	Vout          uint32     `json:"vout"`
+	Generated     bool       `json:"generated,omitempty"`
+	IsMixed       bool       `json:"isMixed,omitempty"` // true if from CoinJoin/StakeShuffle
+	Fiat          *FiatValue `json:"fiat,omitempty"`    // Fiat equivalent of Amount
 }
 
 type TransactionListResponse struct {
@@ -65,11 +100,39 @@ type TransactionListResponse struct {
 	Total        int           `json:"total"`
 }
 
+// Address is a wallet receive or change address
 type Address struct {
-	Address string `json:"address"`
-	Account string `json:"account"`
-	Used    bool   `json:"used"`
-	Path    string `json:"path"` // BIP44 path
+	Address  string  `json:"address"`
+	Account  string  `json:"account"`
+	Branch   uint32  `json:"branch"` // 0 external (receive), 1 internal (change)
+	Index    uint32  `json:"index"`
+	Used     bool    `json:"used"`
+	Received float64 `json:"received"` // Total received, including unconfirmed
+	TxCount  int     `json:"txCount"`
+	Path     string  `json:"path"` // BIP44 path, relative to the xpub for imported xpub accounts
+	URI      string  `json:"uri"`  // decred: payment URI
+}
+
+// NewAddressRequest derives the next address of an account branch. GapPolicy
+// ("error", "ignore" or "wrap") applies to receive addresses only.
+type NewAddressRequest struct {
+	Account   string  `json:"account"`
+	Branch    uint32  `json:"branch"`
+	GapPolicy string  `json:"gapPolicy,omitempty"`
+	Amount    float64 `json:"amount,omitempty"` // Requested amount for the payment URI
+	Label     string  `json:"label,omitempty"`  // Label for the payment URI
+}
+
+// AddressListResponse is a filtered, paginated page of account addresses
+type AddressListResponse struct {
+	Account       string    `json:"account"`
+	Addresses     []Address `json:"addresses"`
+	ExternalIndex uint32    `json:"externalIndex"` // Next receive address index
+	InternalIndex uint32    `json:"internalIndex"` // Next change address index
+	Total         int       `json:"total"`
+	CurrentPage   int       `json:"currentPage"`
+	PageSize      int       `json:"pageSize"`
+	TotalPages    int       `json:"totalPages"`
 }
 
 type ImportXpubRequest struct {
@@ -82,15 +145,38 @@ type ImportXpubResponse struct {
 	Success    bool   `json:"success"`
 	Message    string `json:"message"`
 	AccountNum uint32 `json:"accountNum,omitempty"`
+	JobID      string `json:"jobId,omitempty"`
 }
 
 type RescanRequest struct {
 	BeginHeight int32 `json:"beginHeight"`
+	Queue       bool  `json:"queue"` // Queue behind an active rescan instead of refusing
 }
 
 type RescanResponse struct {
 	Success bool   `json:"success"`
 	Message string `json:"message"`
+	JobID   string `json:"jobId,omitempty"`
+}
+
+// RescanStatus is the state of the rescan controller, recorded from the
+// gRPC Rescan stream
+type RescanStatus struct {
+	State           string     `json:"state"` // "idle", "discovering", "rescanning", "completed", "failed", "cancelled"
+	IsRescanning    bool       `json:"isRescanning"`
+	JobID           string     `json:"jobId,omitempty"`
+	BeginHeight     int64      `json:"beginHeight"`
+	ScanHeight      int64      `json:"scanHeight"`  // Last RescannedThrough height
+	ChainHeight     int64      `json:"chainHeight"` // Height the rescan runs up to
+	Progress        float64    `json:"progress"`    // 0-100%
+	BlocksPerSecond float64    `json:"blocksPerSecond"`
+	ETASeconds      int64      `json:"etaSeconds"`
+	ETA             string     `json:"eta,omitempty"`
+	Queued          int        `json:"queued"` // Rescan requests waiting for the active one
+	StartedAt       *time.Time `json:"startedAt,omitempty"`
+	FinishedAt      *time.Time `json:"finishedAt,omitempty"`
+	Error           string     `json:"error,omitempty"`
+	Message         string     `json:"message"`
 }
 
 type SyncProgressResponse struct {
@@ -123,3 +209,594 @@ type WalletStakingInfo struct {
 	CurrentDifficulty float64 `json:"currentDifficulty"`
 	NextDifficulty    float64 `json:"nextDifficulty"`
 }
+
+// Wallet notification event types
+const (
+	WalletEventTransaction  = "transaction"  // New unmined or newly mined wallet transaction
+	WalletEventConfirmation = "confirmation" // Confirmation count change for a tracked transaction
+	WalletEventAccount      = "account"      // Account created, renamed or key counts changed
+	WalletEventDetached     = "detached"     // Block disconnected during a reorg
+)
+
+// WalletEvent is a real-time wallet notification derived from the dcrwallet
+// gRPC notification streams
+type WalletEvent struct {
+	Type        string               `json:"type"`
+	Time        time.Time            `json:"time"`
+	Transaction *Transaction         `json:"transaction,omitempty"`
+	Account     *AccountNotification `json:"account,omitempty"`
+	BlockHash   string               `json:"blockHash,omitempty"`
+	BlockHeight int32                `json:"blockHeight,omitempty"`
+}
+
+// AccountNotification describes an account change reported by dcrwallet
+type AccountNotification struct {
+	AccountNumber    uint32 `json:"accountNumber"`
+	AccountName      string `json:"accountName"`
+	ExternalKeyCount uint32 `json:"externalKeyCount"`
+	InternalKeyCount uint32 `json:"internalKeyCount"`
+	ImportedKeyCount uint32 `json:"importedKeyCount"`
+}
+
+// SendOutput is a single payment destination
+type SendOutput struct {
+	Address string  `json:"address"`
+	Amount  float64 `json:"amount"` // DCR
+}
+
+// SendPrepareRequest describes a payment to build but not yet sign
+type SendPrepareRequest struct {
+	Account string       `json:"account"` // Source account, "default" if empty
+	Outputs []SendOutput `json:"outputs"`
+	FeeRate float64      `json:"feeRate"`          // DCR/kB, 0 for the wallet default
+	MinConf int32        `json:"minConf"`          // Minimum confirmations of spent outputs
+	Inputs  []Outpoint   `json:"inputs,omitempty"` // Spend exactly these outputs instead of automatic coin selection
+}
+
+// Outpoint identifies a transaction output
+type Outpoint struct {
+	TxID string `json:"txid"`
+	Vout uint32 `json:"vout"`
+	Tree int8   `json:"tree"`
+}
+
+// SendInput is an output spent by a prepared transaction
+type SendInput struct {
+	TxID   string  `json:"txid"`
+	Vout   uint32  `json:"vout"`
+	Tree   int8    `json:"tree"`
+	Amount float64 `json:"amount"`
+}
+
+// SendPreviewOutput is an output created by a prepared transaction
+type SendPreviewOutput struct {
+	Address  string  `json:"address"`
+	Amount   float64 `json:"amount"`
+	IsChange bool    `json:"isChange"`
+}
+
+// SendPreview summarizes an unsigned transaction awaiting confirmation
+type SendPreview struct {
+	Token         string              `json:"token"`
+	ExpiresAt     time.Time           `json:"expiresAt"`
+	Network       string              `json:"network"`
+	Account       string              `json:"account"`
+	Inputs        []SendInput         `json:"inputs"`
+	Outputs       []SendPreviewOutput `json:"outputs"`
+	TotalInput    float64             `json:"totalInput"`
+	TotalSent     float64             `json:"totalSent"` // Excluding change
+	Fee           float64             `json:"fee"`
+	FeeRate       float64             `json:"feeRate"`       // DCR/kB at the estimated size
+	EstimatedSize uint32              `json:"estimatedSize"` // Signed size in bytes
+}
+
+// SendConfirmRequest signs and publishes a prepared transaction
+type SendConfirmRequest struct {
+	Token      string `json:"token"`
+	Passphrase string `json:"passphrase,omitempty"` // Not needed if the wallet is unlocked
+}
+
+// SendResult is the outcome of a published send
+type SendResult struct {
+	TxID      string  `json:"txid"`
+	TotalSent float64 `json:"totalSent"`
+	Fee       float64 `json:"fee"`
+}
+
+// TicketPurchaseRequest buys tickets through a VSP. Passphrase is only used
+// for the purchase itself and may be omitted if the wallet is unlocked.
+type TicketPurchaseRequest struct {
+	Account    string  `json:"account"` // Source account, "default" if empty
+	Count      uint32  `json:"count"`
+	Expiry     uint32  `json:"expiry"` // Blocks until unmined tickets expire, 0 for none
+	VSPHost    string  `json:"vspHost"`
+	VSPPubKey  string  `json:"vspPubkey"`
+	MaxPrice   float64 `json:"maxPrice,omitempty"` // Refuse to buy above this ticket price (DCR)
+	Passphrase string  `json:"passphrase,omitempty"`
+}
+
+// TicketPurchasePreview prices a purchase before it is made
+type TicketPurchasePreview struct {
+	Account          string   `json:"account"`
+	Count            uint32   `json:"count"`
+	TicketPrice      float64  `json:"ticketPrice"`     // Current stake difficulty
+	NextTicketPrice  float64  `json:"nextTicketPrice"` // Stake difficulty of the next window
+	Fees             float64  `json:"fees"`            // Estimated transaction and VSP fees
+	TotalCost        float64  `json:"totalCost"`       // Tickets plus estimated fees
+	SpendableBalance float64  `json:"spendableBalance"`
+	CanAfford        bool     `json:"canAfford"`
+	VSPHost          string   `json:"vspHost"`
+	VSPFeePercent    float64  `json:"vspFeePercent,omitempty"`
+	Warnings         []string `json:"warnings,omitempty"`
+}
+
+// TicketPurchaseResult lists the purchased tickets and their VSP fee state
+type TicketPurchaseResult struct {
+	Account      string            `json:"account"`
+	TicketHashes []string          `json:"ticketHashes"`
+	TicketPrice  float64           `json:"ticketPrice"`
+	Fees         float64           `json:"fees"`      // Estimated transaction and VSP fees
+	TotalCost    float64           `json:"totalCost"` // Tickets plus estimated fees
+	VSPHost      string            `json:"vspHost"`
+	VSPFeeStatus map[string]string `json:"vspFeeStatus"` // Ticket hash to "none", "started", "paid", "errored" or "confirmed"
+}
+
+// WalletTicket is a single ticket owned by the wallet
+type WalletTicket struct {
+	Hash           string   `json:"hash"`
+	Status         string   `json:"status"` // "unmined", "immature", "live", "voted", "expired", "missed", "revoked", "unknown"
+	Account        string   `json:"account"`
+	PurchaseHeight int32    `json:"purchaseHeight,omitempty"` // 0 while unmined
+	PurchaseTime   int64    `json:"purchaseTime"`
+	Price          float64  `json:"price"` // Ticket value locked in the ticket
+	Fee            float64  `json:"fee"`   // Ticket transaction fee
+	SpenderHash    string   `json:"spenderHash,omitempty"`
+	SpenderType    string   `json:"spenderType,omitempty"` // "vote" or "revocation"
+	SpenderTime    int64    `json:"spenderTime,omitempty"`
+	Returned       float64  `json:"returned,omitempty"` // Total paid out by the vote/revocation
+	Reward         float64  `json:"reward,omitempty"`   // Returned minus price and ticket fee
+	DaysToVote     *float64 `json:"daysToVote,omitempty"`
+	VSPHost        string   `json:"vspHost,omitempty"`
+	VSPFeeStatus   string   `json:"vspFeeStatus,omitempty"` // "none", "started", "paid", "errored", "confirmed"
+}
+
+// TicketListResponse is a filtered, paginated page of wallet tickets
+type TicketListResponse struct {
+	Tickets     []WalletTicket `json:"tickets"`
+	StatusCount map[string]int `json:"statusCount"` // Counts over all matching tickets before pagination
+	Total       int            `json:"total"`
+	CurrentPage int            `json:"currentPage"`
+	PageSize    int            `json:"pageSize"`
+	TotalPages  int            `json:"totalPages"`
+}
+
+// TicketReturn is the realized return of a voted or revoked ticket
+type TicketReturn struct {
+	Hash             string  `json:"hash"`
+	Account          string  `json:"account"`
+	Status           string  `json:"status"`
+	PurchaseTime     int64   `json:"purchaseTime"`
+	SpenderTime      int64   `json:"spenderTime"`
+	Price            float64 `json:"price"`
+	Fee              float64 `json:"fee"`
+	Returned         float64 `json:"returned"`
+	NetReward        float64 `json:"netReward"` // Returned minus price and ticket fee
+	DaysLocked       float64 `json:"daysLocked"`
+	AnnualizedReturn float64 `json:"annualizedReturn"` // Percent
+}
+
+// StakeReturnPeriod aggregates ticket returns by month ("2025-01") or account
+type StakeReturnPeriod struct {
+	Key            string  `json:"key"`
+	Tickets        int     `json:"tickets"`
+	Voted          int     `json:"voted"`
+	Revoked        int     `json:"revoked"`
+	TotalInvested  float64 `json:"totalInvested"`
+	TotalNetReward float64 `json:"totalNetReward"`
+	AvgDaysLocked  float64 `json:"avgDaysLocked"`
+	RealizedAPY    float64 `json:"realizedApy"` // Percent, weighted by amount and lock time
+}
+
+// NetworkStakeAPY is the expected APY of a ticket bought at the current price
+type NetworkStakeAPY struct {
+	TicketPrice   float64 `json:"ticketPrice"`
+	PoolSize      int64   `json:"poolSize"`
+	VoteReward    float64 `json:"voteReward"` // Stake subsidy per vote
+	AvgDaysToVote float64 `json:"avgDaysToVote"`
+	ExpectedAPY   float64 `json:"expectedApy"` // Percent
+}
+
+// StakeReturnsResponse is the wallet's staking reward history
+type StakeReturnsResponse struct {
+	Summary   StakeReturnPeriod   `json:"summary"`
+	ByMonth   []StakeReturnPeriod `json:"byMonth"`
+	ByAccount []StakeReturnPeriod `json:"byAccount"`
+	Tickets   []TicketReturn      `json:"tickets"`
+	Network   *NetworkStakeAPY    `json:"network,omitempty"` // Absent without a dcrd connection
+}
+
+// HistoryDebit is a wallet-owned input spent by a history transaction
+type HistoryDebit struct {
+	Index   uint32  `json:"index"`
+	Account string  `json:"account"`
+	Amount  float64 `json:"amount"`
+}
+
+// HistoryCredit is a wallet-owned output of a history transaction
+type HistoryCredit struct {
+	Index    uint32  `json:"index"`
+	Account  string  `json:"account"`
+	Address  string  `json:"address,omitempty"`
+	Amount   float64 `json:"amount"`
+	Internal bool    `json:"internal"` // true for change outputs
+}
+
+// HistoryEntry is one wallet transaction with its wallet-owned inputs and outputs
+type HistoryEntry struct {
+	TxID          string          `json:"txid"`
+	Cursor        string          `json:"cursor,omitempty"` // "height:index", empty for unmined
+	BlockHeight   int32           `json:"blockHeight"`
+	BlockIndex    int             `json:"blockIndex"`
+	BlockHash     string          `json:"blockHash,omitempty"`
+	BlockTime     int64           `json:"blockTime,omitempty"`
+	Time          time.Time       `json:"time"`
+	Confirmations int64           `json:"confirmations"`
+	TxType        string          `json:"txType"`   // "regular", "ticket", "vote", "revocation"
+	Category      string          `json:"category"` // "send", "receive", "transfer", "generate"
+	Amount        float64         `json:"amount"`   // Net change of the wallet balance
+	Fee           float64         `json:"fee,omitempty"`
+	IsMixed       bool            `json:"isMixed,omitempty"`
+	Fiat          *FiatValue      `json:"fiat,omitempty"` // Fiat equivalent of Amount
+	Accounts      []string        `json:"accounts"`
+	Debits        []HistoryDebit  `json:"debits"`
+	Credits       []HistoryCredit `json:"credits"`
+}
+
+// HistoryResponse is one page of wallet history, newest first
+type HistoryResponse struct {
+	Transactions []HistoryEntry `json:"transactions"`
+	Unmined      []HistoryEntry `json:"unmined,omitempty"` // First page only
+	NextCursor   string         `json:"nextCursor,omitempty"`
+	HasMore      bool           `json:"hasMore"`
+}
+
+// TaxRecord is one classified wallet transaction for tax and accounting export.
+// Amount plus Fee equals the net change of the wallet balance.
+type TaxRecord struct {
+	Time        time.Time `json:"time"`
+	TxID        string    `json:"txid"`
+	BlockHeight int32     `json:"blockHeight"`
+	Kind        string    `json:"kind"`
+	Accounts    []string  `json:"accounts"`
+	Amount      float64   `json:"amount"` // Negative when DCR left the wallet
+	Fee         float64   `json:"fee"`    // Negative, paid by the wallet
+	Locked      float64   `json:"locked,omitempty"`
+	Price       float64   `json:"price,omitempty"` // Fiat per DCR on the day of the transaction
+	HasPrice    bool      `json:"hasPrice"`
+	FiatAmount  float64   `json:"fiatAmount,omitempty"`
+	FiatFee     float64   `json:"fiatFee,omitempty"`
+	Description string    `json:"description"`
+}
+
+// MixerStartRequest starts the account mixer. Empty fields fall back to the
+// configured defaults. Passphrase may be omitted if the wallet is unlocked.
+type MixerStartRequest struct {
+	Passphrase         string  `json:"passphrase,omitempty"`
+	MixedAccount       string  `json:"mixedAccount,omitempty"`
+	MixedAccountBranch *uint32 `json:"mixedAccountBranch,omitempty"`
+	ChangeAccount      string  `json:"changeAccount,omitempty"`
+	CSPPServer         string  `json:"csppServer,omitempty"`
+}
+
+// MixerStatus is the state of the account mixer run by this backend
+type MixerStatus struct {
+	Running            bool       `json:"running"`
+	MixedAccount       string     `json:"mixedAccount"`
+	MixedAccountBranch uint32     `json:"mixedAccountBranch"`
+	ChangeAccount      string     `json:"changeAccount"`
+	CSPPServer         string     `json:"csppServer"`
+	StartedAt          *time.Time `json:"startedAt,omitempty"`
+	StoppedAt          *time.Time `json:"stoppedAt,omitempty"`
+	LastError          string     `json:"lastError,omitempty"`
+}
+
+// AccountPrivacy splits the unspent outputs of an account into mixed and
+// unmixed funds
+type AccountPrivacy struct {
+	AccountName     string  `json:"accountName"`
+	MixedBalance    float64 `json:"mixedBalance"`
+	UnmixedBalance  float64 `json:"unmixedBalance"`
+	MixedOutputs    int     `json:"mixedOutputs"`
+	UnmixedOutputs  int     `json:"unmixedOutputs"`
+	MixedPercent    float64 `json:"mixedPercent"`
+	IsMixedAccount  bool    `json:"isMixedAccount,omitempty"`
+	IsChangeAccount bool    `json:"isChangeAccount,omitempty"`
+}
+
+// MixerResponse reports the mixer state and the per-account privacy breakdown
+type MixerResponse struct {
+	Status   MixerStatus      `json:"status"`
+	Accounts []AccountPrivacy `json:"accounts"`
+}
+
+// AccountNameRequest names a new account or renames an existing one
+type AccountNameRequest struct {
+	Name string `json:"name"`
+}
+
+// AccountDetails identifies an account and its next unused address indexes
+type AccountDetails struct {
+	AccountName   string  `json:"accountName"`
+	AccountNumber *uint32 `json:"accountNumber,omitempty"`
+	ExternalIndex uint32  `json:"externalIndex"` // Next receive address index
+	InternalIndex uint32  `json:"internalIndex"` // Next change address index
+}
+
+// AccountXpub is the exported extended public key of an account
+type AccountXpub struct {
+	AccountName string `json:"accountName"`
+	Xpub        string `json:"xpub"`
+	QRPayload   string `json:"qrPayload"` // Exact string to encode in a QR code
+	Network     string `json:"network,omitempty"`
+}
+
+// UTXO is an unspent wallet output
+type UTXO struct {
+	TxID          string  `json:"txid"`
+	Vout          uint32  `json:"vout"`
+	Tree          int8    `json:"tree"`
+	TxType        string  `json:"txType"` // "regular", "ticket", "vote", "revocation", ...
+	Account       string  `json:"account"`
+	Address       string  `json:"address"`
+	Amount        float64 `json:"amount"`
+	Confirmations int64   `json:"confirmations"`
+	Spendable     bool    `json:"spendable"`
+	Locked        bool    `json:"locked"` // Excluded from automatic coin selection
+	IsMixed       bool    `json:"isMixed"`
+	IsDust        bool    `json:"isDust"` // Costs more to spend than it is worth at the relay fee
+}
+
+// UTXOListResponse is a filtered, paginated page of unspent outputs, largest
+// first, with totals over all matching outputs
+type UTXOListResponse struct {
+	UTXOs         []UTXO  `json:"utxos"`
+	TotalAmount   float64 `json:"totalAmount"`
+	MixedAmount   float64 `json:"mixedAmount"`
+	UnmixedAmount float64 `json:"unmixedAmount"`
+	LockedCount   int     `json:"lockedCount"`
+	DustCount     int     `json:"dustCount"`
+	Total         int     `json:"total"`
+	CurrentPage   int     `json:"currentPage"`
+	PageSize      int     `json:"pageSize"`
+	TotalPages    int     `json:"totalPages"`
+}
+
+// UTXOLockRequest locks or unlocks outputs for automatic coin selection
+type UTXOLockRequest struct {
+	Outputs []Outpoint `json:"outputs"`
+}
+
+// ConsolidationRequest describes a consolidation of an account's unspent
+// outputs into fewer, larger ones
+type ConsolidationRequest struct {
+	Account            string  `json:"account"`            // Source account, "default" if empty
+	DestinationAccount string  `json:"destinationAccount"` // Source account if empty
+	MaxAmount          float64 `json:"maxAmount"`          // Only outputs up to this amount, 0 for all
+	MaxTxSize          int     `json:"maxTxSize"`          // Bytes per transaction, 100000 if 0
+	FeeRate            float64 `json:"feeRate"`            // DCR/kB, 0 for the wallet relay fee
+	FeeBudget          float64 `json:"feeBudget"`          // Total fee limit in DCR, 0 for no limit
+	FutureFeeRate      float64 `json:"futureFeeRate"`      // DCR/kB assumed for later spends, feeRate if 0
+	MinConf            int64   `json:"minConf"`            // Minimum confirmations of spent outputs, 1 if 0
+	IncludeMixed       bool    `json:"includeMixed"`       // Also consolidate mixed outputs, never with unmixed ones
+	Passphrase         string  `json:"passphrase,omitempty"`
+}
+
+// ConsolidationBatch is one planned self-send
+type ConsolidationBatch struct {
+	Inputs        []Outpoint `json:"inputs"`
+	InputCount    int        `json:"inputCount"`
+	Mixed         bool       `json:"mixed"` // All inputs are mixed outputs
+	TotalInput    float64    `json:"totalInput"`
+	Output        float64    `json:"output"`
+	Fee           float64    `json:"fee"`
+	EstimatedSize int        `json:"estimatedSize"`
+}
+
+// ConsolidationPlan is the proposed set of consolidation transactions
+type ConsolidationPlan struct {
+	Account            string               `json:"account"`
+	DestinationAccount string               `json:"destinationAccount"`
+	FeeRate            float64              `json:"feeRate"`
+	FutureFeeRate      float64              `json:"futureFeeRate"`
+	Sweep              bool                 `json:"sweep"` // One transaction spending the whole account, built with sweepaccount
+	Batches            []ConsolidationBatch `json:"batches"`
+	InputCount         int                  `json:"inputCount"`
+	TotalInput         float64              `json:"totalInput"`
+	TotalFee           float64              `json:"totalFee"`
+	FeesSaved          float64              `json:"feesSaved"` // Later spend fees avoided less the consolidation fees, may be negative
+	SkippedDust        int                  `json:"skippedDust"`
+	SkippedLarge       int                  `json:"skippedLarge"`
+	SkippedMixed       int                  `json:"skippedMixed"`
+	SkippedBudget      int                  `json:"skippedBudget"`
+	Warnings           []string             `json:"warnings,omitempty"`
+}
+
+// ConsolidationResult is the result of a consolidation job
+type ConsolidationResult struct {
+	Plan      ConsolidationPlan `json:"plan"`
+	TxIDs     []string          `json:"txids"`
+	Published int               `json:"published"`
+}
+
+// ConsolidationResponse is returned when a consolidation job starts
+type ConsolidationResponse struct {
+	Success bool              `json:"success"`
+	Message string            `json:"message"`
+	JobID   string            `json:"jobId"`
+	Plan    ConsolidationPlan `json:"plan"`
+}
+
+// SignMessageRequest asks the wallet to sign a message with an address key
+type SignMessageRequest struct {
+	Address    string `json:"address"`
+	Message    string `json:"message"`
+	Passphrase string `json:"passphrase,omitempty"`
+}
+
+// SignMessageResponse is a message signature proving control of an address
+type SignMessageResponse struct {
+	Address   string `json:"address"`
+	Message   string `json:"message"`
+	Signature string `json:"signature"` // Base64 compact signature, as verifymessage expects
+	Network   string `json:"network"`
+}
+
+// Offline transaction statuses
+const (
+	OfflineTxStatusPrepared  = "prepared"  // Exported, waiting for the signed transaction
+	OfflineTxStatusBroadcast = "broadcast" // Signed transaction verified and broadcast
+)
+
+// OfflineInput is a spent output with the data an offline signer needs
+type OfflineInput struct {
+	TxID          string  `json:"txid"`
+	Vout          uint32  `json:"vout"`
+	Tree          int8    `json:"tree"`
+	Amount        float64 `json:"amount"`
+	Address       string  `json:"address"`
+	PkScript      string  `json:"pkScript"` // Hex previous output script
+	ScriptVersion uint16  `json:"scriptVersion"`
+	Path          string  `json:"path,omitempty"` // Derivation path of the signing key
+}
+
+// OfflineTransaction is an unsigned transaction exported for signing on an
+// air-gapped machine, and the intent its signed version must match
+type OfflineTransaction struct {
+	ID            string              `json:"id"`
+	Status        string              `json:"status"`
+	Network       string              `json:"network"`
+	Account       string              `json:"account"`
+	UnsignedHex   string              `json:"unsignedHex"`
+	Inputs        []OfflineInput      `json:"inputs"`
+	Outputs       []SendPreviewOutput `json:"outputs"`
+	TotalInput    float64             `json:"totalInput"`
+	TotalSent     float64             `json:"totalSent"` // Excluding change
+	Fee           float64             `json:"fee"`
+	FeeRate       float64             `json:"feeRate"`       // DCR/kB at the estimated size
+	EstimatedSize uint32              `json:"estimatedSize"` // Signed size in bytes
+	CreatedAt     time.Time           `json:"createdAt"`
+	ExpiresAt     time.Time           `json:"expiresAt"`
+	TxID          string              `json:"txid,omitempty"`
+	BroadcastAt   *time.Time          `json:"broadcastAt,omitempty"`
+}
+
+// OfflineSubmitRequest carries a transaction signed offline
+type OfflineSubmitRequest struct {
+	SignedHex string `json:"signedHex"`
+}
+
+// OfflineTransactionListResponse lists offline transactions, newest first
+type OfflineTransactionListResponse struct {
+	Transactions []OfflineTransaction `json:"transactions"`
+}
+
+// WalletCreateRequest creates a new wallet from a fresh random seed
+type WalletCreateRequest struct {
+	PrivatePassphrase string `json:"privatePassphrase"`
+	PublicPassphrase  string `json:"publicPassphrase,omitempty"` // dcrwallet's insecure default if empty
+}
+
+// WalletRestoreRequest restores a wallet from its seed
+type WalletRestoreRequest struct {
+	Seed              string `json:"seed"` // 33 seed words or a hex seed
+	PrivatePassphrase string `json:"privatePassphrase"`
+	PublicPassphrase  string `json:"publicPassphrase,omitempty"`
+}
+
+// WalletWatchOnlyRequest creates a watch-only wallet from an account xpub
+type WalletWatchOnlyRequest struct {
+	Xpub             string `json:"xpub"`
+	PublicPassphrase string `json:"publicPassphrase,omitempty"`
+}
+
+// WalletOpenRequest opens an existing wallet
+type WalletOpenRequest struct {
+	PublicPassphrase string `json:"publicPassphrase,omitempty"`
+}
+
+// WalletSyncRequest starts syncing the loaded wallet
+type WalletSyncRequest struct {
+	Mode              string `json:"mode,omitempty"` // "rpc" or "spv", the configured mode if empty
+	DiscoverAccounts  bool   `json:"discoverAccounts"`
+	PrivatePassphrase string `json:"privatePassphrase,omitempty"` // Required to discover accounts
+}
+
+// WalletSeedResponse is returned once when a wallet is created. The seed is
+// not stored anywhere by the backend.
+type WalletSeedResponse struct {
+	SeedMnemonic string           `json:"seedMnemonic"`
+	SeedHex      string           `json:"seedHex"`
+	Message      string           `json:"message"`
+	Sync         WalletSyncStatus `json:"sync"`
+}
+
+// WalletSyncStatus is the state of a sync started through the wallet loader
+type WalletSyncStatus struct {
+	Mode             string     `json:"mode,omitempty"` // "rpc" or "spv"
+	Running          bool       `json:"running"`
+	Synced           bool       `json:"synced"`
+	Stage            string     `json:"stage,omitempty"` // Latest sync notification, e.g. "fetched_headers_progress"
+	PeerCount        int32      `json:"peerCount,omitempty"`
+	RescannedThrough int32      `json:"rescannedThrough,omitempty"`
+	LastError        string     `json:"lastError,omitempty"`
+	StartedAt        *time.Time `json:"startedAt,omitempty"`
+}
+
+// WalletLoaderStatus reports whether a wallet exists and is loaded
+type WalletLoaderStatus struct {
+	Exists bool             `json:"exists"`
+	Loaded bool             `json:"loaded"`
+	Sync   WalletSyncStatus `json:"sync"`
+}
+
+// WalletLoaderResponse is returned by wallet loader operations
+type WalletLoaderResponse struct {
+	Success bool             `json:"success"`
+	Message string           `json:"message"`
+	Sync    WalletSyncStatus `json:"sync"`
+}
+
+// WalletNetwork describes how dcrwallet reaches the network and how far it is
+// synced, derived from the wallet alone so it also works without dcrd
+type WalletNetwork struct {
+	Mode                 string  `json:"mode"`      // "spv" or "rpc"
+	Connected            bool    `json:"connected"` // dcrd reachable (rpc) or at least one peer (spv)
+	Synced               bool    `json:"synced"`
+	InitialBlockDownload bool    `json:"initialBlockDownload"`
+	HeadersFetchProgress float64 `json:"headersFetchProgress"` // 0-100%
+	WalletHeight         int64   `json:"walletHeight"`
+	ChainHeight          int64   `json:"chainHeight"`
+	ChainHeightSource    string  `json:"chainHeightSource"` // "dcrd", "peers" or "wallet"
+	PeerCount            int     `json:"peerCount"`
+	SyncStage            string  `json:"syncStage,omitempty"` // Latest SpvSync/RpcSync notification, when synced through the loader
+	DcrdAvailable        bool    `json:"dcrdAvailable"`       // Pulse's own dcrd connection, needed by node and explorer panels
+}
+
+// WalletPeer is a peer of an SPV wallet
+type WalletPeer struct {
+	ID             int32  `json:"id"`
+	Address        string `json:"address"`
+	LocalAddress   string `json:"localAddress"`
+	Services       string `json:"services"`
+	Version        uint32 `json:"version"`
+	UserAgent      string `json:"userAgent"`
+	StartingHeight int64  `json:"startingHeight"` // Peer's tip when the connection was made
+	BanScore       int32  `json:"banScore"`
+}
+
+// WalletPeersResponse lists the wallet's SPV peers
+type WalletPeersResponse struct {
+	Mode  string       `json:"mode"`
+	Peers []WalletPeer `json:"peers"`
+	Count int          `json:"count"`
+}